@@ -12,3 +12,13 @@ func SetupLLM(apiKey string, modelID core.ModelID) {
 		log.Fatalf("Failed to configure default LLM: %v", err)
 	}
 }
+
+// SetupLLMFromEnv configures the default LLM from GEMINI_API_KEY and
+// DSPY_MODEL (see core.ConfigureDefaultLLMFromEnv), so an example can run
+// as-is with nothing but those environment variables set instead of
+// hardcoding an API key and model ID at the call site.
+func SetupLLMFromEnv() {
+	if err := core.ConfigureDefaultLLMFromEnv(); err != nil {
+		log.Fatalf("Failed to configure default LLM from environment: %v", err)
+	}
+}
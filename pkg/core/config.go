@@ -3,7 +3,24 @@ package core
 import (
 	"context"
 	"fmt"
+	"os"
+	"sync"
 	"time"
+
+	"github.com/darwishdev/dspy-go/pkg/errors"
+)
+
+// Environment variable names read by ConfigureDefaultLLMFromEnv.
+const (
+	// EnvGeminiAPIKey names the environment variable holding the Gemini API
+	// key. There's no default for this one - without it, there's no way to
+	// authenticate - so its absence is reported as an error rather than
+	// silently falling back to an empty key.
+	EnvGeminiAPIKey = "GEMINI_API_KEY"
+
+	// EnvDSPyModel names the environment variable selecting which model ID
+	// to configure. Defaults to ModelGoogleGeminiFlash when unset.
+	EnvDSPyModel = "DSPY_MODEL"
 )
 
 type Config struct {
@@ -11,6 +28,12 @@ type Config struct {
 	TeacherLLM       LLM
 	ConcurrencyLevel int
 
+	// FieldNamingStrategy controls how typed signature field names are
+	// derived from Go struct field names when no explicit `dspy:"name"`
+	// tag is present. Defaults to FieldNamingLowercase for backward
+	// compatibility.
+	FieldNamingStrategy FieldNamingStrategy
+
 	// Registry configuration
 	Registry *RegistryConfig `json:"registry,omitempty" yaml:"registry,omitempty"`
 }
@@ -20,16 +43,59 @@ var GlobalConfig = &Config{
 	ConcurrencyLevel: 1,
 }
 
+// defaultLLMMu guards GlobalConfig.DefaultLLM. It's separate from the rest
+// of GlobalConfig because it's the one field modules read from and tests
+// write to concurrently (e.g. a test swapping in a fake LLM via
+// SetDefaultLLM while another goroutine calls GetDefaultLLM).
+var defaultLLMMu sync.RWMutex
+
 // ConfigureDefaultLLM sets up the default LLM to be used across the package.
 func ConfigureDefaultLLM(apiKey string, modelID ModelID) error {
 	llmInstance, err := DefaultFactory.CreateLLM(apiKey, modelID)
 	if err != nil {
 		return fmt.Errorf("failed to configure default LLM: %w", err)
 	}
+	defaultLLMMu.Lock()
 	GlobalConfig.DefaultLLM = llmInstance
+	defaultLLMMu.Unlock()
 	return nil
 }
 
+// ConfigureDefaultLLMFromEnv sets up the default LLM from environment
+// variables, so examples and quickstarts can run without editing code:
+// EnvGeminiAPIKey ("GEMINI_API_KEY") supplies the API key and is required,
+// and EnvDSPyModel ("DSPY_MODEL") optionally selects the model ID,
+// defaulting to ModelGoogleGeminiFlash when unset. For anything beyond
+// this convention - a different provider, a key sourced some other way -
+// use ConfigureDefaultLLM directly.
+func ConfigureDefaultLLMFromEnv() error {
+	apiKey := os.Getenv(EnvGeminiAPIKey)
+	if apiKey == "" {
+		return fmt.Errorf("%s environment variable is not set", EnvGeminiAPIKey)
+	}
+
+	modelID := ModelGoogleGeminiFlash
+	if v := os.Getenv(EnvDSPyModel); v != "" {
+		modelID = ModelID(v)
+	}
+
+	return ConfigureDefaultLLM(apiKey, modelID)
+}
+
+// RequireDefaultLLM returns the configured default LLM, or an error if
+// none has been configured yet (via ConfigureDefaultLLM,
+// ConfigureDefaultLLMFromEnv, ConfigureDefaultLLMFromRegistry, or
+// SetDefaultLLM). Prefer this over GetDefaultLLM when a nil default would
+// otherwise surface later as a confusing nil-pointer panic deep inside a
+// module.
+func RequireDefaultLLM() (LLM, error) {
+	llm := GetDefaultLLM()
+	if llm == nil {
+		return nil, errors.New(errors.ConfigurationError, "no default LLM configured: call ConfigureDefaultLLM, ConfigureDefaultLLMFromEnv, or SetDefaultLLM first")
+	}
+	return llm, nil
+}
+
 // ConfigureTeacherLLM sets up the teacher LLM.
 func ConfigureTeacherLLM(apiKey string, modelID ModelID) error {
 	llmInstance, err := DefaultFactory.CreateLLM(apiKey, modelID)
@@ -40,8 +106,12 @@ func ConfigureTeacherLLM(apiKey string, modelID ModelID) error {
 	return nil
 }
 
-// GetDefaultLLM returns the default LLM.
+// GetDefaultLLM returns the default LLM, or nil if none has been
+// configured. See RequireDefaultLLM for a variant that returns an error
+// instead of nil.
 func GetDefaultLLM() LLM {
+	defaultLLMMu.RLock()
+	defer defaultLLMMu.RUnlock()
 	return GlobalConfig.DefaultLLM
 }
 
@@ -78,7 +148,9 @@ func ConfigureDefaultLLMFromRegistry(ctx context.Context, apiKey string, modelID
 	if err != nil {
 		return fmt.Errorf("failed to configure default LLM from registry: %w", err)
 	}
+	defaultLLMMu.Lock()
 	GlobalConfig.DefaultLLM = llmInstance
+	defaultLLMMu.Unlock()
 	return nil
 }
 
@@ -42,6 +42,58 @@ func TestConfigureDefaultLLM(t *testing.T) {
 	})
 }
 
+func TestConfigureDefaultLLMFromEnv(t *testing.T) {
+	// Save original state
+	originalDefaultLLM := GlobalConfig.DefaultLLM
+	originalDefaultFactory := DefaultFactory
+	defer func() {
+		GlobalConfig.DefaultLLM = originalDefaultLLM
+		DefaultFactory = originalDefaultFactory
+	}()
+
+	mockFactory := &MockLLMFactory{}
+	DefaultFactory = mockFactory
+
+	t.Run("MissingAPIKey_ReturnsError", func(t *testing.T) {
+		t.Setenv(EnvGeminiAPIKey, "")
+		t.Setenv(EnvDSPyModel, "")
+
+		err := ConfigureDefaultLLMFromEnv()
+		if err == nil {
+			t.Error("Expected error when GEMINI_API_KEY is unset")
+		}
+	})
+
+	t.Run("APIKeySet_ConfiguresDefaultModel", func(t *testing.T) {
+		GlobalConfig.DefaultLLM = nil
+		t.Setenv(EnvGeminiAPIKey, "test-key")
+		t.Setenv(EnvDSPyModel, "")
+
+		if err := ConfigureDefaultLLMFromEnv(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if GlobalConfig.DefaultLLM == nil {
+			t.Error("Expected DefaultLLM to be set")
+		}
+		if mockFactory.LastModelID != ModelGoogleGeminiFlash {
+			t.Errorf("Expected default model %q, got %q", ModelGoogleGeminiFlash, mockFactory.LastModelID)
+		}
+	})
+
+	t.Run("ModelEnvVarSet_OverridesDefault", func(t *testing.T) {
+		GlobalConfig.DefaultLLM = nil
+		t.Setenv(EnvGeminiAPIKey, "test-key")
+		t.Setenv(EnvDSPyModel, "gemini-2.5-pro")
+
+		if err := ConfigureDefaultLLMFromEnv(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if mockFactory.LastModelID != ModelGoogleGeminiPro {
+			t.Errorf("Expected model %q, got %q", ModelGoogleGeminiPro, mockFactory.LastModelID)
+		}
+	})
+}
+
 func TestConfigureTeacherLLM(t *testing.T) {
 	// Save original state
 	originalTeacherLLM := GlobalConfig.TeacherLLM
@@ -520,6 +572,36 @@ func TestCreateLLMWithTimeout(t *testing.T) {
 	})
 }
 
+func TestRequireDefaultLLM(t *testing.T) {
+	// Save original state
+	originalDefaultLLM := GlobalConfig.DefaultLLM
+	defer func() {
+		GlobalConfig.DefaultLLM = originalDefaultLLM
+	}()
+
+	t.Run("Unconfigured_ReturnsError", func(t *testing.T) {
+		SetDefaultLLM(nil)
+
+		_, err := RequireDefaultLLM()
+		if err == nil {
+			t.Error("Expected error when no default LLM is configured")
+		}
+	})
+
+	t.Run("FakeLLMInjectedViaSetDefaultLLM_IsReturned", func(t *testing.T) {
+		fake := &MockLLM{}
+		SetDefaultLLM(fake)
+
+		llm, err := RequireDefaultLLM()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if llm != fake {
+			t.Error("Expected RequireDefaultLLM to return the fake injected via SetDefaultLLM")
+		}
+	})
+}
+
 // TestSetDefaultLLM verifies that SetDefaultLLM correctly sets GlobalConfig.DefaultLLM
 // This test ensures the fix for issue #145 works correctly.
 func TestSetDefaultLLM(t *testing.T) {
@@ -564,9 +646,14 @@ func TestSetDefaultLLM(t *testing.T) {
 // MockLLMFactory is a mock factory for testing.
 type MockLLMFactory struct {
 	ShouldError bool
+	// LastModelID records the modelID passed to the most recent CreateLLM
+	// call, so a test can assert which model a caller resolved to without
+	// a real LLM to introspect.
+	LastModelID ModelID
 }
 
 func (f *MockLLMFactory) CreateLLM(apiKey string, modelID ModelID) (LLM, error) {
+	f.LastModelID = modelID
 	if f.ShouldError {
 		return nil, fmt.Errorf("mock factory error")
 	}
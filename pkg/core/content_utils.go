@@ -92,9 +92,9 @@ func NewImageFromURL(url string) (*ContentBlock, error) {
 
 	block := NewImageBlock(data, mimeType)
 	block.Metadata = map[string]interface{}{
-		"source":      "url",
-		"url":         url,
-		"size":        len(data),
+		"source":       "url",
+		"url":          url,
+		"size":         len(data),
 		"content_type": resp.Header.Get("Content-Type"),
 	}
 
@@ -103,17 +103,16 @@ func NewImageFromURL(url string) (*ContentBlock, error) {
 
 // NewImageFromBase64 creates an image ContentBlock from base64 encoded data.
 // It decodes the base64 data and creates a properly formatted ContentBlock.
+// mimeType may be left empty to have it sniffed from the decoded bytes via
+// DetectImageMimeType - passing it explicitly is still preferred when the
+// caller already knows it, since sniffing needs enough leading bytes to
+// recognize a signature and can't distinguish formats that don't have one.
 func NewImageFromBase64(data string, mimeType string) (*ContentBlock, error) {
 	if data == "" {
 		return nil, errors.New(errors.InvalidInput, "base64 data cannot be empty")
 	}
 
-	if mimeType == "" {
-		return nil, errors.New(errors.InvalidInput, "MIME type cannot be empty")
-	}
-
-	// Validate MIME type
-	if !isValidImageMimeType(mimeType) {
+	if mimeType != "" && !isValidImageMimeType(mimeType) {
 		return nil, errors.New(errors.InvalidInput, fmt.Sprintf("unsupported image MIME type: %s", mimeType))
 	}
 
@@ -131,6 +130,13 @@ func NewImageFromBase64(data string, mimeType string) (*ContentBlock, error) {
 		return nil, errors.Wrap(err, errors.InvalidInput, "failed to decode base64 image data")
 	}
 
+	if mimeType == "" {
+		mimeType, err = DetectImageMimeType(decoded)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	block := NewImageBlock(decoded, mimeType)
 	block.Metadata = map[string]interface{}{
 		"source": "base64",
@@ -215,9 +221,9 @@ func NewAudioFromURL(url string) (*ContentBlock, error) {
 
 	block := NewAudioBlock(data, mimeType)
 	block.Metadata = map[string]interface{}{
-		"source":      "url",
-		"url":         url,
-		"size":        len(data),
+		"source":       "url",
+		"url":          url,
+		"size":         len(data),
 		"content_type": resp.Header.Get("Content-Type"),
 	}
 
@@ -226,6 +232,40 @@ func NewAudioFromURL(url string) (*ContentBlock, error) {
 
 // Helper functions for MIME type detection and validation
 
+// DetectImageMimeType sniffs data's MIME type from its first bytes using
+// http.DetectContentType, rather than the filename/extension fallbacks
+// detectImageMimeType also has available - useful for a caller with only
+// raw bytes and no filename, e.g. NewImageFromBase64 when mimeType is
+// omitted. It returns an error if the sniffed type isn't one of the image
+// formats this package knows how to send to a model (isValidImageMimeType).
+func DetectImageMimeType(data []byte) (string, error) {
+	detected := sniffMimeType(data)
+	if !isValidImageMimeType(detected) {
+		return "", errors.New(errors.InvalidInput, fmt.Sprintf("unsupported image MIME type: %s", detected))
+	}
+	return detected, nil
+}
+
+// DetectAudioMimeType is DetectImageMimeType's audio counterpart.
+func DetectAudioMimeType(data []byte) (string, error) {
+	detected := sniffMimeType(data)
+	if !isValidAudioMimeType(detected) {
+		return "", errors.New(errors.InvalidInput, fmt.Sprintf("unsupported audio MIME type: %s", detected))
+	}
+	return detected, nil
+}
+
+// sniffMimeType wraps http.DetectContentType, stripping any parameters it
+// appends (e.g. "; charset=utf-8") so callers compare against a plain type
+// like "image/png" rather than having to parse it themselves.
+func sniffMimeType(data []byte) string {
+	detected := http.DetectContentType(data)
+	if mimeType, _, err := mime.ParseMediaType(detected); err == nil {
+		return mimeType
+	}
+	return detected
+}
+
 // detectImageMimeType detects the MIME type of image data.
 func detectImageMimeType(data []byte, filename string) string {
 	// First try to detect from file content
@@ -352,6 +392,7 @@ func isValidAudioMimeType(mimeType string) bool {
 		"audio/wav",
 		"audio/wave", // Alternative WAV MIME type
 		"audio/ogg",
+		"application/ogg", // http.DetectContentType's sniffed type for an OGG container
 		"audio/flac",
 		"audio/aac",
 		"audio/mp4",
@@ -368,17 +409,15 @@ func isValidAudioMimeType(mimeType string) bool {
 
 // NewAudioFromBase64 creates an audio ContentBlock from base64 encoded data.
 // It decodes the base64 data and creates a properly formatted ContentBlock.
+// mimeType may be left empty to have it sniffed from the decoded bytes via
+// DetectAudioMimeType - see NewImageFromBase64's doc comment for why an
+// explicit mimeType is still preferred when the caller has one.
 func NewAudioFromBase64(data string, mimeType string) (*ContentBlock, error) {
 	if data == "" {
 		return nil, errors.New(errors.InvalidInput, "base64 data cannot be empty")
 	}
 
-	if mimeType == "" {
-		return nil, errors.New(errors.InvalidInput, "MIME type cannot be empty")
-	}
-
-	// Validate MIME type
-	if !isValidAudioMimeType(mimeType) {
+	if mimeType != "" && !isValidAudioMimeType(mimeType) {
 		return nil, errors.New(errors.InvalidInput, fmt.Sprintf("unsupported audio MIME type: %s", mimeType))
 	}
 
@@ -396,6 +435,13 @@ func NewAudioFromBase64(data string, mimeType string) (*ContentBlock, error) {
 		return nil, errors.Wrap(err, errors.InvalidInput, "failed to decode base64 audio data")
 	}
 
+	if mimeType == "" {
+		mimeType, err = DetectAudioMimeType(decoded)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	block := NewAudioBlock(decoded, mimeType)
 	block.Metadata = map[string]interface{}{
 		"source": "base64",
@@ -404,3 +450,30 @@ func NewAudioFromBase64(data string, mimeType string) (*ContentBlock, error) {
 
 	return &block, nil
 }
+
+// ValidateContentBlockForLLM checks that llm advertises the capability
+// block's type requires - CapabilityVision for an image block,
+// CapabilityAudio for an audio one - before it's sent to that model. This
+// package has no per-model MIME allowlist beyond isValidImageMimeType/
+// isValidAudioMimeType (already enforced by DetectImageMimeType/
+// DetectAudioMimeType and the NewImageFrom*/NewAudioFrom* constructors), so
+// this checks modality support, not the MIME type itself. A text block
+// always passes, since every LLM handles text.
+func ValidateContentBlockForLLM(llm LLM, block ContentBlock) error {
+	var required Capability
+	switch block.Type {
+	case FieldTypeImage:
+		required = CapabilityVision
+	case FieldTypeAudio:
+		required = CapabilityAudio
+	default:
+		return nil
+	}
+
+	for _, c := range llm.Capabilities() {
+		if c == required {
+			return nil
+		}
+	}
+	return errors.New(errors.InvalidInput, fmt.Sprintf("%s does not support %s content", llm.ModelID(), required))
+}
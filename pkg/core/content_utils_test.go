@@ -189,11 +189,20 @@ func TestNewImageFromBase64(t *testing.T) {
 		assert.Contains(t, err.Error(), "base64 data cannot be empty")
 	})
 
-	t.Run("Empty MIME type", func(t *testing.T) {
+	t.Run("Empty MIME type sniffs from content", func(t *testing.T) {
+		pngData := []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}
+		base64Data := base64.StdEncoding.EncodeToString(pngData)
+
+		block, err := NewImageFromBase64(base64Data, "")
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", block.MimeType)
+	})
+
+	t.Run("Empty MIME type and unsniffable content fails", func(t *testing.T) {
 		block, err := NewImageFromBase64("dGVzdA==", "")
 		assert.Error(t, err)
 		assert.Nil(t, block)
-		assert.Contains(t, err.Error(), "MIME type cannot be empty")
+		assert.Contains(t, err.Error(), "unsupported image MIME type")
 	})
 
 	t.Run("Invalid MIME type", func(t *testing.T) {
@@ -387,11 +396,20 @@ func TestNewAudioFromBase64(t *testing.T) {
 		assert.Contains(t, err.Error(), "base64 data cannot be empty")
 	})
 
-	t.Run("Empty MIME type", func(t *testing.T) {
+	t.Run("Empty MIME type sniffs from content", func(t *testing.T) {
+		wavData := []byte("RIFF\x00\x00\x00\x00WAVEfmt ")
+		base64Data := base64.StdEncoding.EncodeToString(wavData)
+
+		block, err := NewAudioFromBase64(base64Data, "")
+		require.NoError(t, err)
+		assert.Equal(t, "audio/wave", block.MimeType)
+	})
+
+	t.Run("Empty MIME type and unsniffable content fails", func(t *testing.T) {
 		block, err := NewAudioFromBase64("dGVzdA==", "")
 		assert.Error(t, err)
 		assert.Nil(t, block)
-		assert.Contains(t, err.Error(), "MIME type cannot be empty")
+		assert.Contains(t, err.Error(), "unsupported audio MIME type")
 	})
 
 	t.Run("Invalid MIME type", func(t *testing.T) {
@@ -485,6 +503,54 @@ func TestDetectAudioMimeType(t *testing.T) {
 	})
 }
 
+func TestDetectImageMimeTypeSniffsFromBytes(t *testing.T) {
+	t.Run("PNG", func(t *testing.T) {
+		pngData := []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}
+		mimeType, err := DetectImageMimeType(pngData)
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", mimeType)
+	})
+
+	t.Run("JPEG", func(t *testing.T) {
+		jpegData := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10}
+		mimeType, err := DetectImageMimeType(jpegData)
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", mimeType)
+	})
+
+	t.Run("WebP", func(t *testing.T) {
+		webpData := []byte("RIFF\x00\x00\x00\x00WEBPVP8 ")
+		mimeType, err := DetectImageMimeType(webpData)
+		require.NoError(t, err)
+		assert.Equal(t, "image/webp", mimeType)
+	})
+
+	t.Run("Unsupported type", func(t *testing.T) {
+		pdfData := []byte("%PDF-1.4")
+		mimeType, err := DetectImageMimeType(pdfData)
+		assert.Error(t, err)
+		assert.Empty(t, mimeType)
+		assert.Contains(t, err.Error(), "unsupported image MIME type")
+	})
+}
+
+func TestDetectAudioMimeTypeSniffsFromBytes(t *testing.T) {
+	t.Run("WAV", func(t *testing.T) {
+		wavData := []byte("RIFF\x00\x00\x00\x00WAVEfmt ")
+		mimeType, err := DetectAudioMimeType(wavData)
+		require.NoError(t, err)
+		assert.Equal(t, "audio/wave", mimeType)
+	})
+
+	t.Run("Unsupported type", func(t *testing.T) {
+		pdfData := []byte("%PDF-1.4")
+		mimeType, err := DetectAudioMimeType(pdfData)
+		assert.Error(t, err)
+		assert.Empty(t, mimeType)
+		assert.Contains(t, err.Error(), "unsupported audio MIME type")
+	})
+}
+
 func TestIsValidImageMimeType(t *testing.T) {
 	validTypes := []string{
 		"image/jpeg",
@@ -601,3 +667,45 @@ func TestContentUtilsIntegration(t *testing.T) {
 		assert.Equal(t, "base64", block2.Metadata["source"])
 	})
 }
+
+// capabilityLLM wraps MockLLM to report a fixed set of capabilities, since
+// MockLLM.Capabilities always returns an empty slice.
+type capabilityLLM struct {
+	MockLLM
+	capabilities []Capability
+}
+
+func (m *capabilityLLM) Capabilities() []Capability {
+	return m.capabilities
+}
+
+func TestValidateContentBlockForLLM(t *testing.T) {
+	t.Run("Text block always passes", func(t *testing.T) {
+		err := ValidateContentBlockForLLM(&MockLLM{}, ContentBlock{Type: FieldTypeText})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Image block passes when LLM supports vision", func(t *testing.T) {
+		llm := &capabilityLLM{capabilities: []Capability{CapabilityVision}}
+		err := ValidateContentBlockForLLM(llm, ContentBlock{Type: FieldTypeImage})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Image block fails when LLM lacks vision", func(t *testing.T) {
+		err := ValidateContentBlockForLLM(&MockLLM{}, ContentBlock{Type: FieldTypeImage})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "vision")
+	})
+
+	t.Run("Audio block passes when LLM supports audio", func(t *testing.T) {
+		llm := &capabilityLLM{capabilities: []Capability{CapabilityAudio}}
+		err := ValidateContentBlockForLLM(llm, ContentBlock{Type: FieldTypeAudio})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Audio block fails when LLM lacks audio", func(t *testing.T) {
+		err := ValidateContentBlockForLLM(&MockLLM{}, ContentBlock{Type: FieldTypeAudio})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "audio")
+	})
+}
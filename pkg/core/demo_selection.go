@@ -0,0 +1,83 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultTokenEstimator estimates a token count from text length using a
+// ~4-characters-per-token heuristic. It exists for callers that have no
+// provider-backed token counter to plug in instead - e.g. an LLM's
+// eventual CountTokens method.
+func DefaultTokenEstimator(text string) int {
+	if text == "" {
+		return 0
+	}
+	return (len(text) + 3) / 4
+}
+
+// DemoSelectorConfig configures SelectDemos.
+type DemoSelectorConfig struct {
+	// Estimator estimates the token cost of a single demonstration's
+	// rendered text. Defaults to a "key: value" rendering of Inputs and
+	// Outputs run through DefaultTokenEstimator when nil.
+	Estimator func(demo Example) int
+
+	// Scorer assigns a priority to each demonstration; demos are
+	// considered for selection in descending score order. When nil, demos
+	// are considered in their original slice order.
+	Scorer func(demo Example) float64
+}
+
+// DemoSelection is the result of a SelectDemos call.
+type DemoSelection struct {
+	Demos           []Example
+	EstimatedTokens int
+}
+
+// SelectDemos greedily selects as many demos as fit within tokenBudget,
+// considering them in priority order (highest config.Scorer score first,
+// or original order if no Scorer is given) and skipping any demo whose
+// cost would blow the remaining budget so later, cheaper demos still get
+// a chance. This lets a prompt renderer include as many few-shot examples
+// as the context window allows without ever exceeding it.
+func SelectDemos(demos []Example, tokenBudget int, config DemoSelectorConfig) DemoSelection {
+	estimator := config.Estimator
+	if estimator == nil {
+		estimator = defaultDemoEstimator
+	}
+
+	ordered := make([]Example, len(demos))
+	copy(ordered, demos)
+	if config.Scorer != nil {
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return config.Scorer(ordered[i]) > config.Scorer(ordered[j])
+		})
+	}
+
+	selection := DemoSelection{Demos: make([]Example, 0, len(ordered))}
+	for _, demo := range ordered {
+		cost := estimator(demo)
+		if selection.EstimatedTokens+cost > tokenBudget {
+			continue
+		}
+		selection.Demos = append(selection.Demos, demo)
+		selection.EstimatedTokens += cost
+	}
+
+	return selection
+}
+
+// defaultDemoEstimator renders a demo as "key: value" lines over its
+// Inputs and Outputs and estimates the token cost of that text.
+func defaultDemoEstimator(demo Example) int {
+	var sb strings.Builder
+	for k, v := range demo.Inputs {
+		fmt.Fprintf(&sb, "%s: %v\n", k, v)
+	}
+	for k, v := range demo.Outputs {
+		fmt.Fprintf(&sb, "%s: %v\n", k, v)
+	}
+	return DefaultTokenEstimator(sb.String())
+}
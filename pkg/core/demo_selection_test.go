@@ -0,0 +1,78 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultTokenEstimator(t *testing.T) {
+	assert.Equal(t, 0, DefaultTokenEstimator(""))
+	assert.Greater(t, DefaultTokenEstimator("a reasonably long sentence about something"), 0)
+}
+
+func TestSelectDemosFitsWithinBudget(t *testing.T) {
+	demos := []Example{
+		{Inputs: map[string]interface{}{"q": "a"}, Outputs: map[string]interface{}{"a": "1"}},
+		{Inputs: map[string]interface{}{"q": "b"}, Outputs: map[string]interface{}{"a": "2"}},
+		{Inputs: map[string]interface{}{"q": "c"}, Outputs: map[string]interface{}{"a": "3"}},
+	}
+
+	result := SelectDemos(demos, 1000, DemoSelectorConfig{})
+	assert.Len(t, result.Demos, 3)
+	assert.Greater(t, result.EstimatedTokens, 0)
+}
+
+func TestSelectDemosRespectsBudget(t *testing.T) {
+	demos := []Example{
+		{Inputs: map[string]interface{}{"q": "short"}},
+		{Inputs: map[string]interface{}{"q": "short"}},
+		{Inputs: map[string]interface{}{"q": "short"}},
+	}
+
+	// Each demo costs the same; cap the budget to fit exactly one.
+	oneDemoCost := defaultDemoEstimator(demos[0])
+	result := SelectDemos(demos, oneDemoCost, DemoSelectorConfig{})
+
+	assert.Len(t, result.Demos, 1)
+	assert.LessOrEqual(t, result.EstimatedTokens, oneDemoCost)
+}
+
+func TestSelectDemosCustomEstimator(t *testing.T) {
+	demos := []Example{
+		{Inputs: map[string]interface{}{"q": "a"}},
+		{Inputs: map[string]interface{}{"q": "b"}},
+	}
+
+	result := SelectDemos(demos, 1, DemoSelectorConfig{
+		Estimator: func(demo Example) int { return 1 },
+	})
+
+	assert.Len(t, result.Demos, 1)
+	assert.Equal(t, 1, result.EstimatedTokens)
+}
+
+func TestSelectDemosPrioritizesByScore(t *testing.T) {
+	low := Example{Inputs: map[string]interface{}{"q": "low"}}
+	high := Example{Inputs: map[string]interface{}{"q": "high"}}
+	demos := []Example{low, high}
+
+	score := map[string]float64{"low": 0, "high": 1}
+	scorer := func(demo Example) float64 {
+		return score[demo.Inputs["q"].(string)]
+	}
+
+	// Budget for exactly one demo: the higher-scored one should win even
+	// though it appears second in the input slice.
+	cost := defaultDemoEstimator(low) // both demos cost the same amount
+	result := SelectDemos(demos, cost, DemoSelectorConfig{Scorer: scorer})
+
+	assert.Len(t, result.Demos, 1)
+	assert.Equal(t, "high", result.Demos[0].Inputs["q"])
+}
+
+func TestSelectDemosEmptyInput(t *testing.T) {
+	result := SelectDemos(nil, 100, DemoSelectorConfig{})
+	assert.Empty(t, result.Demos)
+	assert.Equal(t, 0, result.EstimatedTokens)
+}
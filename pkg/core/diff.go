@@ -0,0 +1,143 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FieldDiff reports a single field that differed between an eval's expected
+// and actual typed output. Path identifies where in the (possibly nested)
+// struct the mismatch occurred, using dot notation for object fields and
+// bracket notation for array elements (e.g. "address.city" or
+// "items[2].name"), so a failure report can point straight at the field
+// that's wrong instead of dumping both structs wholesale.
+type FieldDiff struct {
+	Path     string
+	Expected any
+	Actual   any
+}
+
+// DiffStructs compares expected and actual - normally a TypedSignature's
+// output struct, populated once from the gold example and once from a
+// module's prediction - and reports every field whose value differs,
+// recursing into nested objects and array elements via the same field
+// metadata NewTypedSignature derives from struct tags. It's meant for
+// building readable eval failure reports, not for general-purpose diffing:
+// a mismatched type between expected and actual is reported as a single
+// top-level FieldDiff rather than an error, since "the model returned the
+// wrong shape entirely" is itself a useful thing to show in a report.
+func DiffStructs(expected, actual any) []FieldDiff {
+	ev, eNil := dereference(reflect.ValueOf(expected))
+	av, aNil := dereference(reflect.ValueOf(actual))
+
+	if eNil && aNil {
+		return nil
+	}
+	if eNil || aNil {
+		return []FieldDiff{{Path: "", Expected: expected, Actual: actual}}
+	}
+	if ev.Type() != av.Type() || ev.Kind() != reflect.Struct {
+		return []FieldDiff{{Path: "", Expected: expected, Actual: actual}}
+	}
+
+	fields := parseStructFields(ev.Type(), false, "", nil)
+	return diffFields("", fields, ev, av)
+}
+
+func diffFields(prefix string, fields []FieldMetadata, ev, av reflect.Value) []FieldDiff {
+	var diffs []FieldDiff
+	for _, field := range fields {
+		path := joinDiffPath(prefix, field.Name)
+
+		ef, eNil := dereference(ev.FieldByName(field.GoFieldName))
+		af, aNil := dereference(av.FieldByName(field.GoFieldName))
+		if eNil && aNil {
+			continue
+		}
+		if eNil || aNil {
+			diffs = append(diffs, FieldDiff{Path: path, Expected: interfaceOf(ef), Actual: interfaceOf(af)})
+			continue
+		}
+
+		switch field.Type {
+		case FieldTypeObject:
+			if ef.Kind() != reflect.Struct || af.Kind() != reflect.Struct {
+				if !reflect.DeepEqual(ef.Interface(), af.Interface()) {
+					diffs = append(diffs, FieldDiff{Path: path, Expected: ef.Interface(), Actual: af.Interface()})
+				}
+				continue
+			}
+			diffs = append(diffs, diffFields(path, flatten(field.Properties, field.PropertyOrder), ef, af)...)
+		case FieldTypeArray:
+			diffs = append(diffs, diffArray(path, field.Item, ef, af)...)
+		default:
+			if !reflect.DeepEqual(ef.Interface(), af.Interface()) {
+				diffs = append(diffs, FieldDiff{Path: path, Expected: ef.Interface(), Actual: af.Interface()})
+			}
+		}
+	}
+	return diffs
+}
+
+func diffArray(path string, item *FieldMetadata, ev, av reflect.Value) []FieldDiff {
+	if ev.Kind() != reflect.Slice && ev.Kind() != reflect.Array {
+		if !reflect.DeepEqual(ev.Interface(), av.Interface()) {
+			return []FieldDiff{{Path: path, Expected: ev.Interface(), Actual: av.Interface()}}
+		}
+		return nil
+	}
+	if ev.Len() != av.Len() {
+		return []FieldDiff{{Path: path, Expected: ev.Interface(), Actual: av.Interface()}}
+	}
+
+	var diffs []FieldDiff
+	for i := 0; i < ev.Len(); i++ {
+		elPath := fmt.Sprintf("%s[%d]", path, i)
+
+		ee, eNil := dereference(ev.Index(i))
+		ae, aNil := dereference(av.Index(i))
+		if eNil && aNil {
+			continue
+		}
+		if eNil || aNil {
+			diffs = append(diffs, FieldDiff{Path: elPath, Expected: interfaceOf(ee), Actual: interfaceOf(ae)})
+			continue
+		}
+
+		if item != nil && item.Type == FieldTypeObject && ee.Kind() == reflect.Struct && ae.Kind() == reflect.Struct {
+			diffs = append(diffs, diffFields(elPath, flatten(item.Properties, item.PropertyOrder), ee, ae)...)
+			continue
+		}
+		if !reflect.DeepEqual(ee.Interface(), ae.Interface()) {
+			diffs = append(diffs, FieldDiff{Path: elPath, Expected: ee.Interface(), Actual: ae.Interface()})
+		}
+	}
+	return diffs
+}
+
+// dereference follows v through any pointer indirection, reporting whether
+// it bottomed out at a nil pointer or an invalid (zero) Value - both of
+// which mean there's nothing to recurse into.
+func dereference(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return v, true
+		}
+		v = v.Elem()
+	}
+	return v, !v.IsValid()
+}
+
+func interfaceOf(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+func joinDiffPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type diffAddress struct {
+	City string `dspy:"city"`
+	Zip  string `dspy:"zip"`
+}
+
+type diffItem struct {
+	Name string `dspy:"name"`
+	Qty  int    `dspy:"qty"`
+}
+
+type diffOutput struct {
+	Answer  string      `dspy:"answer"`
+	Address diffAddress `dspy:"address"`
+	Items   []diffItem  `dspy:"items"`
+	Note    *string     `dspy:"note"`
+}
+
+func TestDiffStructsNoDifferences(t *testing.T) {
+	note := "same"
+	expected := diffOutput{Answer: "42", Address: diffAddress{City: "NY", Zip: "10001"}, Note: &note}
+	actual := diffOutput{Answer: "42", Address: diffAddress{City: "NY", Zip: "10001"}, Note: &note}
+
+	diffs := DiffStructs(expected, actual)
+	assert.Empty(t, diffs)
+}
+
+func TestDiffStructsTopLevelFieldMismatch(t *testing.T) {
+	expected := diffOutput{Answer: "42"}
+	actual := diffOutput{Answer: "43"}
+
+	diffs := DiffStructs(expected, actual)
+	assert.Len(t, diffs, 1)
+	assert.Equal(t, "answer", diffs[0].Path)
+	assert.Equal(t, "42", diffs[0].Expected)
+	assert.Equal(t, "43", diffs[0].Actual)
+}
+
+func TestDiffStructsNestedObjectField(t *testing.T) {
+	expected := diffOutput{Answer: "42", Address: diffAddress{City: "NY", Zip: "10001"}}
+	actual := diffOutput{Answer: "42", Address: diffAddress{City: "NY", Zip: "10002"}}
+
+	diffs := DiffStructs(expected, actual)
+	assert.Len(t, diffs, 1)
+	assert.Equal(t, "address.zip", diffs[0].Path)
+	assert.Equal(t, "10001", diffs[0].Expected)
+	assert.Equal(t, "10002", diffs[0].Actual)
+}
+
+func TestDiffStructsArrayElementField(t *testing.T) {
+	expected := diffOutput{Items: []diffItem{{Name: "x", Qty: 1}, {Name: "y", Qty: 2}}}
+	actual := diffOutput{Items: []diffItem{{Name: "x", Qty: 1}, {Name: "y", Qty: 3}}}
+
+	diffs := DiffStructs(expected, actual)
+	assert.Len(t, diffs, 1)
+	assert.Equal(t, "items[1].qty", diffs[0].Path)
+	assert.Equal(t, 2, diffs[0].Expected)
+	assert.Equal(t, 3, diffs[0].Actual)
+}
+
+func TestDiffStructsArrayLengthMismatch(t *testing.T) {
+	expected := diffOutput{Items: []diffItem{{Name: "x", Qty: 1}}}
+	actual := diffOutput{Items: []diffItem{{Name: "x", Qty: 1}, {Name: "y", Qty: 2}}}
+
+	diffs := DiffStructs(expected, actual)
+	assert.Len(t, diffs, 1)
+	assert.Equal(t, "items", diffs[0].Path)
+}
+
+func TestDiffStructsNilPointerMismatch(t *testing.T) {
+	note := "present"
+	expected := diffOutput{Note: &note}
+	actual := diffOutput{Note: nil}
+
+	diffs := DiffStructs(expected, actual)
+	assert.Len(t, diffs, 1)
+	assert.Equal(t, "note", diffs[0].Path)
+	assert.Equal(t, "present", diffs[0].Expected)
+	assert.Nil(t, diffs[0].Actual)
+}
+
+func TestDiffStructsBothNilPointersMatch(t *testing.T) {
+	expected := diffOutput{Note: nil}
+	actual := diffOutput{Note: nil}
+
+	diffs := DiffStructs(expected, actual)
+	assert.Empty(t, diffs)
+}
+
+func TestDiffStructsTypeMismatchReportsSingleTopLevelDiff(t *testing.T) {
+	expected := diffOutput{Answer: "42"}
+	actual := "not a struct at all"
+
+	diffs := DiffStructs(expected, actual)
+	assert.Len(t, diffs, 1)
+	assert.Equal(t, "", diffs[0].Path)
+	assert.Equal(t, expected, diffs[0].Expected)
+	assert.Equal(t, actual, diffs[0].Actual)
+}
+
+func TestDiffStructsBothNilTopLevelMatch(t *testing.T) {
+	var expected *diffOutput
+	var actual *diffOutput
+
+	diffs := DiffStructs(expected, actual)
+	assert.Empty(t, diffs)
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// EvalResult is a single example's outcome from EvaluateDataset: either a
+// scored prediction, or Err set if the module failed to process it.
+type EvalResult struct {
+	Example    Example
+	Prediction map[string]interface{}
+	Score      float64
+	Err        error
+}
+
+// EvaluationReport aggregates EvaluateDataset's per-example results into an
+// average score and the list of errors encountered, giving callers enough
+// detail to build a confusion matrix or error report from Results.
+type EvaluationReport struct {
+	Results      []EvalResult
+	AverageScore float64
+	Errors       []error
+}
+
+// EvaluateDataset runs module over examples with up to concurrency
+// predictions in flight at once, scoring each successful prediction with
+// metric. A module error on one example is recorded in the returned report
+// rather than aborting the run. Cancelling ctx stops scheduling examples
+// that haven't started yet; examples already in flight are expected to
+// return promptly because they were given the same ctx.
+func EvaluateDataset(ctx context.Context, module Module, examples []Example, metric Metric, concurrency int) EvaluationReport {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make([]EvalResult, len(examples))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, example := range examples {
+		select {
+		case <-ctx.Done():
+			results[i] = EvalResult{Example: example, Err: ctx.Err()}
+			continue
+		default:
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(idx int, ex Example) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			prediction, err := module.Process(ctx, ex.Inputs)
+			if err != nil {
+				results[idx] = EvalResult{Example: ex, Err: err}
+				return
+			}
+
+			results[idx] = EvalResult{
+				Example:    ex,
+				Prediction: prediction,
+				Score:      metric(ex.Outputs, prediction),
+			}
+		}(i, example)
+	}
+
+	wg.Wait()
+
+	return buildEvaluationReport(results)
+}
+
+func buildEvaluationReport(results []EvalResult) EvaluationReport {
+	report := EvaluationReport{Results: results}
+
+	var total float64
+	var scored int
+	for _, result := range results {
+		if result.Err != nil {
+			report.Errors = append(report.Errors, result.Err)
+			continue
+		}
+		total += result.Score
+		scored++
+	}
+	if scored > 0 {
+		report.AverageScore = total / float64(scored)
+	}
+	return report
+}
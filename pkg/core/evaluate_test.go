@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// evaluateTestModule echoes back inputs["answer"] as outputs["answer"],
+// failing outright for any input explicitly marked to fail.
+type evaluateTestModule struct {
+	BaseModule
+	delay time.Duration
+}
+
+func (m *evaluateTestModule) Process(ctx context.Context, inputs map[string]any, opts ...Option) (map[string]any, error) {
+	if m.delay > 0 {
+		select {
+		case <-time.After(m.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if fail, _ := inputs["fail"].(bool); fail {
+		return nil, errors.New("module failed to process input")
+	}
+	return map[string]any{"answer": inputs["answer"]}, nil
+}
+
+func exactMatchMetric(expected, actual map[string]interface{}) float64 {
+	if expected["answer"] == actual["answer"] {
+		return 1.0
+	}
+	return 0.0
+}
+
+func TestEvaluateDatasetScoresAllExamples(t *testing.T) {
+	module := &evaluateTestModule{}
+	examples := []Example{
+		{Inputs: map[string]interface{}{"answer": "Paris"}, Outputs: map[string]interface{}{"answer": "Paris"}},
+		{Inputs: map[string]interface{}{"answer": "Berlin"}, Outputs: map[string]interface{}{"answer": "Rome"}},
+	}
+
+	report := EvaluateDataset(context.Background(), module, examples, exactMatchMetric, 2)
+
+	require.Len(t, report.Results, 2)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, 0.5, report.AverageScore)
+}
+
+func TestEvaluateDatasetCollectsErrorsWithoutAborting(t *testing.T) {
+	module := &evaluateTestModule{}
+	examples := []Example{
+		{Inputs: map[string]interface{}{"answer": "Paris"}, Outputs: map[string]interface{}{"answer": "Paris"}},
+		{Inputs: map[string]interface{}{"fail": true}, Outputs: map[string]interface{}{"answer": "Rome"}},
+		{Inputs: map[string]interface{}{"answer": "Berlin"}, Outputs: map[string]interface{}{"answer": "Berlin"}},
+	}
+
+	report := EvaluateDataset(context.Background(), module, examples, exactMatchMetric, 2)
+
+	require.Len(t, report.Results, 3)
+	require.Len(t, report.Errors, 1)
+	// Average score is computed only over the two successfully scored examples.
+	assert.Equal(t, 1.0, report.AverageScore)
+	assert.Error(t, report.Results[1].Err)
+}
+
+func TestEvaluateDatasetRespectsContextCancellation(t *testing.T) {
+	module := &evaluateTestModule{delay: 50 * time.Millisecond}
+	examples := make([]Example, 5)
+	for i := range examples {
+		examples[i] = Example{Inputs: map[string]interface{}{"answer": "x"}, Outputs: map[string]interface{}{"answer": "x"}}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := EvaluateDataset(ctx, module, examples, exactMatchMetric, 2)
+
+	require.Len(t, report.Results, 5)
+	for _, result := range report.Results {
+		assert.ErrorIs(t, result.Err, context.Canceled)
+	}
+}
+
+func TestEvaluateDatasetEmptyDataset(t *testing.T) {
+	module := &evaluateTestModule{}
+	report := EvaluateDataset(context.Background(), module, nil, exactMatchMetric, 4)
+
+	assert.Empty(t, report.Results)
+	assert.Equal(t, 0.0, report.AverageScore)
+}
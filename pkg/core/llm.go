@@ -26,16 +26,66 @@ type TokenInfo struct {
 }
 
 type LLMResponse struct {
-	Content  string
-	Usage    *TokenInfo
+	Content string
+	Usage   *TokenInfo
+
+	// Thoughts carries the model's reasoning/thinking text when the
+	// provider separates it from the answer (see WithThinkingBudget,
+	// WithIncludeThoughts) and the caller asked for it to be included.
+	// Empty when the provider doesn't support thinking mode or the response
+	// didn't carry any thought parts.
+	Thoughts string
+
+	// Metadata carries provider-populated and caller-supplied context about
+	// how this response was produced, for correlation without threading
+	// extra return values through the call chain - e.g. logging a request
+	// hash alongside its latency. Providers that set it populate those
+	// MetadataKey* keys below that apply to the response; a caller's own keys (see
+	// WithExtraMetadata) are merged in alongside them, so avoid reusing a
+	// reserved key unless intentionally overriding it.
 	Metadata map[string]interface{}
 }
 
+// Reserved LLMResponse.Metadata keys a provider populates on the responses
+// it returns. Not every response carries every key - e.g. a finish reason
+// may be unavailable - so check for presence rather than assuming all are
+// set.
+const (
+	MetadataKeyModel        = "model"
+	MetadataKeyLatency      = "latency"
+	MetadataKeyFinishReason = "finishReason"
+	MetadataKeyRequestHash  = "requestHash"
+
+	// MetadataKeySchemaDropped is set to true when a provider, with
+	// schema-rejection fallback enabled, retried a request without its
+	// native structured-output schema after the API rejected it (e.g. too
+	// deeply nested). See GeminiLLM.WithSchemaFallbackOnRejection.
+	MetadataKeySchemaDropped = "schemaDropped"
+
+	// MetadataKeySchemaValidationError is set when a schema-dropped
+	// response fails the provider's best-effort client-side check against
+	// the dropped schema (see MetadataKeySchemaDropped). Its absence does
+	// not guarantee the response matches the schema - only that the
+	// lightweight check didn't catch a mismatch.
+	MetadataKeySchemaValidationError = "schemaValidationError"
+)
+
 type StreamChunk struct {
-	Content string     // The text content of this chunk
-	Done    bool       // Indicates if this is the final chunk
-	Error   error      // Any error that occurred during streaming
-	Usage   *TokenInfo // Optional token usage information (may be nil)
+	Content  string         // The text content of this chunk
+	Thought  string         // Reasoning/thinking text, set instead of Content when the provider marks this chunk as a thought (see WithIncludeThoughts)
+	ToolCall *ToolCallDelta // Set when this chunk carries a fully-assembled tool call instead of text
+	Done     bool           // Indicates if this is the final chunk
+	Error    error          // Any error that occurred during streaming
+	Usage    *TokenInfo     // Optional token usage information (may be nil)
+}
+
+// ToolCallDelta represents a tool/function call assembled from one or more
+// streaming chunks. Providers may emit a call's arguments across several
+// partial deltas; once the arguments form valid JSON the call is considered
+// complete and surfaced on a StreamChunk.
+type ToolCallDelta struct {
+	Name      string
+	Arguments map[string]any
 }
 
 // StreamResponse encapsulates a streaming response.
@@ -152,6 +202,53 @@ type GenerateOptions struct {
 	ResponseSchema       *utils.TypeSchema
 	ResponseJSONSchema   interface{}
 	ResponseMIMEType     string
+
+	// UsageTracker, when set, receives this call's token usage. Opt-in via
+	// WithUsageTracker so reporting has no effect unless a caller asks for it.
+	UsageTracker *UsageTracker
+
+	// SanitizePrompt, when true, strips non-printable control characters
+	// (other than newline/tab) from the prompt and normalizes it to Unicode
+	// NFC before sending it to the provider. Opt-in via WithPromptSanitization
+	// so intentional content (e.g. already-normalized binary-ish payloads) is
+	// never altered unless a caller asks for it.
+	SanitizePrompt bool
+
+	// Params carries provider-specific knobs that don't have a cross-provider
+	// field of their own (e.g. Gemini's "cached_content"). Set via
+	// WithGenerateParams; each provider documents which keys it reads.
+	Params map[string]interface{}
+
+	// ExtraMetadata, set via WithExtraMetadata, is merged into the
+	// returned LLMResponse's Metadata alongside the provider's own
+	// reserved keys (see MetadataKeyModel etc.), so a caller's
+	// correlation data (e.g. a request ID) travels with the response
+	// without needing its own return value threaded through the call chain.
+	ExtraMetadata map[string]interface{}
+
+	// ThinkingBudget, when non-zero, asks a provider that supports a
+	// "thinking" mode (e.g. Gemini) to spend up to this many tokens on
+	// internal reasoning before producing its answer, trading latency for
+	// quality. Zero (the default) omits the setting, leaving the provider's
+	// own default in effect. Set via WithThinkingBudget. Providers without
+	// thinking-mode support ignore it.
+	ThinkingBudget int
+
+	// IncludeThoughts, when true, asks a provider that supports thinking
+	// mode to surface its reasoning text distinctly from the answer (see
+	// LLMResponse.Thoughts and StreamChunk.Thought) instead of discarding
+	// it. False (the default) omits the setting. Set via
+	// WithIncludeThoughts. Providers without thinking-mode support ignore
+	// it.
+	IncludeThoughts bool
+
+	// ValidationRetry bounds how many additional attempts
+	// GenerateWithJSONRetry makes after an output fails validation against
+	// ResponseSchema, before giving up and returning the last validation
+	// error. Zero (the default) makes no retry attempt at all. Set via
+	// WithValidationRetry. Plain Generate/GenerateWithJSON calls ignore it -
+	// it's only consulted by GenerateWithJSONRetry itself.
+	ValidationRetry int
 }
 
 type EmbeddingOptions struct {
@@ -229,6 +326,31 @@ func WithResponseMIMEType(mime string) GenerateOption {
 	}
 }
 
+// WithJSONMode asks the provider to emit a JSON response, optionally
+// constrained to schema, without module code needing to set a provider-
+// specific field itself (e.g. Gemini's responseMimeType) to get there. It's
+// equivalent to WithResponseSchema, except schema may be nil for plain
+// unconstrained JSON output - each provider maps this the same way it maps
+// ResponseSchema/ResponseMIMEType already: Gemini sets responseMimeType
+// and, when schema is non-nil, responseSchema; OpenAI sets response_format,
+// using its json_schema mode when schema is non-nil and json_object
+// otherwise.
+func WithJSONMode(schema *utils.TypeSchema) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.ResponseMIMEType = "application/json"
+		o.ResponseSchema = schema
+	}
+}
+
+// WithValidationRetry sets how many additional attempts
+// GenerateWithJSONRetry makes after a schema-validation failure. See
+// GenerateOptions.ValidationRetry.
+func WithValidationRetry(n int) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.ValidationRetry = n
+	}
+}
+
 // WithMaxTokens sets the maximum number of tokens to generate.
 func WithMaxTokens(n int) GenerateOption {
 	return func(o *GenerateOptions) {
@@ -271,6 +393,70 @@ func WithStopSequences(sequences ...string) GenerateOption {
 	}
 }
 
+// WithUsageTracker attaches a UsageTracker that this call's token usage
+// will be recorded into, letting a caller accumulate cost across a batch
+// or optimizer run.
+func WithUsageTracker(tracker *UsageTracker) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.UsageTracker = tracker
+	}
+}
+
+// WithPromptSanitization enables stripping control characters and
+// normalizing unicode in the prompt before it's sent to the provider. See
+// GenerateOptions.SanitizePrompt.
+func WithPromptSanitization() GenerateOption {
+	return func(o *GenerateOptions) {
+		o.SanitizePrompt = true
+	}
+}
+
+// WithGenerateParams merges provider-specific parameters into
+// GenerateOptions.Params, for knobs that don't warrant a dedicated
+// cross-provider option (e.g. Gemini's "cached_content").
+func WithGenerateParams(params map[string]interface{}) GenerateOption {
+	return func(o *GenerateOptions) {
+		if o.Params == nil {
+			o.Params = make(map[string]interface{})
+		}
+		for k, v := range params {
+			o.Params[k] = v
+		}
+	}
+}
+
+// WithExtraMetadata merges keys into GenerateOptions.ExtraMetadata, which a
+// provider merges into the returned LLMResponse.Metadata alongside its own
+// reserved keys (see MetadataKeyModel etc.) - a way to stash caller-defined
+// correlation data (e.g. a request ID) that travels with the response.
+func WithExtraMetadata(metadata map[string]interface{}) GenerateOption {
+	return func(o *GenerateOptions) {
+		if o.ExtraMetadata == nil {
+			o.ExtraMetadata = make(map[string]interface{})
+		}
+		for k, v := range metadata {
+			o.ExtraMetadata[k] = v
+		}
+	}
+}
+
+// WithThinkingBudget sets the token budget a provider's thinking mode may
+// spend on internal reasoning before answering. See GenerateOptions.ThinkingBudget.
+func WithThinkingBudget(tokens int) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.ThinkingBudget = tokens
+	}
+}
+
+// WithIncludeThoughts asks a provider that supports thinking mode to
+// surface its reasoning text distinctly from the answer. See
+// GenerateOptions.IncludeThoughts.
+func WithIncludeThoughts() GenerateOption {
+	return func(o *GenerateOptions) {
+		o.IncludeThoughts = true
+	}
+}
+
 func WithModel(model string) EmbeddingOption {
 	return func(o *EmbeddingOptions) {
 		o.Model = model
@@ -310,6 +496,16 @@ type EndpointConfig struct {
 	SchemaConfig *SchemaConfig
 }
 
+// TokenSource supplies a bearer token for OAuth-based authentication, such
+// as a Vertex AI service account, instead of a static API key. Token is
+// called on every request so implementations are expected to cache and
+// refresh the underlying credential themselves (e.g. wrapping
+// golang.org/x/oauth2.TokenSource) rather than push that responsibility
+// onto every caller.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
 // TransportConfig configures HTTP connection pooling behavior for LLM requests.
 // Tuning these values can significantly improve performance for parallel workloads.
 type TransportConfig struct {
@@ -377,6 +573,7 @@ type BaseLLM struct {
 	endpoint     *EndpointConfig // Optional endpoint configuration
 	client       *http.Client    // Common HTTP client
 	schemaConfig *SchemaConfig
+	metricsHook  MetricsHook // Optional; defaults to a no-op hook, see WithMetricsHook
 }
 
 // ProviderName implements LLM interface.
@@ -434,6 +631,7 @@ func NewBaseLLM(providerName string, modelID ModelID, capabilities []Capability,
 		endpoint:     endpoint,
 		client:       client,
 		schemaConfig: endpoint.SchemaConfig,
+		metricsHook:  defaultMetricsHook,
 	}
 
 	// Apply custom options (e.g., WithTransportConfig)
@@ -465,8 +663,16 @@ func (b *BaseLLM) GetEndpointConfig() *EndpointConfig {
 	return b.endpoint
 }
 
-// GetHTTPClient returns the HTTP client.
+// GetHTTPClient returns the HTTP client used for requests, falling back to
+// http.DefaultClient if none was set - a BaseLLM built via NewBaseLLM
+// always has one, but a BaseLLM assembled some other way (e.g. a
+// zero-valued struct literal) could still reach here with a nil client.
+// Request helpers that call this can rely on it never returning nil rather
+// than each needing their own nil check.
 func (b *BaseLLM) GetHTTPClient() *http.Client {
+	if b.client == nil {
+		return http.DefaultClient
+	}
 	return b.client
 }
 
@@ -622,8 +828,10 @@ func IsMultimodalContent(signature Signature, inputs map[string]any) bool {
 	return false
 }
 
-// SetDefaultLLM sets the default LLM.
+// SetDefaultLLM sets the default LLM, e.g. to inject a fake in tests.
 func SetDefaultLLM(llm LLM) {
+	defaultLLMMu.Lock()
+	defer defaultLLMMu.Unlock()
 	GlobalConfig.DefaultLLM = llm
 }
 
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 )
@@ -171,3 +172,15 @@ func TestWithTransportConfig(t *testing.T) {
 		t.Error("Expected HTTP client to be set")
 	}
 }
+
+// TestBaseLLM_GetHTTPClientDefaultsWhenNil covers a BaseLLM that never went
+// through NewBaseLLM, so its client field was never assigned.
+func TestBaseLLM_GetHTTPClientDefaultsWhenNil(t *testing.T) {
+	llm := &BaseLLM{}
+
+	client := llm.GetHTTPClient()
+
+	if client != http.DefaultClient {
+		t.Errorf("expected GetHTTPClient to fall back to http.DefaultClient, got %v", client)
+	}
+}
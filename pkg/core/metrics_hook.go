@@ -0,0 +1,72 @@
+package core
+
+// MetricsHook receives aggregate instrumentation events emitted by LLM
+// implementations: request counts, latency, token usage, retry attempts,
+// and errors grouped by error code. It is intentionally minimal so it can
+// be backed by Prometheus, StatsD, or any other metrics backend without
+// pulling a dependency into this module.
+//
+// Both methods are called on the hot path of every request, so
+// implementations should avoid allocations and blocking I/O where possible.
+type MetricsHook interface {
+	// IncrementCounter increments the named counter by one, tagged with
+	// the given key/value pairs (e.g. "provider", "model", "code").
+	IncrementCounter(name string, tags map[string]string)
+
+	// ObserveHistogram records a single observation (e.g. request latency
+	// in seconds, or a token count) under the named histogram.
+	ObserveHistogram(name string, value float64, tags map[string]string)
+}
+
+// MetricsHookProvider is implemented by LLMs that expose their configured
+// MetricsHook, letting code outside the LLM (such as a module's retry loop)
+// emit metrics to the same backend.
+type MetricsHookProvider interface {
+	GetMetricsHook() MetricsHook
+}
+
+// noOpMetricsHook discards every event. It is the default hook for LLMs
+// that aren't configured with WithMetricsHook.
+type noOpMetricsHook struct{}
+
+func (noOpMetricsHook) IncrementCounter(name string, tags map[string]string)                {}
+func (noOpMetricsHook) ObserveHistogram(name string, value float64, tags map[string]string) {}
+
+// defaultMetricsHook is shared by every BaseLLM so GetMetricsHook never
+// returns nil.
+var defaultMetricsHook MetricsHook = noOpMetricsHook{}
+
+// WithMetricsHook configures the MetricsHook an LLM reports request
+// latency, token, and error-by-code events to. A nil hook is ignored and
+// the default no-op hook is kept.
+//
+// Example Prometheus adapter:
+//
+//	type prometheusHook struct {
+//		counters   *prometheus.CounterVec
+//		histograms *prometheus.HistogramVec
+//	}
+//
+//	func (h *prometheusHook) IncrementCounter(name string, tags map[string]string) {
+//		h.counters.WithLabelValues(labelValues(name, tags)...).Inc()
+//	}
+//
+//	func (h *prometheusHook) ObserveHistogram(name string, value float64, tags map[string]string) {
+//		h.histograms.WithLabelValues(labelValues(name, tags)...).Observe(value)
+//	}
+func WithMetricsHook(hook MetricsHook) BaseLLMOption {
+	return func(b *BaseLLM) {
+		if hook != nil {
+			b.metricsHook = hook
+		}
+	}
+}
+
+// GetMetricsHook returns the configured MetricsHook, or a no-op hook if
+// none was set via WithMetricsHook.
+func (b *BaseLLM) GetMetricsHook() MetricsHook {
+	if b.metricsHook == nil {
+		return defaultMetricsHook
+	}
+	return b.metricsHook
+}
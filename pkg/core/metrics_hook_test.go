@@ -0,0 +1,58 @@
+package core
+
+import "testing"
+
+type recordingMetricsHook struct {
+	counters   []string
+	histograms []string
+}
+
+func (h *recordingMetricsHook) IncrementCounter(name string, tags map[string]string) {
+	h.counters = append(h.counters, name)
+}
+
+func (h *recordingMetricsHook) ObserveHistogram(name string, value float64, tags map[string]string) {
+	h.histograms = append(h.histograms, name)
+}
+
+// TestBaseLLM_GetMetricsHook_DefaultsToNoOp verifies an LLM created without
+// WithMetricsHook still has a usable, non-nil hook.
+func TestBaseLLM_GetMetricsHook_DefaultsToNoOp(t *testing.T) {
+	llm := NewBaseLLM("gemini", "test-model", nil, nil)
+
+	hook := llm.GetMetricsHook()
+	if hook == nil {
+		t.Fatal("Expected a non-nil default MetricsHook")
+	}
+
+	// Should not panic, and should not be observable anywhere.
+	hook.IncrementCounter("requests", nil)
+	hook.ObserveHistogram("latency", 1.23, nil)
+}
+
+// TestWithMetricsHook verifies the WithMetricsHook option wires a custom
+// hook into the LLM, and that every LLM satisfies MetricsHookProvider.
+func TestWithMetricsHook(t *testing.T) {
+	hook := &recordingMetricsHook{}
+	llm := NewBaseLLM("gemini", "test-model", nil, nil, WithMetricsHook(hook))
+
+	var provider MetricsHookProvider = llm
+	if provider.GetMetricsHook() != hook {
+		t.Error("Expected GetMetricsHook to return the configured hook")
+	}
+
+	provider.GetMetricsHook().IncrementCounter("llm_requests_total", map[string]string{"model": "test-model"})
+	if len(hook.counters) != 1 || hook.counters[0] != "llm_requests_total" {
+		t.Errorf("Expected one recorded counter increment, got %v", hook.counters)
+	}
+}
+
+// TestWithMetricsHook_NilHookIgnored verifies passing a nil hook keeps the
+// default no-op hook rather than leaving GetMetricsHook able to return nil.
+func TestWithMetricsHook_NilHookIgnored(t *testing.T) {
+	llm := NewBaseLLM("gemini", "test-model", nil, nil, WithMetricsHook(nil))
+
+	if llm.GetMetricsHook() == nil {
+		t.Error("Expected a non-nil MetricsHook even when WithMetricsHook(nil) is used")
+	}
+}
@@ -267,7 +267,7 @@ func TestProcessTypedWithValidation(t *testing.T) {
 				// Context missing
 			},
 			wantErr: true,
-			errMsg:  "required input field 'context' cannot be empty",
+			errMsg:  "input.context: required field cannot be empty",
 		},
 	}
 
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/darwishdev/dspy-go/pkg/utils"
+)
+
+// CoerceToOutput converts a map[string]any - typically the result of
+// GenerateWithJSON, which has no static type to decode into - into TOutput,
+// using sig's output field metadata (types, required-ness, nested
+// properties) the same way validateStruct does. This is the missing glue
+// between the map-returning LLM path and typed outputs: json.Unmarshal
+// already decodes numbers as float64 regardless of the target field's
+// width, so a plain type assertion on a map value fails for an int output
+// field even when the value is perfectly convertible.
+//
+// A field whose value doesn't match its expected shape (e.g. a string
+// where an object was expected) returns a *ValidationError carrying the
+// dotted/indexed path to the offending field, so a caller doesn't need to
+// parse an error string to find out which field broke.
+func CoerceToOutput[TInput, TOutput any](m map[string]any, sig TypedSignature[TInput, TOutput]) (TOutput, error) {
+	var zero TOutput
+	if m == nil {
+		return zero, &ValidationError{Path: "output", Message: "cannot be nil"}
+	}
+
+	outputType := reflect.TypeOf(zero)
+	isPtr := outputType != nil && outputType.Kind() == reflect.Ptr
+	structType := outputType
+	if isPtr {
+		structType = structType.Elem()
+	}
+	if structType == nil || structType.Kind() != reflect.Struct {
+		return zero, &ValidationError{Path: "output", Message: fmt.Sprintf("output type must be a struct, got %s", outputType)}
+	}
+
+	out := reflect.New(structType).Elem()
+	if err := coerceFieldsInto(out, sig.GetFieldMetadata().Outputs, m, "output"); err != nil {
+		return zero, err
+	}
+
+	if isPtr {
+		return out.Addr().Interface().(TOutput), nil
+	}
+	return out.Interface().(TOutput), nil
+}
+
+// coerceFieldsInto fills dst (a struct value) from data, field by field,
+// per fields' metadata. Missing-but-required fields and per-field
+// conversion failures both return a path-qualified *ValidationError rather
+// than continuing with a half-populated struct.
+func coerceFieldsInto(dst reflect.Value, fields []FieldMetadata, data map[string]any, path string) error {
+	for _, field := range fields {
+		fieldVal := dst.FieldByName(field.GoFieldName)
+		if !fieldVal.IsValid() || !fieldVal.CanSet() {
+			continue
+		}
+
+		fieldPath := path + "." + field.Name
+		raw, exists := data[field.Name]
+		if !exists || raw == nil {
+			if field.Required {
+				return &ValidationError{Path: fieldPath, Message: "required field is missing", Field: &field}
+			}
+			continue
+		}
+
+		if err := coerceValue(fieldVal, field, raw, fieldPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// coerceValue converts raw into dst per meta's field type, recursing into
+// coerceFieldsInto/coerceArray for nested objects/arrays (whose static
+// shape meta.Properties/meta.Item describes) and falling back to
+// utils.SetFieldValue - the same numeric/bool/string coercion
+// PopulateStructFromMap already relies on - for everything else.
+func coerceValue(dst reflect.Value, meta FieldMetadata, raw any, path string) error {
+	switch meta.Type {
+	case FieldTypeObject:
+		rawMap, ok := raw.(map[string]any)
+		if !ok {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("expected an object, got %T", raw), Field: &meta}
+		}
+		return coerceObject(dst, meta, rawMap, path)
+
+	case FieldTypeArray:
+		rawSlice, ok := raw.([]any)
+		if !ok {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("expected an array, got %T", raw), Field: &meta}
+		}
+		return coerceArray(dst, meta, rawSlice, path)
+
+	default:
+		if err := utils.SetFieldValue(dst, raw); err != nil {
+			return &ValidationError{Path: path, Message: err.Error(), Field: &meta}
+		}
+		return nil
+	}
+}
+
+// coerceObject fills dst from raw. dst may be a struct, a pointer to one
+// (allocated here if nil), or a non-struct object-ish type like
+// map[string]any, which utils.SetFieldValue can assign directly.
+func coerceObject(dst reflect.Value, meta FieldMetadata, raw map[string]any, path string) error {
+	targetType := dst.Type()
+	if targetType.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(targetType.Elem()))
+		}
+		return coerceObject(dst.Elem(), meta, raw, path)
+	}
+
+	if targetType.Kind() != reflect.Struct {
+		if err := utils.SetFieldValue(dst, raw); err != nil {
+			return &ValidationError{Path: path, Message: err.Error(), Field: &meta}
+		}
+		return nil
+	}
+
+	return coerceFieldsInto(dst, flatten(meta.Properties, meta.PropertyOrder), raw, path)
+}
+
+// coerceArray fills dst (a slice) from raw, recursing per-element via
+// meta.Item when the array's element shape is known (e.g. a slice of
+// structs), and falling back to utils.SetFieldValue per element otherwise.
+func coerceArray(dst reflect.Value, meta FieldMetadata, raw []any, path string) error {
+	elemType := dst.Type().Elem()
+	slice := reflect.MakeSlice(dst.Type(), 0, len(raw))
+
+	for i, item := range raw {
+		elemPath := fmt.Sprintf("%s[%d]", path, i)
+		elemVal := reflect.New(elemType).Elem()
+
+		if item == nil {
+			slice = reflect.Append(slice, elemVal)
+			continue
+		}
+
+		if meta.Item != nil {
+			if err := coerceValue(elemVal, *meta.Item, item, elemPath); err != nil {
+				return err
+			}
+		} else if err := utils.SetFieldValue(elemVal, item); err != nil {
+			return &ValidationError{Path: elemPath, Message: err.Error(), Field: meta.Item}
+		}
+
+		slice = reflect.Append(slice, elemVal)
+	}
+
+	dst.Set(slice)
+	return nil
+}
@@ -0,0 +1,27 @@
+package core
+
+// PrefixStyle renders the display prefix used for a field when a module
+// formats a signature into a prompt. It's a separate concern from
+// Field.Prefix (which remains the parsing anchor stripMarkdown looks for):
+// a style only changes how a field is *rendered*, letting the same
+// signature target different prompt conventions without touching its field
+// definitions.
+type PrefixStyle func(field Field) string
+
+// ColonPrefixStyle renders a field using its stored Field.Prefix as-is.
+// This matches the package's long-standing default ("name:") and is what
+// modules use when no PrefixStyle is configured.
+func ColonPrefixStyle(field Field) string {
+	return field.Prefix
+}
+
+// MarkdownHeaderPrefixStyle renders a field as a markdown header, e.g.
+// "### answer".
+func MarkdownHeaderPrefixStyle(field Field) string {
+	return "### " + field.Name
+}
+
+// XMLTagPrefixStyle renders a field as an opening XML tag, e.g. "<answer>".
+func XMLTagPrefixStyle(field Field) string {
+	return "<" + field.Name + ">"
+}
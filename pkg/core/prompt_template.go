@@ -0,0 +1,123 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"text/template/parse"
+)
+
+// PromptTemplate is a text/template-backed prompt whose placeholders are
+// checked against a Signature's input fields before rendering, so a typo
+// in a `{{.FieldName}}` placeholder fails fast instead of silently
+// producing a blank prompt. Conditionals (`{{if}}`) and loops
+// (`{{range}}`) over array inputs are supported since rendering
+// delegates directly to text/template.
+type PromptTemplate struct {
+	text string
+	tmpl *template.Template
+}
+
+// NewPromptTemplate parses text as a text/template. Parsing errors are
+// returned immediately; placeholder-to-field validation happens in
+// Render, once the signature it must match is known.
+func NewPromptTemplate(name, text string) (*PromptTemplate, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("prompt template: parse failed: %w", err)
+	}
+
+	return &PromptTemplate{text: text, tmpl: tmpl}, nil
+}
+
+// Render executes the template against data, which should be a struct or
+// map whose fields/keys correspond to sig's input names. It returns an
+// error without rendering if the template references a placeholder that
+// is not one of sig's input fields, and returns an error if data is
+// missing a value the template needs.
+func (p *PromptTemplate) Render(sig Signature, data any) (string, error) {
+	known := make(map[string]bool, len(sig.Inputs))
+	for _, in := range sig.Inputs {
+		known[in.Name] = true
+	}
+
+	for _, field := range referencedTemplateFields(p.tmpl) {
+		if !known[field] {
+			return "", fmt.Errorf("prompt template: placeholder %q does not match any input field in signature", field)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := p.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("prompt template: render failed: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// String returns the underlying template text.
+func (p *PromptTemplate) String() string {
+	return p.text
+}
+
+// referencedTemplateFields walks tmpl's parse tree, collecting the names
+// of top-level fields (".Name") referenced anywhere in the template,
+// including inside if/range/with blocks.
+func referencedTemplateFields(tmpl *template.Template) []string {
+	seen := make(map[string]bool)
+	var fields []string
+
+	var walk func(nodes []parse.Node)
+	walk = func(nodes []parse.Node) {
+		for _, n := range nodes {
+			switch node := n.(type) {
+			case *parse.ActionNode:
+				collectPipeFields(node.Pipe, seen, &fields)
+			case *parse.IfNode:
+				collectPipeFields(node.Pipe, seen, &fields)
+				walk(node.List.Nodes)
+				if node.ElseList != nil {
+					walk(node.ElseList.Nodes)
+				}
+			case *parse.RangeNode:
+				collectPipeFields(node.Pipe, seen, &fields)
+				walk(node.List.Nodes)
+				if node.ElseList != nil {
+					walk(node.ElseList.Nodes)
+				}
+			case *parse.WithNode:
+				collectPipeFields(node.Pipe, seen, &fields)
+				walk(node.List.Nodes)
+				if node.ElseList != nil {
+					walk(node.ElseList.Nodes)
+				}
+			case *parse.ListNode:
+				walk(node.Nodes)
+			}
+		}
+	}
+
+	walk(tmpl.Root.Nodes)
+	return fields
+}
+
+// collectPipeFields extracts top-level field names (the first identifier
+// of a ".Name" or ".Name.Nested" reference) from a template pipeline.
+func collectPipeFields(pipe *parse.PipeNode, seen map[string]bool, fields *[]string) {
+	if pipe == nil {
+		return
+	}
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			field, ok := arg.(*parse.FieldNode)
+			if !ok || len(field.Ident) == 0 {
+				continue
+			}
+			name := field.Ident[0]
+			if !seen[name] {
+				seen[name] = true
+				*fields = append(*fields, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptTemplateRenderBasic(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "Question"}}},
+		[]OutputField{{Field: Field{Name: "Answer"}}},
+	)
+
+	tmpl, err := NewPromptTemplate("basic", "Answer this question: {{.Question}}")
+	require.NoError(t, err)
+
+	out, err := tmpl.Render(sig, struct{ Question string }{Question: "What is Go?"})
+	require.NoError(t, err)
+	assert.Equal(t, "Answer this question: What is Go?", out)
+}
+
+func TestPromptTemplateRenderUnknownPlaceholder(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "Question"}}},
+		[]OutputField{{Field: Field{Name: "Answer"}}},
+	)
+
+	tmpl, err := NewPromptTemplate("typo", "Answer this: {{.Qeustion}}")
+	require.NoError(t, err)
+
+	_, err = tmpl.Render(sig, struct{ Qeustion string }{Qeustion: "oops"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Qeustion")
+}
+
+func TestPromptTemplateRenderMissingInput(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "Question"}}},
+		[]OutputField{{Field: Field{Name: "Answer"}}},
+	)
+
+	tmpl, err := NewPromptTemplate("missing", "Answer this question: {{.Question}}")
+	require.NoError(t, err)
+
+	_, err = tmpl.Render(sig, struct{ Other string }{Other: "value"})
+	require.Error(t, err)
+}
+
+func TestPromptTemplateRenderRangeOverArrayInput(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "Items"}}},
+		[]OutputField{{Field: Field{Name: "Summary"}}},
+	)
+
+	tmpl, err := NewPromptTemplate("range", "Items:{{range .Items}} {{.}}{{end}}")
+	require.NoError(t, err)
+
+	out, err := tmpl.Render(sig, struct{ Items []string }{Items: []string{"a", "b", "c"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Items: a b c", out)
+}
+
+func TestPromptTemplateRenderConditional(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "Context"}}},
+		[]OutputField{{Field: Field{Name: "Answer"}}},
+	)
+
+	tmpl, err := NewPromptTemplate("conditional", "{{if .Context}}Context: {{.Context}}{{else}}No context provided{{end}}")
+	require.NoError(t, err)
+
+	out, err := tmpl.Render(sig, struct{ Context string }{Context: "background info"})
+	require.NoError(t, err)
+	assert.Equal(t, "Context: background info", out)
+
+	out, err = tmpl.Render(sig, struct{ Context string }{})
+	require.NoError(t, err)
+	assert.Equal(t, "No context provided", out)
+}
+
+func TestNewPromptTemplateParseError(t *testing.T) {
+	_, err := NewPromptTemplate("bad", "{{.Unclosed")
+	require.Error(t, err)
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"fmt"
+)
+
+// GenerateRepairPrompt builds a correction instruction for an output that
+// failed validation against sig, naming the exact field verr is about and
+// restating sig's expected output shape (via ExampleOutputJSON), so a
+// re-prompted model has both "what went wrong" and "what it should look
+// like instead" in one place. It's deterministic - the same sig and verr
+// always produce the same string - which is what lets a validation-retry
+// loop (see GenerateWithJSONRetry) be driven by a fixed, repeatable prompt
+// in tests rather than whatever wording a live model happens to produce.
+//
+// Returns "" for a nil verr, since there's nothing to repair.
+func GenerateRepairPrompt(sig Signature, verr *ValidationError) string {
+	if verr == nil {
+		return ""
+	}
+
+	fieldName := verr.Path
+	if verr.Field != nil && verr.Field.Name != "" {
+		fieldName = verr.Field.Name
+	}
+
+	instruction := fmt.Sprintf("Your previous response was invalid: field %q %s.", fieldName, verr.Message)
+
+	if len(sig.Outputs) == 0 {
+		return instruction + " Please correct it and respond again."
+	}
+
+	example, err := sig.ExampleOutputJSON()
+	if err != nil {
+		return instruction + " Please correct it and respond again."
+	}
+
+	return fmt.Sprintf("%s Please correct it and respond again, matching this schema:\n%s", instruction, example)
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateRepairPromptNamesTheViolatedField(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "question", Type: FieldTypeText}}},
+		[]OutputField{
+			{Field: Field{Name: "answer", Type: FieldTypeString}},
+			{Field: Field{Name: "confidence", Type: FieldTypeInt}},
+		},
+	)
+	verr := &ValidationError{
+		Path:    "output.confidence",
+		Message: "is required but missing",
+		Field:   &FieldMetadata{Name: "confidence", Type: FieldTypeInt},
+	}
+
+	prompt := GenerateRepairPrompt(sig, verr)
+
+	if !strings.Contains(prompt, "confidence") {
+		t.Errorf("expected prompt to name the violated field, got: %s", prompt)
+	}
+	if !strings.Contains(prompt, "is required but missing") {
+		t.Errorf("expected prompt to include the validation message, got: %s", prompt)
+	}
+	if !strings.Contains(prompt, "answer") {
+		t.Errorf("expected prompt to restate the output schema, got: %s", prompt)
+	}
+}
+
+func TestGenerateRepairPromptFallsBackToPathWithoutFieldMetadata(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "question", Type: FieldTypeText}}},
+		[]OutputField{{Field: Field{Name: "answer", Type: FieldTypeString}}},
+	)
+	verr := &ValidationError{Path: "output.answer", Message: "cannot be empty"}
+
+	prompt := GenerateRepairPrompt(sig, verr)
+
+	if !strings.Contains(prompt, "output.answer") {
+		t.Errorf("expected prompt to fall back to Path when Field is nil, got: %s", prompt)
+	}
+}
+
+func TestGenerateRepairPromptIsDeterministic(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "question", Type: FieldTypeText}}},
+		[]OutputField{{Field: Field{Name: "answer", Type: FieldTypeString}}},
+	)
+	verr := &ValidationError{Path: "output.answer", Message: "cannot be empty"}
+
+	first := GenerateRepairPrompt(sig, verr)
+	second := GenerateRepairPrompt(sig, verr)
+
+	if first != second {
+		t.Errorf("expected GenerateRepairPrompt to be deterministic, got %q and %q", first, second)
+	}
+}
+
+func TestGenerateRepairPromptNilValidationErrorReturnsEmpty(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "question", Type: FieldTypeText}}},
+		[]OutputField{{Field: Field{Name: "answer", Type: FieldTypeString}}},
+	)
+
+	if got := GenerateRepairPrompt(sig, nil); got != "" {
+		t.Errorf("expected empty string for a nil ValidationError, got %q", got)
+	}
+}
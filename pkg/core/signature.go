@@ -1,8 +1,12 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+
+	"github.com/darwishdev/dspy-go/pkg/utils"
 )
 
 // FieldType represents the type of data a field can contain.
@@ -27,6 +31,40 @@ type Field struct {
 	Type        FieldType         // Data type for the field
 	Items       *Field            // For array types, this represents the item type
 	Properties  map[string]*Field // For object types, this holds nested fields
+
+	// Sensitive marks a field as carrying PII or other data that shouldn't
+	// appear in logs/traces. The value is still sent to the model as usual -
+	// see RedactSensitiveInputs, which observability call sites use to build
+	// a masked copy of inputs for logging/tracing only.
+	Sensitive bool
+
+	// Transient marks an output field as streaming-only scratch content
+	// (e.g. chain-of-thought reasoning): it's still rendered in the prompt
+	// instructions and expected in the raw completion, but dropped from the
+	// parsed structured output and excluded from a JSON response schema's
+	// required set, so it never appears in the typed result.
+	Transient bool
+
+	// Optional marks an input field as not required. Inputs default to
+	// required (Optional == false), matching how the prompt renderer treats
+	// every input as expected unless told otherwise.
+	Optional bool
+
+	// Enum, Min, Max, and Pattern are optional validation constraints
+	// carried alongside the field's type. They don't affect prompt
+	// rendering or parsing - they exist so metadata already known about a
+	// field (allowed values, numeric bounds, a regex a string must match)
+	// can be reused by consumers like Signature.UIFormSchema instead of
+	// being redeclared a second time for a form.
+	Enum    []string
+	Min     *float64
+	Max     *float64
+	Pattern string
+
+	// Example holds a concrete sample value for the field, used by
+	// Signature.ExampleOutputJSON in place of a type-appropriate
+	// placeholder when set.
+	Example interface{}
 }
 
 // NewField creates a new Field with smart defaults and customizable options.
@@ -70,6 +108,62 @@ func WithNoPrefix() FieldOption {
 	}
 }
 
+// WithSensitive marks the field as Sensitive. See Field.Sensitive.
+func WithSensitive() FieldOption {
+	return func(f *Field) {
+		f.Sensitive = true
+	}
+}
+
+// WithTransient marks the field as Transient. See Field.Transient.
+func WithTransient() FieldOption {
+	return func(f *Field) {
+		f.Transient = true
+	}
+}
+
+// WithOptional marks the field as not required. See Field.Optional.
+func WithOptional() FieldOption {
+	return func(f *Field) {
+		f.Optional = true
+	}
+}
+
+// WithEnum restricts the field to a fixed set of allowed string values.
+func WithEnum(values ...string) FieldOption {
+	return func(f *Field) {
+		f.Enum = values
+	}
+}
+
+// WithMin sets the field's minimum numeric value.
+func WithMin(min float64) FieldOption {
+	return func(f *Field) {
+		f.Min = &min
+	}
+}
+
+// WithMax sets the field's maximum numeric value.
+func WithMax(max float64) FieldOption {
+	return func(f *Field) {
+		f.Max = &max
+	}
+}
+
+// WithPattern sets a regular expression the field's string value must match.
+func WithPattern(pattern string) FieldOption {
+	return func(f *Field) {
+		f.Pattern = pattern
+	}
+}
+
+// WithExample sets a concrete sample value for the field. See Field.Example.
+func WithExample(value interface{}) FieldOption {
+	return func(f *Field) {
+		f.Example = value
+	}
+}
+
 // WithFieldType sets the field type.
 func WithFieldType(fieldType FieldType) FieldOption {
 	return func(f *Field) {
@@ -179,35 +273,60 @@ func (s Signature) String() string {
 	return sb.String()
 }
 
-// ParseSignature parses a signature string into a Signature struct.
+// ParseSignature parses a shorthand signature string of the form
+// "input1, input2 -> output1, output2" into a Signature. It rejects a
+// string with anything other than exactly one "->", and an input or output
+// side that has no field names left once empty entries - e.g. from a
+// trailing or doubled comma - are dropped.
 func ParseSignature(signatureStr string) (Signature, error) {
 	parts := strings.Split(signatureStr, "->")
 	if len(parts) != 2 {
-		return Signature{}, fmt.Errorf("invalid signature format: %s", signatureStr)
+		return Signature{}, fmt.Errorf("invalid signature format %q: expected exactly one \"->\", found %d", signatureStr, len(parts)-1)
 	}
 
 	inputs := parseInputFields(strings.TrimSpace(parts[0]))
+	if len(inputs) == 0 {
+		return Signature{}, fmt.Errorf("invalid signature format %q: no input fields before \"->\"", signatureStr)
+	}
+
 	outputs := parseOutputFields(strings.TrimSpace(parts[1]))
+	if len(outputs) == 0 {
+		return Signature{}, fmt.Errorf("invalid signature format %q: no output fields after \"->\"", signatureStr)
+	}
 
 	return NewSignature(inputs, outputs), nil
 }
 
-func parseInputFields(fieldsStr string) []InputField {
+// splitFieldNames splits a comma-separated field list, trims whitespace
+// from each entry, and drops any that come out empty - e.g. from a
+// trailing or doubled comma - rather than letting an empty field name
+// through.
+func splitFieldNames(fieldsStr string) []string {
 	fieldStrs := strings.Split(fieldsStr, ",")
-	fields := make([]InputField, len(fieldStrs))
-	for i, fieldStr := range fieldStrs {
-		fieldStr = strings.TrimSpace(fieldStr)
-		fields[i] = InputField{Field: Field{Name: fieldStr}}
+	names := make([]string, 0, len(fieldStrs))
+	for _, fieldStr := range fieldStrs {
+		name := strings.TrimSpace(fieldStr)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func parseInputFields(fieldsStr string) []InputField {
+	names := splitFieldNames(fieldsStr)
+	fields := make([]InputField, len(names))
+	for i, name := range names {
+		fields[i] = InputField{Field: Field{Name: name}}
 	}
 	return fields
 }
 
 func parseOutputFields(fieldsStr string) []OutputField {
-	fieldStrs := strings.Split(fieldsStr, ",")
-	fields := make([]OutputField, len(fieldStrs))
-	for i, fieldStr := range fieldStrs {
-		fieldStr = strings.TrimSpace(fieldStr)
-		fields[i] = OutputField{Field: Field{Name: fieldStr}}
+	names := splitFieldNames(fieldsStr)
+	fields := make([]OutputField, len(names))
+	for i, name := range names {
+		fields[i] = OutputField{Field: Field{Name: name}}
 	}
 	return fields
 }
@@ -242,3 +361,375 @@ func (s Signature) PrependOutput(name string, prefix string, description string)
 	s.Outputs = append([]OutputField{newOutput}, s.Outputs...)
 	return s
 }
+
+// Validate checks that the signature is structurally well-formed: no empty
+// field names, no duplicate names among its inputs or among its outputs,
+// array fields carry an Items type, object fields carry Properties, and no
+// two outputs have the same or an overlapping prefix (one prefix that is
+// itself a prefix of another, e.g. "ans:" and "answer:", not just an exact
+// duplicate) - either case leaves prefix-based parsing unable to tell the
+// fields apart. It returns a descriptive error for the first problem found,
+// or nil if the signature is well-formed.
+func (s Signature) Validate() error {
+	seenInputs := make(map[string]bool, len(s.Inputs))
+	for _, input := range s.Inputs {
+		if err := validateSignatureField(input.Field, "input"); err != nil {
+			return err
+		}
+		if seenInputs[input.Name] {
+			return fmt.Errorf("signature has duplicate input field name %q", input.Name)
+		}
+		seenInputs[input.Name] = true
+	}
+
+	seenOutputs := make(map[string]bool, len(s.Outputs))
+	var seenPrefixes []outputPrefix
+	for _, output := range s.Outputs {
+		if err := validateSignatureField(output.Field, "output"); err != nil {
+			return err
+		}
+		if seenOutputs[output.Name] {
+			return fmt.Errorf("signature has duplicate output field name %q", output.Name)
+		}
+		seenOutputs[output.Name] = true
+
+		if output.Prefix == "" {
+			continue
+		}
+		normalizedPrefix := strings.ToLower(strings.TrimSpace(output.Prefix))
+		comparablePrefix := strings.TrimSuffix(normalizedPrefix, ":")
+		for _, other := range seenPrefixes {
+			if normalizedPrefix == other.normalized {
+				return fmt.Errorf("signature outputs %q and %q share the same prefix %q, which breaks prefix-based parsing", other.name, output.Name, output.Prefix)
+			}
+			if strings.HasPrefix(comparablePrefix, other.comparable) || strings.HasPrefix(other.comparable, comparablePrefix) {
+				return fmt.Errorf("signature outputs %q and %q have overlapping prefixes (%q and %q), which breaks prefix-based parsing", other.name, output.Name, other.raw, output.Prefix)
+			}
+		}
+		seenPrefixes = append(seenPrefixes, outputPrefix{name: output.Name, raw: output.Prefix, normalized: normalizedPrefix, comparable: comparablePrefix})
+	}
+
+	return nil
+}
+
+// outputPrefix records one already-validated output's prefix, so a later
+// output in Validate's loop can be checked against every prefix seen so
+// far: normalized (lower-cased, trimmed) for an exact-match check, and
+// comparable (normalized with its trailing separator stripped) for the
+// overlap check, so "ans:" is recognized as overlapping "answer:" even
+// though "ans:" isn't a literal string prefix of "answer:".
+type outputPrefix struct {
+	name       string
+	raw        string
+	normalized string
+	comparable string
+}
+
+// CoerceNumericOutputs converts outputs' int-typed fields (per s.Outputs)
+// from whatever numeric shape they decoded as - json.Number from
+// ParseJSONResponseWithNumber, a plain float64, a numeric string, or a
+// numeric string carrying thousands separators ("1,234") or underscore
+// digit grouping ("1_234") - into int64. Fields not declared FieldTypeInt,
+// or whose value isn't a recognizable number, are left untouched. It
+// returns a new map; outputs itself is not modified.
+func (s Signature) CoerceNumericOutputs(outputs map[string]interface{}) map[string]interface{} {
+	return s.coerceNumericOutputs(outputs, false)
+}
+
+// CoerceNumericOutputsTolerant behaves like CoerceNumericOutputs, but when
+// allowWrittenNumbers is true it also recognizes simple English written
+// numbers like "forty-two" (see utils.CoerceNumericFieldTolerant). This is
+// a separate method, rather than CoerceNumericOutputs' default behavior,
+// since the written-number vocabulary is small and could otherwise
+// misinterpret an ordinary text value as a number.
+func (s Signature) CoerceNumericOutputsTolerant(outputs map[string]interface{}, allowWrittenNumbers bool) map[string]interface{} {
+	return s.coerceNumericOutputs(outputs, allowWrittenNumbers)
+}
+
+func (s Signature) coerceNumericOutputs(outputs map[string]interface{}, allowWrittenNumbers bool) map[string]interface{} {
+	coerced := make(map[string]interface{}, len(outputs))
+	for k, v := range outputs {
+		coerced[k] = v
+	}
+
+	for _, output := range s.Outputs {
+		if output.Type != FieldTypeInt {
+			continue
+		}
+		value, ok := coerced[output.Name]
+		if !ok {
+			continue
+		}
+		if converted, err := utils.CoerceNumericFieldTolerant(value, true, allowWrittenNumbers); err == nil {
+			coerced[output.Name] = converted
+		}
+	}
+
+	return coerced
+}
+
+// Merge combines s and other into a new Signature whose inputs are the
+// union of both signatures' inputs and whose outputs are the union of both
+// signatures' outputs. This is meant for composing pipeline stages where
+// stage N's outputs become (some of) stage N+1's inputs: merging stage N's
+// signature with stage N+1's signature produces a signature describing the
+// whole chain.
+//
+// A field name shared between the two signatures in the same input/output
+// role is kept once. A field that's an input of one signature and an
+// output of the other - the normal case for a pipeline, where a later
+// stage consumes an earlier stage's result - is dropped from the merged
+// inputs entirely, since it's supplied internally rather than being an
+// external requirement of the composed signature. Either way, the shared
+// field's two definitions must be structurally compatible - same Type,
+// and for array/object types, structurally compatible Items/Properties;
+// Description and Prefix aren't compared, since those are cosmetic. A
+// shared name with incompatible definitions is reported as an error;
+// nothing is merged in that case.
+func (s Signature) Merge(other Signature) (Signature, error) {
+	sInputs, err := dropInputsProducedElsewhere(s.Inputs, other.Outputs)
+	if err != nil {
+		return Signature{}, err
+	}
+	otherInputs, err := dropInputsProducedElsewhere(other.Inputs, s.Outputs)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	mergedInputs, err := mergeInputFields(sInputs, otherInputs)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	mergedOutputs, err := mergeOutputFields(s.Outputs, other.Outputs)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	merged := NewSignature(mergedInputs, mergedOutputs)
+	if s.Instruction != "" {
+		merged.Instruction = s.Instruction
+	} else {
+		merged.Instruction = other.Instruction
+	}
+	return merged, nil
+}
+
+// SelectOutputs returns a copy of s whose Outputs are only the named
+// fields, in the order names is given, preserving each field's full
+// definition (including nested Items/Properties) and s's Instruction. It
+// errors if names includes a field that isn't one of s's outputs, so a
+// typo fails at sub-signature construction time rather than silently
+// dropping the field.
+func (s Signature) SelectOutputs(names ...string) (Signature, error) {
+	byName := make(map[string]OutputField, len(s.Outputs))
+	for _, f := range s.Outputs {
+		byName[f.Name] = f
+	}
+
+	selected := make([]OutputField, 0, len(names))
+	for _, name := range names {
+		f, ok := byName[name]
+		if !ok {
+			return Signature{}, fmt.Errorf("signature: unknown output field %q", name)
+		}
+		selected = append(selected, f)
+	}
+
+	return Signature{Inputs: s.Inputs, Outputs: selected, Instruction: s.Instruction}, nil
+}
+
+// SelectInputs returns a copy of s whose Inputs are only the named fields,
+// in the order names is given. See SelectOutputs for the error and
+// preservation behavior, which this mirrors.
+func (s Signature) SelectInputs(names ...string) (Signature, error) {
+	byName := make(map[string]InputField, len(s.Inputs))
+	for _, f := range s.Inputs {
+		byName[f.Name] = f
+	}
+
+	selected := make([]InputField, 0, len(names))
+	for _, name := range names {
+		f, ok := byName[name]
+		if !ok {
+			return Signature{}, fmt.Errorf("signature: unknown input field %q", name)
+		}
+		selected = append(selected, f)
+	}
+
+	return Signature{Inputs: selected, Outputs: s.Outputs, Instruction: s.Instruction}, nil
+}
+
+// ExampleOutputJSON renders a JSON object with one key per output field, so
+// a model or a test fixture has a concrete shape to imitate. Each field's
+// value is its Field.Example when set; otherwise a type-appropriate
+// placeholder - a field's first Enum value if it has one, 0 for an int,
+// false for a bool, the string "example value" for any other scalar type,
+// a single-element array recursing into Items, or a nested object
+// recursing into Properties (in sorted key order, since Properties is a map).
+func (s Signature) ExampleOutputJSON() ([]byte, error) {
+	obj := make(map[string]interface{}, len(s.Outputs))
+	for _, f := range s.Outputs {
+		obj[f.Name] = exampleFieldValue(f.Field)
+	}
+	return json.MarshalIndent(obj, "", "  ")
+}
+
+func exampleFieldValue(field Field) interface{} {
+	if field.Example != nil {
+		return field.Example
+	}
+
+	if len(field.Enum) > 0 {
+		return field.Enum[0]
+	}
+
+	switch field.Type {
+	case FieldTypeInt:
+		return 0
+	case FieldTypeBool:
+		return false
+	case FieldTypeArray:
+		if field.Items == nil {
+			return []interface{}{}
+		}
+		return []interface{}{exampleFieldValue(*field.Items)}
+	case FieldTypeObject:
+		names := make([]string, 0, len(field.Properties))
+		for name := range field.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		obj := make(map[string]interface{}, len(names))
+		for _, name := range names {
+			obj[name] = exampleFieldValue(*field.Properties[name])
+		}
+		return obj
+	default:
+		return "example value"
+	}
+}
+
+// dropInputsProducedElsewhere removes any field from inputs that's already
+// produced as an output in producedBy, since a value supplied by the other
+// signature being merged with isn't an external requirement of the
+// composed pipeline. A name present on both sides with incompatible
+// definitions is reported as an error rather than silently dropped.
+func dropInputsProducedElsewhere(inputs []InputField, producedBy []OutputField) ([]InputField, error) {
+	produced := make(map[string]Field, len(producedBy))
+	for _, f := range producedBy {
+		produced[f.Name] = f.Field
+	}
+
+	remaining := make([]InputField, 0, len(inputs))
+	for _, f := range inputs {
+		out, ok := produced[f.Name]
+		if !ok {
+			remaining = append(remaining, f)
+			continue
+		}
+		if !fieldsCompatible(out, f.Field) {
+			return nil, fmt.Errorf("signature merge: %q is an input in one signature and an output in the other, with conflicting definitions", f.Name)
+		}
+	}
+	return remaining, nil
+}
+
+func mergeInputFields(a, b []InputField) ([]InputField, error) {
+	merged := make([]InputField, 0, len(a)+len(b))
+	byName := make(map[string]Field, len(a))
+
+	merged = append(merged, a...)
+	for _, f := range a {
+		byName[f.Name] = f.Field
+	}
+
+	for _, f := range b {
+		if existing, ok := byName[f.Name]; ok {
+			if !fieldsCompatible(existing, f.Field) {
+				return nil, fmt.Errorf("signature merge: input field %q has conflicting definitions", f.Name)
+			}
+			continue
+		}
+		byName[f.Name] = f.Field
+		merged = append(merged, f)
+	}
+
+	return merged, nil
+}
+
+func mergeOutputFields(a, b []OutputField) ([]OutputField, error) {
+	merged := make([]OutputField, 0, len(a)+len(b))
+	byName := make(map[string]Field, len(a))
+
+	merged = append(merged, a...)
+	for _, f := range a {
+		byName[f.Name] = f.Field
+	}
+
+	for _, f := range b {
+		if existing, ok := byName[f.Name]; ok {
+			if !fieldsCompatible(existing, f.Field) {
+				return nil, fmt.Errorf("signature merge: output field %q has conflicting definitions", f.Name)
+			}
+			continue
+		}
+		byName[f.Name] = f.Field
+		merged = append(merged, f)
+	}
+
+	return merged, nil
+}
+
+// fieldsCompatible reports whether a and b can stand for the same field when
+// merging two signatures: same Type, and for array/object types,
+// structurally compatible Items/Properties. Description and Prefix are
+// cosmetic and not compared.
+func fieldsCompatible(a, b Field) bool {
+	if a.Type != b.Type {
+		return false
+	}
+
+	switch a.Type {
+	case FieldTypeArray:
+		if (a.Items == nil) != (b.Items == nil) {
+			return false
+		}
+		if a.Items == nil {
+			return true
+		}
+		return fieldsCompatible(*a.Items, *b.Items)
+	case FieldTypeObject:
+		if len(a.Properties) != len(b.Properties) {
+			return false
+		}
+		for name, aProp := range a.Properties {
+			bProp, ok := b.Properties[name]
+			if !ok || aProp == nil || bProp == nil {
+				return false
+			}
+			if !fieldsCompatible(*aProp, *bProp) {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// validateSignatureField checks a single field's structural invariants,
+// independent of where it sits (input or output).
+func validateSignatureField(field Field, kind string) error {
+	if strings.TrimSpace(field.Name) == "" {
+		return fmt.Errorf("signature has an %s field with an empty name", kind)
+	}
+	if field.Type == FieldTypeArray && field.Items == nil {
+		return fmt.Errorf("signature %s field %q is type array but has no Items", kind, field.Name)
+	}
+	if field.Type == FieldTypeObject && field.Properties == nil {
+		return fmt.Errorf("signature %s field %q is type object but has no Properties", kind, field.Name)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// GoStructOptions configures GenerateGoStruct/WriteGoStruct.
+type GoStructOptions struct {
+	// PackageName names the generated file's package clause. Defaults to
+	// "generated" when empty.
+	PackageName string
+
+	// InputName and OutputName name the top-level generated structs for
+	// s.Inputs and s.Outputs. Default to "Input" and "Output".
+	InputName  string
+	OutputName string
+}
+
+// GenerateGoStruct renders s's inputs and outputs as gofmt-clean Go source
+// declaring a struct per side, with dspy tags matching each field's Name -
+// see WriteGoStruct for the full behavior. This bootstraps typed signature
+// usage (NewTypedSignature[TInput, TOutput]) from a Signature assembled
+// dynamically (e.g. parsed from a shorthand string) instead of hand-writing
+// the equivalent struct.
+func (s Signature) GenerateGoStruct(opts GoStructOptions) (string, error) {
+	var buf bytes.Buffer
+	if err := s.WriteGoStruct(&buf, opts); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// WriteGoStruct writes the same output as GenerateGoStruct to w. An object
+// field becomes its own named nested struct (named after the struct it's
+// declared on plus the field's name), and an array field becomes a Go
+// slice of the element type - recursing the same way into an array of
+// objects' element struct. Field and type names are sanitized into valid
+// Go identifiers; a name that collides with an already-generated type gets
+// a numeric suffix.
+func (s Signature) WriteGoStruct(w io.Writer, opts GoStructOptions) error {
+	if opts.PackageName == "" {
+		opts.PackageName = "generated"
+	}
+	if opts.InputName == "" {
+		opts.InputName = "Input"
+	}
+	if opts.OutputName == "" {
+		opts.OutputName = "Output"
+	}
+
+	gen := &goStructGenerator{usedNames: map[string]bool{}}
+	gen.enqueue(sanitizeGoIdentifier(opts.InputName, true), inputFields(s.Inputs))
+	gen.enqueue(sanitizeGoIdentifier(opts.OutputName, true), outputFields(s.Outputs))
+
+	var src strings.Builder
+	fmt.Fprintf(&src, "package %s\n\n", sanitizeGoIdentifier(opts.PackageName, false))
+	for i, decl := range gen.drain() {
+		if i > 0 {
+			src.WriteString("\n")
+		}
+		src.WriteString(decl)
+	}
+
+	formatted, err := format.Source([]byte(src.String()))
+	if err != nil {
+		return fmt.Errorf("failed to format generated Go source: %w", err)
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+// goStructGenerator renders a Signature's fields into Go struct
+// declarations breadth-first: each object/array-of-object field discovered
+// while rendering one struct queues its own nested struct, named after the
+// parent plus the field, so the output reads top-down from Input/Output
+// through their nested types.
+type goStructGenerator struct {
+	usedNames map[string]bool
+	pending   []pendingStruct
+}
+
+type pendingStruct struct {
+	name   string
+	fields []Field
+}
+
+// enqueue reserves a unique name for a struct and queues it for rendering,
+// returning the name actually assigned (which may differ from name if it
+// collided with one already in use).
+func (g *goStructGenerator) enqueue(name string, fields []Field) string {
+	name = g.uniqueName(name)
+	g.pending = append(g.pending, pendingStruct{name: name, fields: fields})
+	return name
+}
+
+func (g *goStructGenerator) uniqueName(name string) string {
+	candidate := name
+	for n := 2; g.usedNames[candidate]; n++ {
+		candidate = fmt.Sprintf("%s%d", name, n)
+	}
+	g.usedNames[candidate] = true
+	return candidate
+}
+
+// drain renders every queued struct, including ones queued while rendering
+// earlier structs' own fields, until none remain.
+func (g *goStructGenerator) drain() []string {
+	var decls []string
+	for len(g.pending) > 0 {
+		next := g.pending[0]
+		g.pending = g.pending[1:]
+		decls = append(decls, g.renderStruct(next.name, next.fields))
+	}
+	return decls
+}
+
+func (g *goStructGenerator) renderStruct(name string, fields []Field) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "type %s struct {\n", name)
+	fieldNames := map[string]bool{}
+	for _, f := range fields {
+		goName := uniqueFieldName(fieldNames, sanitizeGoIdentifier(f.Name, true))
+		fmt.Fprintf(&sb, "%s %s %s\n", goName, g.fieldGoType(name, goName, f), fieldTag(f))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// uniqueFieldName resolves a collision between two fields that sanitize to
+// the same Go identifier within a single struct - e.g. properties "address"
+// and "Address" - the same way goStructGenerator.uniqueName resolves
+// colliding type names, but scoped to names, not the generator's global
+// set, since field names only need to be unique within their own struct.
+func uniqueFieldName(used map[string]bool, name string) string {
+	candidate := name
+	for n := 2; used[candidate]; n++ {
+		candidate = fmt.Sprintf("%s%d", name, n)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// fieldGoType returns the Go type for f, queuing a nested struct
+// declaration - named structName+goName, or structName+goName+"Item" for
+// an array-of-objects element type - when f needs one.
+func (g *goStructGenerator) fieldGoType(structName, goName string, f Field) string {
+	switch f.Type {
+	case FieldTypeInt:
+		return "int"
+	case FieldTypeBool:
+		return "bool"
+	case FieldTypeObject:
+		return g.enqueue(structName+goName, sortedProperties(f.Properties))
+	case FieldTypeArray:
+		if f.Items == nil {
+			return "[]interface{}"
+		}
+		if f.Items.Type == FieldTypeObject {
+			return "[]" + g.enqueue(structName+goName+"Item", sortedProperties(f.Items.Properties))
+		}
+		return "[]" + g.fieldGoType(structName, goName, *f.Items)
+	default:
+		// FieldTypeText, FieldTypeString, FieldTypeImage, FieldTypeAudio all
+		// become a plain string - mirroring fieldToTypeSchema's treatment of
+		// the same four types in signature_openapi.go.
+		return "string"
+	}
+}
+
+// sortedProperties converts an object field's Properties map into a slice,
+// ordered alphabetically by name for deterministic output - Field.Properties
+// carries no declaration order of its own (see objectFieldToTypeSchema,
+// which sorts the same way for the same reason).
+func sortedProperties(props map[string]*Field) []Field {
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]Field, 0, len(names))
+	for _, name := range names {
+		if props[name] != nil {
+			fields = append(fields, *props[name])
+		}
+	}
+	return fields
+}
+
+// fieldTag renders f's struct tag: a dspy tag carrying f.Name plus
+// "required"/"transient" flags (see parseStructTag's `dspy:"name,required,
+// transient"` grammar), and description/prefix/sensitive tags alongside it
+// when set - so a struct generated here round-trips through
+// NewTypedSignature unchanged.
+func fieldTag(f Field) string {
+	dspy := f.Name
+	if !f.Optional {
+		dspy += ",required"
+	}
+	if f.Transient {
+		dspy += ",transient"
+	}
+
+	parts := []string{fmt.Sprintf(`dspy:"%s"`, dspy)}
+	if f.Description != "" {
+		parts = append(parts, fmt.Sprintf(`description:%q`, f.Description))
+	}
+	if f.Prefix != "" {
+		parts = append(parts, fmt.Sprintf(`prefix:%q`, f.Prefix))
+	}
+	if f.Sensitive {
+		parts = append(parts, `sensitive:"true"`)
+	}
+	return "`" + strings.Join(parts, " ") + "`"
+}
+
+// sanitizeGoIdentifier converts name into a valid Go identifier: runs of
+// non-letter/non-digit characters become word boundaries, the first rune
+// of each word is upper-cased when exported is true (Pascal case, for an
+// exported struct field or type name) and lower-cased when false (for a
+// package clause name), and the rest of each word's original casing is
+// kept for exported identifiers - so an already-camelCased Name like
+// "userID" sanitizes to "UserID" rather than losing its embedded acronym -
+// and lower-cased for unexported ones, matching Go's all-lowercase package
+// naming convention. A result that collides with a Go keyword - "type"
+// used as a signature or field name, say - gets a trailing underscore,
+// since go/format would otherwise reject it as a syntax error.
+func sanitizeGoIdentifier(name string, exported bool) string {
+	var sb strings.Builder
+	upperNext := exported
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = exported
+			continue
+		}
+		switch {
+		case upperNext:
+			sb.WriteRune(unicode.ToUpper(r))
+		case exported:
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune(unicode.ToLower(r))
+		}
+		upperNext = false
+	}
+
+	id := sb.String()
+	if id == "" {
+		id = "Field"
+	}
+	if unicode.IsDigit(rune(id[0])) {
+		id = "_" + id
+	}
+	if token.IsKeyword(id) {
+		id += "_"
+	}
+	return id
+}
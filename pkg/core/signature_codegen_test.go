@@ -0,0 +1,167 @@
+package core
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// squeezeSpace collapses the column-alignment padding gofmt inserts between
+// struct field names, types, and tags, so assertions can match a field's
+// components without depending on how wide its neighbors are.
+var squeezeSpaceRE = regexp.MustCompile(`[ \t]+`)
+
+func squeezeSpace(s string) string {
+	return squeezeSpaceRE.ReplaceAllString(s, " ")
+}
+
+func TestSignatureGenerateGoStructBasicFields(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "question", Type: FieldTypeString}}},
+		[]OutputField{
+			{Field: Field{Name: "answer", Type: FieldTypeString}},
+			{Field: Field{Name: "confidence", Type: FieldTypeInt, Optional: true}},
+		},
+	)
+
+	src, err := sig.GenerateGoStruct(GoStructOptions{})
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	_, err = parser.ParseFile(fset, "generated.go", src, 0)
+	require.NoError(t, err, "generated source must parse as valid Go:\n%s", src)
+
+	flat := squeezeSpace(src)
+	assert.Contains(t, flat, "type Input struct")
+	assert.Contains(t, flat, "type Output struct")
+	assert.Contains(t, flat, `Question string `+"`"+`dspy:"question,required"`+"`")
+	assert.Contains(t, flat, `Answer string `+"`"+`dspy:"answer,required"`+"`")
+	assert.Contains(t, flat, `Confidence int `+"`"+`dspy:"confidence"`+"`")
+}
+
+func TestSignatureGenerateGoStructNestedObjectAndArray(t *testing.T) {
+	sig := NewSignature(
+		nil,
+		[]OutputField{
+			{Field: Field{
+				Name: "source",
+				Type: FieldTypeObject,
+				Properties: map[string]*Field{
+					"url": {Name: "url", Type: FieldTypeString},
+				},
+			}},
+			{Field: Field{
+				Name:  "tags",
+				Type:  FieldTypeArray,
+				Items: &Field{Type: FieldTypeString},
+			}},
+			{Field: Field{
+				Name: "items",
+				Type: FieldTypeArray,
+				Items: &Field{
+					Type: FieldTypeObject,
+					Properties: map[string]*Field{
+						"name": {Name: "name", Type: FieldTypeString},
+					},
+				},
+			}},
+		},
+	)
+
+	src, err := sig.GenerateGoStruct(GoStructOptions{})
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	_, err = parser.ParseFile(fset, "generated.go", src, 0)
+	require.NoError(t, err, "generated source must parse as valid Go:\n%s", src)
+
+	flat := squeezeSpace(src)
+	assert.Contains(t, flat, "Source OutputSource")
+	assert.Contains(t, flat, "type OutputSource struct")
+	assert.Contains(t, flat, "Tags []string")
+	assert.Contains(t, flat, "Items []OutputItemsItem")
+	assert.Contains(t, flat, "type OutputItemsItem struct")
+}
+
+func TestSignatureGenerateGoStructSanitizesFieldNames(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{
+			{Field: Field{Name: "user-name", Type: FieldTypeString}},
+			{Field: Field{Name: "type", Type: FieldTypeString}},
+		},
+		nil,
+	)
+
+	src, err := sig.GenerateGoStruct(GoStructOptions{})
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	_, err = parser.ParseFile(fset, "generated.go", src, 0)
+	require.NoError(t, err, "generated source must parse as valid Go:\n%s", src)
+
+	flat := squeezeSpace(src)
+	assert.Contains(t, flat, "UserName string")
+	assert.Contains(t, flat, `dspy:"user-name,required"`)
+	assert.Contains(t, flat, "Type string")
+	assert.Contains(t, flat, `dspy:"type,required"`)
+}
+
+// TestSignatureGenerateGoStructSanitizesReservedPackageName confirms a
+// package name that happens to be a Go keyword - plausible when it's
+// derived from a signature's own name - gets a trailing underscore instead
+// of producing unparseable source.
+func TestSignatureGenerateGoStructSanitizesReservedPackageName(t *testing.T) {
+	sig := NewSignature([]InputField{{Field: Field{Name: "question", Type: FieldTypeString}}}, nil)
+
+	src, err := sig.GenerateGoStruct(GoStructOptions{PackageName: "type"})
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	_, err = parser.ParseFile(fset, "generated.go", src, 0)
+	require.NoError(t, err, "generated source must parse as valid Go:\n%s", src)
+
+	assert.True(t, strings.HasPrefix(src, "package type_\n"))
+}
+
+// TestSignatureGenerateGoStructDedupesCollidingNestedTypeNames confirms two
+// object fields that sanitize to the same Go identifier (differing only in
+// case) don't produce two conflicting "type OutputAddress struct"
+// declarations.
+func TestSignatureGenerateGoStructDedupesCollidingNestedTypeNames(t *testing.T) {
+	sig := NewSignature(
+		nil,
+		[]OutputField{
+			{Field: Field{Name: "address", Type: FieldTypeObject, Properties: map[string]*Field{
+				"city": {Name: "city", Type: FieldTypeString},
+			}}},
+			{Field: Field{Name: "Address", Type: FieldTypeObject, Properties: map[string]*Field{
+				"city": {Name: "city", Type: FieldTypeString},
+			}}},
+		},
+	)
+
+	src, err := sig.GenerateGoStruct(GoStructOptions{})
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	_, err = parser.ParseFile(fset, "generated.go", src, 0)
+	require.NoError(t, err, "generated source must parse as valid Go:\n%s", src)
+
+	assert.Contains(t, src, "type OutputAddress struct")
+	assert.Contains(t, src, "type OutputAddress2 struct")
+}
+
+func TestSignatureWriteGoStructWritesToWriter(t *testing.T) {
+	sig := NewSignature([]InputField{{Field: Field{Name: "question", Type: FieldTypeString}}}, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, sig.WriteGoStruct(&buf, GoStructOptions{PackageName: "my-signature"}))
+
+	assert.True(t, strings.HasPrefix(buf.String(), "package mysignature\n"))
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"sort"
+
+	"github.com/darwishdev/dspy-go/pkg/utils"
+)
+
+// OpenAPIComponents converts s's inputs and outputs into OpenAPI 3.1 schema
+// objects - OpenAPI 3.1 adopted JSON Schema directly, so this builds a
+// utils.TypeSchema for each side and reuses TypeSchema.ToJSONSchema rather
+// than maintaining a separate exporter. The result is keyed name+"Request"
+// and name+"Response", ready to drop under an OpenAPI document's
+// components.schemas section.
+func (s Signature) OpenAPIComponents(name string) map[string]interface{} {
+	return map[string]interface{}{
+		name + "Request":  fieldsToTypeSchema(inputFields(s.Inputs)).ToJSONSchema(),
+		name + "Response": fieldsToTypeSchema(outputFields(s.Outputs)).ToJSONSchema(),
+	}
+}
+
+func inputFields(inputs []InputField) []Field {
+	fields := make([]Field, len(inputs))
+	for i, f := range inputs {
+		fields[i] = f.Field
+	}
+	return fields
+}
+
+func outputFields(outputs []OutputField) []Field {
+	fields := make([]Field, len(outputs))
+	for i, f := range outputs {
+		fields[i] = f.Field
+	}
+	return fields
+}
+
+// fieldsToTypeSchema builds an OBJECT TypeSchema whose properties are
+// fields. Every field is marked required unless it is Optional (see
+// Field.Optional).
+func fieldsToTypeSchema(fields []Field) *utils.TypeSchema {
+	schema := &utils.TypeSchema{
+		Type:       string(utils.TypeObject),
+		Properties: make(map[string]*utils.TypeSchema, len(fields)),
+	}
+
+	for _, f := range fields {
+		schema.Properties[f.Name] = fieldToTypeSchema(f)
+		schema.PropertyOrdering = append(schema.PropertyOrdering, f.Name)
+		if !f.Optional {
+			schema.Required = append(schema.Required, f.Name)
+		}
+	}
+
+	return schema
+}
+
+func fieldToTypeSchema(f Field) *utils.TypeSchema {
+	switch f.Type {
+	case FieldTypeInt:
+		return &utils.TypeSchema{Type: string(utils.TypeInteger), Description: f.Description}
+	case FieldTypeBool:
+		return &utils.TypeSchema{Type: string(utils.TypeBoolean), Description: f.Description}
+	case FieldTypeArray:
+		var items *utils.TypeSchema
+		if f.Items != nil {
+			items = fieldToTypeSchema(*f.Items)
+		}
+		return &utils.TypeSchema{Type: string(utils.TypeArray), Items: items, Description: f.Description}
+	case FieldTypeObject:
+		return objectFieldToTypeSchema(f)
+	default:
+		// FieldTypeText, FieldTypeString, FieldTypeImage, FieldTypeAudio all
+		// surface as a plain STRING in the generated schema - none of them
+		// have a richer OpenAPI representation worth modeling here.
+		return &utils.TypeSchema{Type: string(utils.TypeString), Description: f.Description}
+	}
+}
+
+func objectFieldToTypeSchema(f Field) *utils.TypeSchema {
+	schema := &utils.TypeSchema{
+		Type:        string(utils.TypeObject),
+		Description: f.Description,
+		Properties:  make(map[string]*utils.TypeSchema, len(f.Properties)),
+	}
+
+	// Field.Properties is a map with no declared ordering, so property names
+	// are sorted for a deterministic, diffable schema.
+	names := make([]string, 0, len(f.Properties))
+	for name := range f.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop := f.Properties[name]
+		if prop == nil {
+			continue
+		}
+		schema.Properties[name] = fieldToTypeSchema(*prop)
+		schema.PropertyOrdering = append(schema.PropertyOrdering, name)
+		schema.Required = append(schema.Required, name)
+	}
+
+	return schema
+}
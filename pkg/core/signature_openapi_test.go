@@ -0,0 +1,94 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureOpenAPIComponents(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "question", Type: FieldTypeString}}},
+		[]OutputField{
+			{Field: Field{Name: "answer", Type: FieldTypeString}},
+			{Field: Field{Name: "confidence", Type: FieldTypeInt}},
+			{Field: Field{
+				Name:  "tags",
+				Type:  FieldTypeArray,
+				Items: &Field{Type: FieldTypeString},
+			}},
+			{Field: Field{
+				Name: "source",
+				Type: FieldTypeObject,
+				Properties: map[string]*Field{
+					"url": {Type: FieldTypeString},
+				},
+			}},
+		},
+	)
+
+	components := sig.OpenAPIComponents("Answer")
+
+	request, ok := components["AnswerRequest"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "object", request["type"])
+	requestProps, ok := request["properties"].(map[string]interface{})
+	require.True(t, ok)
+	questionSchema, ok := requestProps["question"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "string", questionSchema["type"])
+	assert.ElementsMatch(t, []interface{}{"question"}, request["required"])
+
+	response, ok := components["AnswerResponse"].(map[string]interface{})
+	require.True(t, ok)
+	responseProps, ok := response["properties"].(map[string]interface{})
+	require.True(t, ok)
+
+	confidenceSchema, ok := responseProps["confidence"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "integer", confidenceSchema["type"])
+
+	tagsSchema, ok := responseProps["tags"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "array", tagsSchema["type"])
+	itemsSchema, ok := tagsSchema["items"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "string", itemsSchema["type"])
+
+	sourceSchema, ok := responseProps["source"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "object", sourceSchema["type"])
+	sourceProps, ok := sourceSchema["properties"].(map[string]interface{})
+	require.True(t, ok)
+	urlSchema, ok := sourceProps["url"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "string", urlSchema["type"])
+
+	assert.ElementsMatch(t, []interface{}{"answer", "confidence", "tags", "source"}, response["required"])
+}
+
+// TestSignatureOpenAPIComponentsKeysOnNameNotPrefix confirms that a field's
+// Prefix (the text-parsing anchor formatPrompt/parseCompletion use, see
+// Field.Prefix) plays no part in the generated schema: the JSON property
+// key is always the field's plain Name, even when Prefix diverges from it.
+func TestSignatureOpenAPIComponentsKeysOnNameNotPrefix(t *testing.T) {
+	sig := NewSignature(
+		nil,
+		[]OutputField{
+			{Field: Field{Name: "answer", Type: FieldTypeString, Prefix: "Final Answer:"}},
+		},
+	)
+
+	components := sig.OpenAPIComponents("Answer")
+
+	response, ok := components["AnswerResponse"].(map[string]interface{})
+	require.True(t, ok)
+	responseProps, ok := response["properties"].(map[string]interface{})
+	require.True(t, ok)
+
+	_, ok = responseProps["answer"]
+	assert.True(t, ok, "expected property key to be the plain field name")
+	_, ok = responseProps["Final Answer:"]
+	assert.False(t, ok, "prefix must not leak into the JSON schema's property key")
+}
@@ -0,0 +1,214 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderPromptOptions configures RenderPrompt's section headers. See
+// WithInstructionHeader/WithInputsHeader/WithOutputsHeader.
+type RenderPromptOptions struct {
+	// InstructionHeader precedes s.Instruction. Empty (the default) omits
+	// a header line, since the instruction text alone is often enough.
+	InstructionHeader string
+	// InputsHeader precedes the rendered input fields. Defaults to "Inputs:".
+	InputsHeader string
+	// OutputsHeader precedes the blank output fields. Defaults to "Outputs:".
+	OutputsHeader string
+}
+
+// RenderPromptOption allows for optional parameters to RenderPrompt.
+type RenderPromptOption func(*RenderPromptOptions)
+
+// WithInstructionHeader sets the header line printed before the
+// signature's instruction. See RenderPromptOptions.InstructionHeader.
+func WithInstructionHeader(header string) RenderPromptOption {
+	return func(o *RenderPromptOptions) {
+		o.InstructionHeader = header
+	}
+}
+
+// WithInputsHeader overrides the default "Inputs:" section header.
+func WithInputsHeader(header string) RenderPromptOption {
+	return func(o *RenderPromptOptions) {
+		o.InputsHeader = header
+	}
+}
+
+// WithOutputsHeader overrides the default "Outputs:" section header.
+func WithOutputsHeader(header string) RenderPromptOption {
+	return func(o *RenderPromptOptions) {
+		o.OutputsHeader = header
+	}
+}
+
+func newRenderPromptOptions(opts []RenderPromptOption) RenderPromptOptions {
+	o := RenderPromptOptions{
+		InputsHeader:  "Inputs:",
+		OutputsHeader: "Outputs:",
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// RenderPrompt renders s and input into a complete text prompt: an
+// instruction section, s's input fields with their prefixes and values
+// from input, and a blank output section listing each output field's
+// prefix for the model to fill in after it. It's the package's general-
+// purpose text-prompt builder - pkg/modules' Predict has its own
+// formatPrompt geared toward its demos/prefix-style/type-hint options, but
+// a caller outside that module has had no equivalent until now.
+//
+// input's keys are validated against s.Inputs before rendering: an unknown
+// key, or a missing key for a field that isn't Optional, is returned as an
+// error rather than silently rendering an empty value.
+func (s Signature) RenderPrompt(input map[string]any, opts ...RenderPromptOption) (string, error) {
+	if err := s.validateRenderPromptInput(input); err != nil {
+		return "", err
+	}
+	o := newRenderPromptOptions(opts)
+
+	var sb strings.Builder
+
+	if s.Instruction != "" {
+		if o.InstructionHeader != "" {
+			sb.WriteString(o.InstructionHeader)
+			sb.WriteString("\n")
+		}
+		sb.WriteString(s.Instruction)
+		sb.WriteString("\n\n")
+	}
+
+	if o.InputsHeader != "" {
+		sb.WriteString(o.InputsHeader)
+		sb.WriteString("\n")
+	}
+	for _, f := range s.Inputs {
+		renderPromptField(&sb, f.Field, input[f.Name], "")
+	}
+	sb.WriteString("\n")
+
+	if o.OutputsHeader != "" {
+		sb.WriteString(o.OutputsHeader)
+		sb.WriteString("\n")
+	}
+	for _, f := range s.Outputs {
+		sb.WriteString(fieldPromptLabel(f.Field))
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), nil
+}
+
+// validateRenderPromptInput checks input's keys against s.Inputs: every key
+// must name a declared input field, and every input field that isn't
+// Optional must have a key present (even if its value is the zero value -
+// this only checks presence, not "is it a meaningful value").
+func (s Signature) validateRenderPromptInput(input map[string]any) error {
+	known := make(map[string]bool, len(s.Inputs))
+	for _, f := range s.Inputs {
+		known[f.Name] = true
+	}
+	for key := range input {
+		if !known[key] {
+			return fmt.Errorf("signature: RenderPrompt received unknown input field %q", key)
+		}
+	}
+	for _, f := range s.Inputs {
+		if f.Optional {
+			continue
+		}
+		if _, ok := input[f.Name]; !ok {
+			return fmt.Errorf("signature: RenderPrompt is missing required input field %q", f.Name)
+		}
+	}
+	return nil
+}
+
+// fieldPromptLabel returns field's prefix, falling back to its name plus a
+// colon when it has none - the same default NewField assigns, applied here
+// too for a Field built directly as a struct literal.
+func fieldPromptLabel(field Field) string {
+	if field.Prefix != "" {
+		return field.Prefix
+	}
+	return field.Name + ":"
+}
+
+// renderPromptField writes field's label followed by value, indented by
+// indent. An object value recurses into its properties and an array value
+// lists its elements, both indented one level further, so a nested input
+// reads as a small outline instead of a single %v-formatted blob; every
+// other field type is rendered as a single "label value" line.
+func renderPromptField(sb *strings.Builder, field Field, value any, indent string) {
+	label := fieldPromptLabel(field)
+
+	switch field.Type {
+	case FieldTypeObject:
+		sb.WriteString(indent + label + "\n")
+		renderPromptObjectValue(sb, field, value, indent+"  ")
+	case FieldTypeArray:
+		sb.WriteString(indent + label + "\n")
+		renderPromptArrayValue(sb, field, value, indent+"  ")
+	default:
+		fmt.Fprintf(sb, "%s%s %s\n", indent, label, formatPromptScalarValue(value))
+	}
+}
+
+// renderPromptObjectValue renders value's properties, in field.Properties'
+// alphabetical order (see sortedProperties), recursing through
+// renderPromptField so a property that's itself an object or array nests
+// correctly. A value that isn't a map[string]any - e.g. nil, or a caller
+// that passed a concrete struct instead of a map - is rendered as a single
+// line with %v rather than silently producing an empty section.
+func renderPromptObjectValue(sb *strings.Builder, field Field, value any, indent string) {
+	m, ok := value.(map[string]any)
+	if !ok {
+		if value != nil {
+			fmt.Fprintf(sb, "%s%v\n", indent, value)
+		}
+		return
+	}
+	for _, prop := range sortedProperties(field.Properties) {
+		renderPromptField(sb, prop, m[prop.Name], indent)
+	}
+}
+
+// renderPromptArrayValue renders each of value's elements as its own "- "
+// line - recursing into renderPromptObjectValue for an array of objects, so
+// each element reads as its own small outline, and falling back to %v for
+// a scalar element. A value that isn't a []any is rendered as a single
+// line with %v, same as renderPromptObjectValue's fallback.
+func renderPromptArrayValue(sb *strings.Builder, field Field, value any, indent string) {
+	items, ok := value.([]any)
+	if !ok {
+		if value != nil {
+			fmt.Fprintf(sb, "%s%v\n", indent, value)
+		}
+		return
+	}
+	for i, item := range items {
+		if field.Items != nil && field.Items.Type == FieldTypeObject {
+			fmt.Fprintf(sb, "%s- [%d]\n", indent, i)
+			renderPromptObjectValue(sb, *field.Items, item, indent+"  ")
+			continue
+		}
+		fmt.Fprintf(sb, "%s- %v\n", indent, item)
+	}
+}
+
+// formatPromptScalarValue stringifies a single field value: a
+// ContentBlock's own String() form (matching Predict's formatPrompt, which
+// does the same for an image/audio input), nil as an empty string rather
+// than Go's literal "<nil>", and everything else via %v.
+func formatPromptScalarValue(value any) string {
+	if block, ok := value.(ContentBlock); ok {
+		return block.String()
+	}
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
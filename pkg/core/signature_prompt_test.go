@@ -0,0 +1,155 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureRenderPromptBasic(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "question", Type: FieldTypeString, Prefix: "Question:"}}},
+		[]OutputField{{Field: Field{Name: "answer", Type: FieldTypeString, Prefix: "Answer:"}}},
+	)
+	sig.Instruction = "Answer the question."
+
+	prompt, err := sig.RenderPrompt(map[string]any{"question": "What is the capital of France?"})
+	require.NoError(t, err)
+
+	assert.Contains(t, prompt, "Answer the question.")
+	assert.Contains(t, prompt, "Question: What is the capital of France?")
+	assert.Contains(t, prompt, "Answer:")
+	assert.Contains(t, prompt, "Inputs:")
+	assert.Contains(t, prompt, "Outputs:")
+}
+
+func TestSignatureRenderPromptUnknownInputFieldErrors(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "question", Type: FieldTypeString}}},
+		[]OutputField{{Field: Field{Name: "answer", Type: FieldTypeString}}},
+	)
+
+	_, err := sig.RenderPrompt(map[string]any{"question": "hi", "extra": "oops"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "extra")
+}
+
+func TestSignatureRenderPromptMissingRequiredInputErrors(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "question", Type: FieldTypeString}}},
+		[]OutputField{{Field: Field{Name: "answer", Type: FieldTypeString}}},
+	)
+
+	_, err := sig.RenderPrompt(map[string]any{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "question")
+}
+
+func TestSignatureRenderPromptMissingOptionalInputPasses(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "question", Type: FieldTypeString}}, {Field: Field{Name: "hint", Type: FieldTypeString, Optional: true}}},
+		[]OutputField{{Field: Field{Name: "answer", Type: FieldTypeString}}},
+	)
+
+	_, err := sig.RenderPrompt(map[string]any{"question": "hi"})
+	assert.NoError(t, err)
+}
+
+func TestSignatureRenderPromptNestedObjectInput(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{
+			{Field: Field{
+				Name: "context",
+				Type: FieldTypeObject,
+				Properties: map[string]*Field{
+					"title": {Name: "title", Type: FieldTypeString},
+					"body":  {Name: "body", Type: FieldTypeString},
+				},
+			}},
+		},
+		[]OutputField{{Field: Field{Name: "answer", Type: FieldTypeString}}},
+	)
+
+	prompt, err := sig.RenderPrompt(map[string]any{
+		"context": map[string]any{
+			"title": "Background",
+			"body":  "Some long body text.",
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, prompt, "context:")
+	assert.Contains(t, prompt, "  body: Some long body text.")
+	assert.Contains(t, prompt, "  title: Background")
+}
+
+func TestSignatureRenderPromptNestedArrayOfObjectsInput(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{
+			{Field: Field{
+				Name: "documents",
+				Type: FieldTypeArray,
+				Items: &Field{
+					Type: FieldTypeObject,
+					Properties: map[string]*Field{
+						"id":   {Name: "id", Type: FieldTypeString},
+						"text": {Name: "text", Type: FieldTypeString},
+					},
+				},
+			}},
+		},
+		[]OutputField{{Field: Field{Name: "answer", Type: FieldTypeString}}},
+	)
+
+	prompt, err := sig.RenderPrompt(map[string]any{
+		"documents": []any{
+			map[string]any{"id": "1", "text": "first"},
+			map[string]any{"id": "2", "text": "second"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, prompt, "documents:")
+	assert.Contains(t, prompt, "  - [0]")
+	assert.Contains(t, prompt, "    id: 1")
+	assert.Contains(t, prompt, "    text: first")
+	assert.Contains(t, prompt, "  - [1]")
+	assert.Contains(t, prompt, "    text: second")
+}
+
+func TestSignatureRenderPromptNestedArrayOfScalarsInput(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{
+			{Field: Field{Name: "tags", Type: FieldTypeArray, Items: &Field{Type: FieldTypeString}}},
+		},
+		[]OutputField{{Field: Field{Name: "answer", Type: FieldTypeString}}},
+	)
+
+	prompt, err := sig.RenderPrompt(map[string]any{"tags": []any{"a", "b"}})
+	require.NoError(t, err)
+
+	assert.Contains(t, prompt, "tags:")
+	assert.Contains(t, prompt, "  - a")
+	assert.Contains(t, prompt, "  - b")
+}
+
+func TestSignatureRenderPromptConfigurableHeaders(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "question", Type: FieldTypeString}}},
+		[]OutputField{{Field: Field{Name: "answer", Type: FieldTypeString}}},
+	)
+	sig.Instruction = "Answer it."
+
+	prompt, err := sig.RenderPrompt(
+		map[string]any{"question": "hi"},
+		WithInstructionHeader("### Instruction"),
+		WithInputsHeader("### Input"),
+		WithOutputsHeader("### Output"),
+	)
+	require.NoError(t, err)
+
+	assert.Contains(t, prompt, "### Instruction")
+	assert.Contains(t, prompt, "### Input")
+	assert.Contains(t, prompt, "### Output")
+}
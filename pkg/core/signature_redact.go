@@ -0,0 +1,34 @@
+package core
+
+const redactedValue = "[REDACTED]"
+
+// RedactSensitiveInputs returns a copy of inputs with the values of any
+// field marked Sensitive (in either s.Inputs or s.Outputs) replaced by a
+// fixed redaction marker. It's meant for logging/tracing call sites that
+// render a prompt or its inputs for observability: the request actually
+// sent to the model should keep using the original, unredacted inputs.
+// inputs itself is not modified.
+func RedactSensitiveInputs(s Signature, inputs map[string]interface{}) map[string]interface{} {
+	sensitive := make(map[string]bool)
+	for _, f := range s.Inputs {
+		if f.Sensitive {
+			sensitive[f.Name] = true
+		}
+	}
+	for _, f := range s.Outputs {
+		if f.Sensitive {
+			sensitive[f.Name] = true
+		}
+	}
+
+	redacted := make(map[string]interface{}, len(inputs))
+	for k, v := range inputs {
+		if sensitive[k] {
+			redacted[k] = redactedValue
+			continue
+		}
+		redacted[k] = v
+	}
+
+	return redacted
+}
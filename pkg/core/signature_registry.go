@@ -0,0 +1,62 @@
+package core
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/darwishdev/dspy-go/pkg/errors"
+)
+
+// SignatureRegistry provides thread-safe, named lookup of Signatures, so
+// modules can be wired up from a name (e.g. read from config) instead of a
+// compile-time reference.
+type SignatureRegistry struct {
+	mu         sync.RWMutex
+	signatures map[string]Signature
+}
+
+// NewSignatureRegistry creates a new, empty SignatureRegistry.
+func NewSignatureRegistry() *SignatureRegistry {
+	return &SignatureRegistry{
+		signatures: make(map[string]Signature),
+	}
+}
+
+// Register adds sig to the registry under name. Registering the same name
+// twice is not an error - the later call wins, consistent with
+// DefaultLLMRegistry.RegisterProvider - since callers commonly re-register a
+// signature to apply an update.
+func (r *SignatureRegistry) Register(name string, sig Signature) error {
+	if name == "" {
+		return errors.New(errors.InvalidInput, "signature name cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.signatures[name] = sig
+	return nil
+}
+
+// Get returns the Signature registered under name, and whether it was found.
+func (r *SignatureRegistry) Get(name string) (Signature, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sig, ok := r.signatures[name]
+	return sig, ok
+}
+
+// List returns the names of all registered signatures, sorted
+// alphabetically.
+func (r *SignatureRegistry) List() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.signatures))
+	for name := range r.signatures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
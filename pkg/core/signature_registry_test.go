@@ -0,0 +1,84 @@
+package core
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureRegistry_RegisterAndGet(t *testing.T) {
+	registry := NewSignatureRegistry()
+	sig, err := ParseSignature("question -> answer")
+	require.NoError(t, err)
+
+	require.NoError(t, registry.Register("qa", sig))
+
+	got, ok := registry.Get("qa")
+	assert.True(t, ok)
+	assert.Equal(t, sig, got)
+}
+
+func TestSignatureRegistry_GetMissing(t *testing.T) {
+	registry := NewSignatureRegistry()
+
+	_, ok := registry.Get("missing")
+	assert.False(t, ok)
+}
+
+func TestSignatureRegistry_RegisterEmptyNameFails(t *testing.T) {
+	registry := NewSignatureRegistry()
+	sig, err := ParseSignature("question -> answer")
+	require.NoError(t, err)
+
+	err = registry.Register("", sig)
+	assert.Error(t, err)
+}
+
+func TestSignatureRegistry_RegisterLastWins(t *testing.T) {
+	registry := NewSignatureRegistry()
+	first, err := ParseSignature("question -> answer")
+	require.NoError(t, err)
+	second, err := ParseSignature("question, context -> answer")
+	require.NoError(t, err)
+
+	require.NoError(t, registry.Register("qa", first))
+	require.NoError(t, registry.Register("qa", second))
+
+	got, ok := registry.Get("qa")
+	assert.True(t, ok)
+	assert.Equal(t, second, got)
+}
+
+func TestSignatureRegistry_List(t *testing.T) {
+	registry := NewSignatureRegistry()
+	sig, err := ParseSignature("question -> answer")
+	require.NoError(t, err)
+
+	require.NoError(t, registry.Register("zeta", sig))
+	require.NoError(t, registry.Register("alpha", sig))
+
+	assert.Equal(t, []string{"alpha", "zeta"}, registry.List())
+}
+
+func TestSignatureRegistry_ConcurrentAccess(t *testing.T) {
+	registry := NewSignatureRegistry()
+	sig, err := ParseSignature("question -> answer")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = registry.Register("sig", sig)
+			registry.Get("sig")
+			registry.List()
+		}(i)
+	}
+	wg.Wait()
+
+	_, ok := registry.Get("sig")
+	assert.True(t, ok)
+}
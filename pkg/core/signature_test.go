@@ -1,10 +1,13 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestField(t *testing.T) {
@@ -103,6 +106,37 @@ func TestSignatureParser(t *testing.T) {
 		assert.Equal(t, "input1", sig.Inputs[0].Name)
 		assert.Equal(t, "output1", sig.Outputs[0].Name)
 	})
+
+	t.Run("ParseSignature collapses trailing commas", func(t *testing.T) {
+		sig, err := ParseSignature("input1, input2, -> output1,")
+		assert.NoError(t, err)
+		assert.Len(t, sig.Inputs, 2)
+		assert.Len(t, sig.Outputs, 1)
+	})
+
+	t.Run("ParseSignature rejects an empty input side", func(t *testing.T) {
+		_, err := ParseSignature(" -> output1")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no input fields")
+	})
+
+	t.Run("ParseSignature rejects an empty output side", func(t *testing.T) {
+		_, err := ParseSignature("input1 -> ")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no output fields")
+	})
+
+	t.Run("ParseSignature rejects an input side that's only commas", func(t *testing.T) {
+		_, err := ParseSignature(" , , -> output1")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no input fields")
+	})
+
+	t.Run("ParseSignature rejects more than one arrow", func(t *testing.T) {
+		_, err := ParseSignature("a -> b -> c")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "expected exactly one")
+	})
 }
 
 func TestSignatureAppendInput(t *testing.T) {
@@ -253,9 +287,9 @@ func TestSignatureChaining(t *testing.T) {
 
 		// Verify outputs (prepended in reverse order)
 		assert.Len(t, newSig.Outputs, 3)
-		assert.Equal(t, "rationale", newSig.Outputs[0].Name)   // Last prepended
-		assert.Equal(t, "output0", newSig.Outputs[1].Name)    // First prepended
-		assert.Equal(t, "output1", newSig.Outputs[2].Name)    // Original
+		assert.Equal(t, "rationale", newSig.Outputs[0].Name) // Last prepended
+		assert.Equal(t, "output0", newSig.Outputs[1].Name)   // First prepended
+		assert.Equal(t, "output1", newSig.Outputs[2].Name)   // Original
 
 		// Verify instruction is preserved
 		assert.Equal(t, "Original instruction", newSig.Instruction)
@@ -346,6 +380,79 @@ func TestSignatureImmutability(t *testing.T) {
 	})
 }
 
+// shorthandOf reconstructs the minimal "in1, in2 -> out1, out2" notation
+// ParseSignature expects from sig's field names. Signature.String() is a
+// verbose debug format, not shorthand notation, so it isn't a valid
+// ParseSignature input - this is what FuzzParseSignature round-trips
+// through instead.
+func shorthandOf(sig Signature) string {
+	inputNames := make([]string, len(sig.Inputs))
+	for i, input := range sig.Inputs {
+		inputNames[i] = input.Name
+	}
+	outputNames := make([]string, len(sig.Outputs))
+	for i, output := range sig.Outputs {
+		outputNames[i] = output.Name
+	}
+	return strings.Join(inputNames, ", ") + " -> " + strings.Join(outputNames, ", ")
+}
+
+func FuzzParseSignature(f *testing.F) {
+	seeds := []string{
+		"question -> answer",
+		"a, b -> c",
+		"",
+		"->",
+		"a ->",
+		"-> b",
+		"a, , b -> c,",
+		"a -> b -> c",
+		"a -> b,,,",
+		",,, -> b",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, signatureStr string) {
+		sig, err := ParseSignature(signatureStr)
+		if err != nil {
+			return
+		}
+
+		for _, input := range sig.Inputs {
+			if strings.TrimSpace(input.Name) == "" {
+				t.Fatalf("ParseSignature(%q) produced an empty input field name", signatureStr)
+			}
+		}
+		for _, output := range sig.Outputs {
+			if strings.TrimSpace(output.Name) == "" {
+				t.Fatalf("ParseSignature(%q) produced an empty output field name", signatureStr)
+			}
+		}
+
+		reparsed, err := ParseSignature(shorthandOf(sig))
+		if err != nil {
+			t.Fatalf("re-parsing %q (shorthand of %q) failed: %v", shorthandOf(sig), signatureStr, err)
+		}
+
+		if len(reparsed.Inputs) != len(sig.Inputs) || len(reparsed.Outputs) != len(sig.Outputs) {
+			t.Fatalf("round-trip field count mismatch for %q: got %d inputs/%d outputs, want %d/%d",
+				signatureStr, len(reparsed.Inputs), len(reparsed.Outputs), len(sig.Inputs), len(sig.Outputs))
+		}
+		for i, input := range sig.Inputs {
+			if reparsed.Inputs[i].Name != input.Name {
+				t.Fatalf("round-trip input name mismatch for %q: got %q, want %q", signatureStr, reparsed.Inputs[i].Name, input.Name)
+			}
+		}
+		for i, output := range sig.Outputs {
+			if reparsed.Outputs[i].Name != output.Name {
+				t.Fatalf("round-trip output name mismatch for %q: got %q, want %q", signatureStr, reparsed.Outputs[i].Name, output.Name)
+			}
+		}
+	})
+}
+
 func TestHelperFunctions(t *testing.T) {
 	t.Run("parseInputFields", func(t *testing.T) {
 		fields := parseInputFields("field1, field2")
@@ -361,3 +468,365 @@ func TestHelperFunctions(t *testing.T) {
 		assert.Equal(t, "field2", fields[1].Name)
 	})
 }
+
+func TestSignatureValidate(t *testing.T) {
+	t.Run("valid signature passes", func(t *testing.T) {
+		sig := NewSignature(
+			[]InputField{{Field: Field{Name: "question"}}},
+			[]OutputField{{Field: NewField("answer")}},
+		)
+		assert.NoError(t, sig.Validate())
+	})
+
+	t.Run("empty field name fails", func(t *testing.T) {
+		sig := NewSignature(
+			[]InputField{{Field: Field{Name: ""}}},
+			[]OutputField{{Field: NewField("answer")}},
+		)
+		err := sig.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "empty name")
+	})
+
+	t.Run("duplicate input names fail", func(t *testing.T) {
+		sig := NewSignature(
+			[]InputField{
+				{Field: Field{Name: "question"}},
+				{Field: Field{Name: "question"}},
+			},
+			[]OutputField{{Field: NewField("answer")}},
+		)
+		err := sig.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate input field name")
+	})
+
+	t.Run("duplicate output names fail", func(t *testing.T) {
+		sig := NewSignature(
+			[]InputField{{Field: Field{Name: "question"}}},
+			[]OutputField{
+				{Field: NewField("answer")},
+				{Field: NewField("answer")},
+			},
+		)
+		err := sig.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate output field name")
+	})
+
+	t.Run("array field without Items fails", func(t *testing.T) {
+		sig := NewSignature(
+			[]InputField{{Field: Field{Name: "question"}}},
+			[]OutputField{{Field: Field{Name: "answers", Type: FieldTypeArray}}},
+		)
+		err := sig.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no Items")
+	})
+
+	t.Run("object field without Properties fails", func(t *testing.T) {
+		sig := NewSignature(
+			[]InputField{{Field: Field{Name: "question"}}},
+			[]OutputField{{Field: Field{Name: "result", Type: FieldTypeObject}}},
+		)
+		err := sig.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no Properties")
+	})
+
+	t.Run("duplicate output prefixes fail", func(t *testing.T) {
+		sig := NewSignature(
+			[]InputField{{Field: Field{Name: "question"}}},
+			[]OutputField{
+				{Field: Field{Name: "answer", Prefix: "Result:"}},
+				{Field: Field{Name: "explanation", Prefix: "result:"}},
+			},
+		)
+		err := sig.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "share the same prefix")
+	})
+
+	t.Run("overlapping output prefixes fail", func(t *testing.T) {
+		sig := NewSignature(
+			[]InputField{{Field: Field{Name: "question"}}},
+			[]OutputField{
+				{Field: Field{Name: "ans", Prefix: "ans:"}},
+				{Field: Field{Name: "answer", Prefix: "answer:"}},
+			},
+		)
+		err := sig.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "overlapping prefixes")
+	})
+
+	t.Run("non-overlapping output prefixes pass", func(t *testing.T) {
+		sig := NewSignature(
+			[]InputField{{Field: Field{Name: "question"}}},
+			[]OutputField{
+				{Field: Field{Name: "answer", Prefix: "answer:"}},
+				{Field: Field{Name: "explanation", Prefix: "explanation:"}},
+			},
+		)
+		assert.NoError(t, sig.Validate())
+	})
+}
+
+func TestSignatureCoerceNumericOutputs(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "question"}}},
+		[]OutputField{
+			{Field: Field{Name: "id", Type: FieldTypeInt}},
+			{Field: Field{Name: "name", Type: FieldTypeString}},
+		},
+	)
+
+	t.Run("json.Number coerces to int64", func(t *testing.T) {
+		outputs := map[string]interface{}{
+			"id":   json.Number("9223372036854775"),
+			"name": "Alice",
+		}
+		coerced := sig.CoerceNumericOutputs(outputs)
+		assert.Equal(t, int64(9223372036854775), coerced["id"])
+		assert.Equal(t, "Alice", coerced["name"])
+	})
+
+	t.Run("float64 coerces to int64", func(t *testing.T) {
+		outputs := map[string]interface{}{"id": float64(42), "name": "Bob"}
+		coerced := sig.CoerceNumericOutputs(outputs)
+		assert.Equal(t, int64(42), coerced["id"])
+	})
+
+	t.Run("non-numeric value is left untouched", func(t *testing.T) {
+		outputs := map[string]interface{}{"id": "not-a-number", "name": "Carol"}
+		coerced := sig.CoerceNumericOutputs(outputs)
+		assert.Equal(t, "not-a-number", coerced["id"])
+	})
+
+	t.Run("does not mutate the input map", func(t *testing.T) {
+		outputs := map[string]interface{}{"id": float64(7), "name": "Dana"}
+		_ = sig.CoerceNumericOutputs(outputs)
+		assert.Equal(t, float64(7), outputs["id"])
+	})
+
+	t.Run("missing field is ignored", func(t *testing.T) {
+		outputs := map[string]interface{}{"name": "Eve"}
+		coerced := sig.CoerceNumericOutputs(outputs)
+		assert.Equal(t, "Eve", coerced["name"])
+		_, ok := coerced["id"]
+		assert.False(t, ok)
+	})
+}
+
+func TestSignatureMerge(t *testing.T) {
+	t.Run("clean merge unions inputs and outputs", func(t *testing.T) {
+		stage1 := NewSignature(
+			[]InputField{{Field: Field{Name: "question"}}},
+			[]OutputField{{Field: Field{Name: "answer"}}},
+		)
+		stage2 := NewSignature(
+			[]InputField{{Field: Field{Name: "context"}}},
+			[]OutputField{{Field: Field{Name: "summary"}}},
+		)
+
+		merged, err := stage1.Merge(stage2)
+		require.NoError(t, err)
+
+		inputNames := make([]string, len(merged.Inputs))
+		for i, f := range merged.Inputs {
+			inputNames[i] = f.Name
+		}
+		outputNames := make([]string, len(merged.Outputs))
+		for i, f := range merged.Outputs {
+			outputNames[i] = f.Name
+		}
+		assert.Equal(t, []string{"question", "context"}, inputNames)
+		assert.Equal(t, []string{"answer", "summary"}, outputNames)
+	})
+
+	t.Run("stage N+1's input satisfied by stage N's output is dropped from merged inputs", func(t *testing.T) {
+		stage1 := NewSignature(
+			[]InputField{{Field: Field{Name: "question"}}},
+			[]OutputField{{Field: Field{Name: "answer"}}},
+		)
+		stage2 := NewSignature(
+			[]InputField{{Field: Field{Name: "answer"}}},
+			[]OutputField{{Field: Field{Name: "summary"}}},
+		)
+
+		merged, err := stage1.Merge(stage2)
+		require.NoError(t, err)
+
+		inputNames := make([]string, len(merged.Inputs))
+		for i, f := range merged.Inputs {
+			inputNames[i] = f.Name
+		}
+		outputNames := make([]string, len(merged.Outputs))
+		for i, f := range merged.Outputs {
+			outputNames[i] = f.Name
+		}
+		assert.Equal(t, []string{"question"}, inputNames)
+		assert.Equal(t, []string{"answer", "summary"}, outputNames)
+	})
+
+	t.Run("shared field with identical type merges to one field", func(t *testing.T) {
+		stage1 := NewSignature(
+			[]InputField{{Field: Field{Name: "question"}}},
+			[]OutputField{{Field: Field{Name: "score", Type: FieldTypeInt}}},
+		)
+		stage2 := NewSignature(
+			[]InputField{{Field: Field{Name: "score", Type: FieldTypeInt}}},
+			[]OutputField{{Field: Field{Name: "verdict"}}},
+		)
+
+		merged, err := stage1.Merge(stage2)
+		require.NoError(t, err)
+		assert.Len(t, merged.Inputs, 1)
+		assert.Equal(t, "question", merged.Inputs[0].Name)
+	})
+
+	t.Run("conflicting input types error", func(t *testing.T) {
+		stage1 := NewSignature(
+			[]InputField{{Field: Field{Name: "id", Type: FieldTypeInt}}},
+			[]OutputField{{Field: Field{Name: "answer"}}},
+		)
+		stage2 := NewSignature(
+			[]InputField{{Field: Field{Name: "id", Type: FieldTypeString}}},
+			[]OutputField{{Field: Field{Name: "summary"}}},
+		)
+
+		_, err := stage1.Merge(stage2)
+		assert.Error(t, err)
+	})
+
+	t.Run("conflicting nested array item types error", func(t *testing.T) {
+		stage1 := NewSignature(
+			[]InputField{{Field: Field{Name: "question"}}},
+			[]OutputField{{Field: Field{
+				Name:  "tags",
+				Type:  FieldTypeArray,
+				Items: &Field{Type: FieldTypeString},
+			}}},
+		)
+		stage2 := NewSignature(
+			[]InputField{{Field: Field{
+				Name:  "tags",
+				Type:  FieldTypeArray,
+				Items: &Field{Type: FieldTypeInt},
+			}}},
+			[]OutputField{{Field: Field{Name: "summary"}}},
+		)
+
+		_, err := stage1.Merge(stage2)
+		assert.Error(t, err)
+	})
+
+	t.Run("compatible nested object properties merge cleanly", func(t *testing.T) {
+		props := map[string]*Field{
+			"city": {Type: FieldTypeString},
+		}
+		stage1 := NewSignature(
+			[]InputField{{Field: Field{Name: "question"}}},
+			[]OutputField{{Field: Field{Name: "address", Type: FieldTypeObject, Properties: props}}},
+		)
+		stage2 := NewSignature(
+			[]InputField{{Field: Field{Name: "address", Type: FieldTypeObject, Properties: props}}},
+			[]OutputField{{Field: Field{Name: "summary"}}},
+		)
+
+		merged, err := stage1.Merge(stage2)
+		require.NoError(t, err)
+		assert.Len(t, merged.Inputs, 1)
+	})
+}
+
+func TestSignatureSelectOutputs(t *testing.T) {
+	props := map[string]*Field{
+		"city": {Type: FieldTypeString},
+	}
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "question"}}},
+		[]OutputField{
+			{Field: Field{Name: "answer"}},
+			{Field: Field{Name: "address", Type: FieldTypeObject, Properties: props}},
+			{Field: Field{Name: "confidence", Type: FieldTypeInt}},
+		},
+	).WithInstruction("Answer carefully")
+
+	t.Run("valid subset preserves field definitions, order, and instruction", func(t *testing.T) {
+		sub, err := sig.SelectOutputs("confidence", "address")
+		require.NoError(t, err)
+
+		require.Len(t, sub.Outputs, 2)
+		assert.Equal(t, "confidence", sub.Outputs[0].Name)
+		assert.Equal(t, FieldTypeInt, sub.Outputs[0].Type)
+		assert.Equal(t, "address", sub.Outputs[1].Name)
+		assert.Equal(t, props, sub.Outputs[1].Properties)
+		assert.Equal(t, sig.Inputs, sub.Inputs)
+		assert.Equal(t, "Answer carefully", sub.Instruction)
+	})
+
+	t.Run("unknown output name errors", func(t *testing.T) {
+		_, err := sig.SelectOutputs("answer", "nonexistent")
+		assert.Error(t, err)
+	})
+}
+
+func TestSignatureSelectInputs(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{
+			{Field: Field{Name: "question"}},
+			{Field: Field{Name: "context", Type: FieldTypeString}},
+		},
+		[]OutputField{{Field: Field{Name: "answer"}}},
+	).WithInstruction("Answer carefully")
+
+	t.Run("valid subset preserves field definitions, order, and instruction", func(t *testing.T) {
+		sub, err := sig.SelectInputs("context")
+		require.NoError(t, err)
+
+		require.Len(t, sub.Inputs, 1)
+		assert.Equal(t, "context", sub.Inputs[0].Name)
+		assert.Equal(t, sig.Outputs, sub.Outputs)
+		assert.Equal(t, "Answer carefully", sub.Instruction)
+	})
+
+	t.Run("unknown input name errors", func(t *testing.T) {
+		_, err := sig.SelectInputs("question", "nonexistent")
+		assert.Error(t, err)
+	})
+}
+
+func TestSignatureExampleOutputJSON(t *testing.T) {
+	sig := NewSignature(
+		[]InputField{{Field: Field{Name: "question"}}},
+		[]OutputField{
+			{Field: Field{Name: "answer", Example: "Paris"}},
+			{Field: Field{Name: "confidence", Type: FieldTypeInt}},
+			{Field: Field{Name: "verified", Type: FieldTypeBool}},
+			{Field: Field{Name: "status", Type: FieldTypeString, Enum: []string{"open", "closed"}}},
+			{Field: Field{Name: "tags", Type: FieldTypeArray, Items: &Field{Type: FieldTypeString}}},
+			{Field: Field{Name: "address", Type: FieldTypeObject, Properties: map[string]*Field{
+				"city": {Type: FieldTypeString},
+				"zip":  {Type: FieldTypeString},
+			}}},
+		},
+	)
+
+	data, err := sig.ExampleOutputJSON()
+	require.NoError(t, err)
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &got))
+
+	assert.Equal(t, "Paris", got["answer"])
+	assert.Equal(t, float64(0), got["confidence"])
+	assert.Equal(t, false, got["verified"])
+	assert.Equal(t, "open", got["status"])
+	assert.Equal(t, []interface{}{"example value"}, got["tags"])
+
+	address, ok := got["address"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "example value", address["city"])
+	assert.Equal(t, "example value", address["zip"])
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"context"
+	"strings"
+
+	"github.com/darwishdev/dspy-go/pkg/errors"
+)
+
+// CollectStreamResult is the aggregated outcome of draining a
+// StreamResponse with CollectStream.
+type CollectStreamResult struct {
+	Content string
+	Usage   TokenInfo
+}
+
+// CollectStream drains stream into a single aggregated result, checking
+// ctx.Done() between chunk reads instead of just ranging over
+// stream.ChunkChannel - a stalled upstream would otherwise block the
+// caller forever regardless of ctx's deadline. If ctx is canceled or its
+// deadline passes before the stream finishes, CollectStream calls
+// stream.Cancel and returns ctx's error alongside whatever content and
+// usage were collected so far, so the caller can decide whether the
+// partial result is still worth using.
+func CollectStream(ctx context.Context, stream *StreamResponse) (*CollectStreamResult, error) {
+	result := &CollectStreamResult{}
+	var content strings.Builder
+
+	for {
+		select {
+		case <-ctx.Done():
+			stream.Cancel()
+			result.Content = content.String()
+			return result, errors.Wrap(ctx.Err(), errors.Timeout, "stream collection deadline exceeded")
+		case chunk, ok := <-stream.ChunkChannel:
+			if !ok {
+				result.Content = content.String()
+				return result, nil
+			}
+			if chunk.Usage != nil {
+				// Usage is cumulative (the totals-so-far, not a delta since
+				// the previous chunk) - see geminiStreamChunk.UsageMetadata
+				// - so the latest chunk's numbers simply replace the
+				// running total rather than adding to it.
+				result.Usage.PromptTokens = chunk.Usage.PromptTokens
+				result.Usage.CompletionTokens = chunk.Usage.CompletionTokens
+				result.Usage.TotalTokens = result.Usage.PromptTokens + result.Usage.CompletionTokens
+			}
+			if chunk.Error != nil {
+				result.Content = content.String()
+				return result, chunk.Error
+			}
+			if chunk.Done {
+				result.Content = content.String()
+				return result, nil
+			}
+			content.WriteString(chunk.Content)
+		}
+	}
+}
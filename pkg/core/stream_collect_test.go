@@ -0,0 +1,84 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectStream_AggregatesUntilDone(t *testing.T) {
+	chunkChan := make(chan StreamChunk, 3)
+	chunkChan <- StreamChunk{Content: "hello ", Usage: &TokenInfo{PromptTokens: 5, CompletionTokens: 1}}
+	chunkChan <- StreamChunk{Content: "world", Usage: &TokenInfo{PromptTokens: 5, CompletionTokens: 2}}
+	chunkChan <- StreamChunk{Done: true}
+	close(chunkChan)
+
+	stream := &StreamResponse{ChunkChannel: chunkChan, Cancel: func() {}}
+
+	result, err := CollectStream(context.Background(), stream)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", result.Content)
+	assert.Equal(t, 5, result.Usage.PromptTokens)
+	assert.Equal(t, 2, result.Usage.CompletionTokens)
+	assert.Equal(t, 7, result.Usage.TotalTokens)
+}
+
+func TestCollectStream_PropagatesChunkError(t *testing.T) {
+	chunkChan := make(chan StreamChunk, 2)
+	chunkChan <- StreamChunk{Content: "partial"}
+	chunkChan <- StreamChunk{Error: errors.New("upstream exploded")}
+	close(chunkChan)
+
+	stream := &StreamResponse{ChunkChannel: chunkChan, Cancel: func() {}}
+
+	result, err := CollectStream(context.Background(), stream)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "upstream exploded")
+	assert.Equal(t, "partial", result.Content)
+}
+
+func TestCollectStream_StopsOnContextDeadlineInsteadOfBlockingForever(t *testing.T) {
+	// Nothing is ever sent on this channel, simulating a stalled upstream.
+	chunkChan := make(chan StreamChunk)
+	canceled := false
+	stream := &StreamResponse{
+		ChunkChannel: chunkChan,
+		Cancel:       func() { canceled = true },
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	result, err := CollectStream(ctx, stream)
+	elapsed := time.Since(start)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, canceled, "expected CollectStream to call stream.Cancel on deadline")
+	assert.Less(t, elapsed, time.Second, "CollectStream should not block past the deadline")
+	assert.Equal(t, "", result.Content)
+}
+
+func TestCollectStream_ReturnsPartialContentCollectedBeforeDeadline(t *testing.T) {
+	chunkChan := make(chan StreamChunk)
+	stream := &StreamResponse{ChunkChannel: chunkChan, Cancel: func() {}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 40*time.Millisecond)
+	defer cancel()
+
+	go func() {
+		chunkChan <- StreamChunk{Content: "partial before stall"}
+		// Then stall forever (until the test process exits); the deadline
+		// should still fire on the reader side.
+	}()
+
+	result, err := CollectStream(ctx, stream)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "partial before stall", result.Content)
+}
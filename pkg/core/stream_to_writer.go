@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"io"
+)
+
+// flusherWithError matches bufio.Writer's Flush method.
+type flusherWithError interface {
+	Flush() error
+}
+
+// flusherNoError matches http.Flusher's Flush method.
+type flusherNoError interface {
+	Flush()
+}
+
+// StreamToWriter streams llm's response to prompt, writing each chunk's
+// text to w as it arrives, and returns the assembled final response (with
+// token usage) once the stream finishes. It's the common "print a
+// streaming response" case CollectStream doesn't cover on its own, since
+// CollectStream only aggregates in memory without writing anywhere.
+//
+// If w implements Flush() error (e.g. *bufio.Writer) or Flush() (e.g.
+// http.Flusher), StreamToWriter calls it after every write so a chunk
+// reaches the other end immediately instead of sitting in a buffer. A
+// write error cancels the underlying stream and is returned immediately -
+// a broken pipe on the write side isn't something more streaming can fix.
+func StreamToWriter(ctx context.Context, llm LLM, prompt string, w io.Writer, options ...GenerateOption) (*LLMResponse, error) {
+	stream, err := llm.StreamGenerate(ctx, prompt, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &LLMResponse{Usage: &TokenInfo{}}
+
+	for {
+		select {
+		case <-ctx.Done():
+			stream.Cancel()
+			return response, ctx.Err()
+		case chunk, ok := <-stream.ChunkChannel:
+			if !ok {
+				return response, nil
+			}
+			if chunk.Error != nil {
+				stream.Cancel()
+				return response, chunk.Error
+			}
+			if chunk.Usage != nil {
+				response.Usage.PromptTokens = chunk.Usage.PromptTokens
+				response.Usage.CompletionTokens = chunk.Usage.CompletionTokens
+				response.Usage.TotalTokens = response.Usage.PromptTokens + response.Usage.CompletionTokens
+			}
+			if chunk.Content != "" {
+				if _, werr := w.Write([]byte(chunk.Content)); werr != nil {
+					stream.Cancel()
+					return response, werr
+				}
+				response.Content += chunk.Content
+				if err := flush(w); err != nil {
+					stream.Cancel()
+					return response, err
+				}
+			}
+			if chunk.Done {
+				return response, nil
+			}
+		}
+	}
+}
+
+// flush flushes w if it supports Flush() error or Flush(), and is a no-op
+// otherwise.
+func flush(w io.Writer) error {
+	if f, ok := w.(flusherWithError); ok {
+		return f.Flush()
+	}
+	if f, ok := w.(flusherNoError); ok {
+		f.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamingMockLLM implements just enough of the LLM interface for
+// StreamToWriter's tests: a single canned StreamResponse returned from
+// StreamGenerate.
+type streamingMockLLM struct {
+	MockBaseLLM
+	stream *StreamResponse
+}
+
+func (m *streamingMockLLM) StreamGenerate(ctx context.Context, prompt string, opts ...GenerateOption) (*StreamResponse, error) {
+	return m.stream, nil
+}
+
+func TestStreamToWriter_WritesChunksAndReturnsAssembledResponse(t *testing.T) {
+	chunkChan := make(chan StreamChunk, 3)
+	chunkChan <- StreamChunk{Content: "hello ", Usage: &TokenInfo{PromptTokens: 5, CompletionTokens: 1}}
+	chunkChan <- StreamChunk{Content: "world", Usage: &TokenInfo{PromptTokens: 5, CompletionTokens: 2}}
+	chunkChan <- StreamChunk{Done: true}
+	close(chunkChan)
+
+	llm := &streamingMockLLM{stream: &StreamResponse{ChunkChannel: chunkChan, Cancel: func() {}}}
+
+	var buf bytes.Buffer
+	response, err := StreamToWriter(context.Background(), llm, "prompt", &buf)
+
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", buf.String())
+	assert.Equal(t, "hello world", response.Content)
+	assert.Equal(t, 7, response.Usage.TotalTokens)
+}
+
+func TestStreamToWriter_FlushesABufferedWriterAfterEachChunk(t *testing.T) {
+	chunkChan := make(chan StreamChunk, 2)
+	chunkChan <- StreamChunk{Content: "chunk"}
+	chunkChan <- StreamChunk{Done: true}
+	close(chunkChan)
+
+	llm := &streamingMockLLM{stream: &StreamResponse{ChunkChannel: chunkChan, Cancel: func() {}}}
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	_, err := StreamToWriter(context.Background(), llm, "prompt", w)
+
+	require.NoError(t, err)
+	// If StreamToWriter didn't flush, buf would still be empty even though
+	// w itself received the write.
+	assert.Equal(t, "chunk", buf.String())
+}
+
+func TestStreamToWriter_CancelsStreamOnWriteError(t *testing.T) {
+	chunkChan := make(chan StreamChunk, 1)
+	chunkChan <- StreamChunk{Content: "chunk"}
+
+	canceled := false
+	llm := &streamingMockLLM{stream: &StreamResponse{
+		ChunkChannel: chunkChan,
+		Cancel:       func() { canceled = true },
+	}}
+
+	_, err := StreamToWriter(context.Background(), llm, "prompt", failingWriter{})
+
+	require.Error(t, err)
+	assert.True(t, canceled, "expected StreamToWriter to cancel the stream on a write error")
+}
+
+func TestStreamToWriter_PropagatesChunkError(t *testing.T) {
+	chunkChan := make(chan StreamChunk, 2)
+	chunkChan <- StreamChunk{Content: "partial"}
+	chunkChan <- StreamChunk{Error: errors.New("upstream exploded")}
+	close(chunkChan)
+
+	llm := &streamingMockLLM{stream: &StreamResponse{ChunkChannel: chunkChan, Cancel: func() {}}}
+
+	var buf bytes.Buffer
+	_, err := StreamToWriter(context.Background(), llm, "prompt", &buf)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "upstream exploded")
+	assert.Equal(t, "partial", buf.String())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
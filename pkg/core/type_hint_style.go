@@ -0,0 +1,75 @@
+package core
+
+import (
+	"sort"
+	"strings"
+)
+
+// TypeHintStyle renders a compact type hint for field, such as "(integer)"
+// or "(array of string)", appended to the field's instructions in a
+// text-rendered prompt. This helps a model asked for prefix-parsed (not
+// JSON-mode) output produce well-formed values for non-text fields, which
+// otherwise carry no signal about their expected shape. Return "" to omit
+// a hint for a particular field.
+type TypeHintStyle func(field Field) string
+
+// CompactTypeHintStyle renders "(type)" for a scalar field, "(array of
+// item-type)" for an array, and "(object: key (type), ...)" for an object,
+// recursing into Items/Properties the way Field itself nests. It omits the
+// hint entirely for FieldTypeText, the implicit default for most fields,
+// since flagging every plain text field would be more noise than signal.
+func CompactTypeHintStyle(field Field) string {
+	hint := typeHint(field)
+	if hint == "" {
+		return ""
+	}
+	return "(" + hint + ")"
+}
+
+func typeHint(field Field) string {
+	switch field.Type {
+	case FieldTypeArray:
+		if field.Items == nil {
+			return "array"
+		}
+		itemHint := typeHint(*field.Items)
+		if itemHint == "" {
+			return "array"
+		}
+		return "array of " + itemHint
+
+	case FieldTypeObject:
+		if len(field.Properties) == 0 {
+			return "object"
+		}
+		names := make([]string, 0, len(field.Properties))
+		for name := range field.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		parts := make([]string, 0, len(names))
+		for _, name := range names {
+			prop := field.Properties[name]
+			if propHint := typeHint(*prop); propHint != "" {
+				parts = append(parts, name+" ("+propHint+")")
+			} else {
+				parts = append(parts, name)
+			}
+		}
+		return "object: " + strings.Join(parts, ", ")
+
+	case FieldTypeInt:
+		return "integer"
+	case FieldTypeBool:
+		return "boolean"
+	case FieldTypeString:
+		return "string"
+	case FieldTypeImage:
+		return "image"
+	case FieldTypeAudio:
+		return "audio"
+	default:
+		return ""
+	}
+}
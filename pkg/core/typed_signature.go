@@ -3,10 +3,86 @@ package core
 import (
 	"fmt"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"unicode"
+
+	"github.com/darwishdev/dspy-go/pkg/utils"
+)
+
+// FieldNamingStrategy controls how a Go struct field's name is converted
+// into its default prompt/schema field name when the field carries no
+// explicit `dspy:"name"` tag.
+type FieldNamingStrategy int
+
+const (
+	// FieldNamingLowercase lowercases the Go field name with no separators
+	// (e.g. "FirstName" -> "firstname"). This is the historical default.
+	FieldNamingLowercase FieldNamingStrategy = iota
+	// FieldNamingSnakeCase inserts underscores at word boundaries and
+	// lowercases the result (e.g. "FirstName" -> "first_name").
+	FieldNamingSnakeCase
+	// FieldNamingCamelCase lowercases only the leading letter, leaving the
+	// rest of the Go field name untouched (e.g. "FirstName" -> "firstName").
+	FieldNamingCamelCase
+	// FieldNamingAsIs keeps the Go field name exactly as written (e.g.
+	// "FirstName" -> "FirstName").
+	FieldNamingAsIs
 )
 
+// applyFieldNamingStrategy converts a Go field name into its default
+// signature field name according to strategy.
+func applyFieldNamingStrategy(name string, strategy FieldNamingStrategy) string {
+	switch strategy {
+	case FieldNamingSnakeCase:
+		return toSnakeCase(name)
+	case FieldNamingCamelCase:
+		return toLowerCamelCase(name)
+	case FieldNamingAsIs:
+		return name
+	default:
+		return strings.ToLower(name)
+	}
+}
+
+// toSnakeCase lowercases name and inserts an underscore at each word
+// boundary, treating a run of consecutive uppercase letters as a single
+// acronym rather than splitting it rune-by-rune: "UserID" -> "user_id", not
+// "user_i_d". A boundary falls before an uppercase rune whose predecessor is
+// lowercase or a digit (e.g. "userName" -> "user_name"), and before the last
+// uppercase rune of an acronym when it's followed by a lowercase rune (e.g.
+// "URLPath" -> "url_path"). Case folding goes through unicode.ToLower rune by
+// rune, so accented letters normalize correctly wherever Unicode defines a
+// mapping for them, not just ASCII.
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			endsAcronym := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || endsAcronym {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+// toLowerCamelCase lowercases the leading rune of name, leaving the rest
+// of the Go field name's casing untouched.
+func toLowerCamelCase(name string) string {
+	if name == "" {
+		return name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
 //
 // ────────────────────────────────────────────────
 //  FIELD METADATA EXTENDED FOR NESTED STRUCTURES
@@ -21,15 +97,68 @@ type FieldMetadata struct {
 	Prefix      string
 	Type        FieldType
 	GoType      reflect.Type
+	Sensitive   bool     // from the `sensitive:"true"` struct tag; see Field.Sensitive
+	Transient   bool     // from the `dspy:",transient"` tag; see Field.Transient
+	Enum        []string // allowed values for a field whose Go type was registered via utils.RegisterEnum
+
+	// Pattern, Min, and Max are scalar validation constraints from the
+	// `pattern:"..."`, `min:"..."`, and `max:"..."` struct tags - see
+	// validateScalarConstraints. On an array field these describe each
+	// element rather than the array itself (a slice has no scalar value
+	// to pattern-match or bound), so they're carried on Item instead of
+	// on the array's own FieldMetadata.
+	Pattern string
+	Min     *float64
+	Max     *float64
+
+	// RequiredIf, from the `requiredif:"siblingField=value"` struct tag,
+	// makes this field required only when the named sibling field equals
+	// value - e.g. a phone field required only when contactMethod is
+	// "phone". Nil means the field's required-ness is purely static
+	// (Required). See validateStruct's evaluation of it.
+	RequiredIf *RequiredIfCondition
 
 	// NEW
-	Item       *FieldMetadata            // for arrays
-	Properties map[string]*FieldMetadata // for nested objects
+	Item          *FieldMetadata            // for arrays
+	Properties    map[string]*FieldMetadata // for nested objects
+	PropertyOrder []string                  // declaration order of Properties' keys, for deterministic iteration
+}
+
+// RequiredIfCondition is a parsed `requiredif:"field=value"` tag: the field
+// it's attached to is required only when its sibling named Field has a
+// string representation equal to Value. Only equality against a literal is
+// supported.
+type RequiredIfCondition struct {
+	Field string
+	Value string
 }
 type SignatureMetadata struct {
 	Inputs      []FieldMetadata
 	Outputs     []FieldMetadata
 	Instruction string
+
+	diagnostics []Diagnostic
+}
+
+// Diagnostic is a non-fatal observation recorded while parsing a struct
+// into FieldMetadata - e.g. a field whose Go type has no defined mapping
+// and silently defaulted to FieldTypeText. Signature construction has no
+// error return to surface modeling mistakes like this through, so they're
+// collected here instead for a caller who wants to check for them.
+type Diagnostic struct {
+	// Path is the dotted/indexed path to the field that prompted the
+	// diagnostic, e.g. "input.items.payload" (see ValidationError.Path for
+	// the same convention applied to validation failures).
+	Path string
+	// Message describes what was observed and what default was used.
+	Message string
+}
+
+// Diagnostics returns the non-fatal warnings collected while parsing this
+// signature's input and output types. An empty slice means nothing worth
+// flagging was found.
+func (m SignatureMetadata) Diagnostics() []Diagnostic {
+	return m.diagnostics
 }
 
 //
@@ -51,9 +180,15 @@ type typedSignatureImpl[TInput, TOutput any] struct {
 //
 
 func createTypedSignatureImpl[TInput, TOutput any](inputType, outputType reflect.Type) *typedSignatureImpl[TInput, TOutput] {
+	var diags []Diagnostic
 	metadata := SignatureMetadata{
-		Inputs:  parseStructFields(inputType, true),
-		Outputs: parseStructFields(outputType, false),
+		Inputs:      parseStructFields(inputType, true, "input", &diags),
+		Outputs:     parseStructFields(outputType, false, "output", &diags),
+		Instruction: structInstruction(inputType),
+		diagnostics: diags,
+	}
+	if metadata.Instruction == "" {
+		metadata.Instruction = structInstruction(outputType)
 	}
 
 	return &typedSignatureImpl[TInput, TOutput]{
@@ -63,17 +198,95 @@ func createTypedSignatureImpl[TInput, TOutput any](inputType, outputType reflect
 	}
 }
 
+// instructionProvider is implemented by an input or output type that wants
+// to co-locate its prompt instruction with its field definitions instead
+// of setting it via WithInstruction at every call site. See structInstruction.
+type instructionProvider interface {
+	Instruction() string
+}
+
+// structInstruction looks up t's struct-level instruction, trying the
+// Instruction() string method form first (instructionProvider, checked on
+// both the value and a pointer, since the method may be declared with
+// either receiver) and falling back to an `instruction:"..."` tag on any
+// field, exported or not - the latter lets a type declare the instruction
+// without adding a method, typically via an unexported marker field like
+// `_ struct{} \`instruction:"..."\“. It returns "" if t defines neither.
+// A Signature built this way can still have its instruction changed later
+// via WithInstruction, which always takes precedence.
+func structInstruction(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return ""
+	}
+
+	if p, ok := reflect.New(t).Elem().Interface().(instructionProvider); ok {
+		return p.Instruction()
+	}
+	if p, ok := reflect.New(t).Interface().(instructionProvider); ok {
+		return p.Instruction()
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		if tag, ok := t.Field(i).Tag.Lookup("instruction"); ok {
+			return tag
+		}
+	}
+	return ""
+}
+
 //
 // ────────────────────────────────────────────────
 //  STRUCT FIELD PARSER (recursive)
 // ────────────────────────────────────────────────
 //
 
-func parseStructFields(t reflect.Type, isInput bool) []FieldMetadata {
+// structFieldsCache memoizes parseStructFields by (type, isInput, path,
+// naming strategy), so NewTypedSignature - not just
+// NewTypedSignatureCached, which caches a whole signature rather than this
+// per-type parse - avoids re-walking reflection on every call for the same
+// hot type. Like typedSignatureCache, a cache hit returns the exact cached
+// slice rather than a copy: FieldMetadata is built once and treated as
+// read-only afterward. GlobalConfig.FieldNamingStrategy is part of the key,
+// not just an input read once at parse time, since a field's Name is
+// derived from it - without that, flipping the strategy between calls for
+// an already-cached type would keep serving names from whichever strategy
+// was active on the first call.
+var structFieldsCache sync.Map
+
+type structFieldsCacheKey struct {
+	t        reflect.Type
+	isInput  bool
+	path     string
+	strategy FieldNamingStrategy
+}
+
+type structFieldsCacheEntry struct {
+	fields []FieldMetadata
+	diags  []Diagnostic
+}
+
+func parseStructFields(t reflect.Type, isInput bool, path string, diags *[]Diagnostic) []FieldMetadata {
 	if t == nil || t.Kind() != reflect.Struct {
 		return nil
 	}
+
+	key := structFieldsCacheKey{t: t, isInput: isInput, path: path, strategy: GlobalConfig.FieldNamingStrategy}
+	if cached, ok := structFieldsCache.Load(key); ok {
+		entry := cached.(structFieldsCacheEntry)
+		if diags != nil {
+			*diags = append(*diags, entry.diags...)
+		}
+		return entry.fields
+	}
+
 	var fields []FieldMetadata
+	var localDiags []Diagnostic
 
 	for i := 0; i < t.NumField(); i++ {
 		sf := t.Field(i)
@@ -81,11 +294,16 @@ func parseStructFields(t reflect.Type, isInput bool) []FieldMetadata {
 			continue
 		}
 
-		meta := parseFieldMetadataRecursive(sf, isInput)
+		meta := parseFieldMetadataRecursive(sf, isInput, path+"."+fieldSignatureName(sf), &localDiags)
 		fields = append(fields, meta)
 	}
 
-	return fields
+	actual, _ := structFieldsCache.LoadOrStore(key, structFieldsCacheEntry{fields: fields, diags: localDiags})
+	entry := actual.(structFieldsCacheEntry)
+	if diags != nil {
+		*diags = append(*diags, entry.diags...)
+	}
+	return entry.fields
 }
 
 //
@@ -94,25 +312,68 @@ func parseStructFields(t reflect.Type, isInput bool) []FieldMetadata {
 // ────────────────────────────────────────────────
 //
 
-func parseFieldMetadataRecursive(field reflect.StructField, isInput bool) FieldMetadata {
+// fieldSignatureName returns the name a struct field is known by in the
+// signature - the dspy tag's name when set, otherwise sf.Name run through
+// GlobalConfig.FieldNamingStrategy. Callers building a fieldPath for a
+// nested field use this instead of sf.Name directly, so a Diagnostic's
+// Path follows the same convention as ValidationError.Path (e.g.
+// "input.weight" for a Go field Weight tagged `dspy:"weight"`, not
+// "input.Weight").
+func fieldSignatureName(sf reflect.StructField) string {
+	name := applyFieldNamingStrategy(sf.Name, GlobalConfig.FieldNamingStrategy)
+	if tag := sf.Tag.Get("dspy"); tag != "" {
+		if parts := strings.Split(tag, ","); parts[0] != "" {
+			name = parts[0]
+		}
+	}
+	return name
+}
+
+// fieldPath is the already-fully-qualified path to field (e.g.
+// "input.items[].address.zip"), computed by the caller - struct fields
+// append ".Name" to their parent's path, array elements append "[]"
+// instead, since a fake element field's Name is often empty.
+func parseFieldMetadataRecursive(field reflect.StructField, isInput bool, fieldPath string, diags *[]Diagnostic) FieldMetadata {
+	fieldType, defaulted := inferFieldType(field.Type)
+	if defaulted && diags != nil {
+		*diags = append(*diags, Diagnostic{
+			Path:    fieldPath,
+			Message: fmt.Sprintf("field of type %s has no defined mapping and defaulted to text", field.Type),
+		})
+	}
+
 	meta := FieldMetadata{
-		Name:        strings.ToLower(field.Name),
+		Name:        fieldSignatureName(field),
 		GoFieldName: field.Name,
 		GoType:      field.Type,
-		Type:        inferFieldType(field.Type),
+		Type:        fieldType,
 		Description: field.Name,
 		Required:    false,
 	}
 
-	// dspy:"name,required"
+	// A field whose Go type was registered via utils.RegisterEnum gets its
+	// allowed values carried along automatically from that registration;
+	// the struct tags have no option of their own for listing them.
+	enumType := field.Type
+	if enumType.Kind() == reflect.Ptr {
+		enumType = enumType.Elem()
+	}
+	if enum, ok := utils.EnumValues(enumType); ok {
+		meta.Enum = enum
+	}
+
+	// dspy:"name,required,transient"
 	if tag := field.Tag.Get("dspy"); tag != "" {
 		parts := strings.Split(tag, ",")
 		if parts[0] != "" {
 			meta.Name = parts[0]
 		}
 		for _, p := range parts[1:] {
-			if strings.TrimSpace(p) == "required" {
+			switch strings.TrimSpace(p) {
+			case "required":
 				meta.Required = true
+			case "transient":
+				meta.Transient = true
 			}
 		}
 	}
@@ -122,6 +383,11 @@ func parseFieldMetadataRecursive(field reflect.StructField, isInput bool) FieldM
 		meta.Description = desc
 	}
 
+	// sensitive:"true"
+	if sensitive := field.Tag.Get("sensitive"); sensitive == "true" {
+		meta.Sensitive = true
+	}
+
 	// prefix:"..."
 	if !isInput {
 		meta.Prefix = meta.Name + ":"
@@ -130,29 +396,75 @@ func parseFieldMetadataRecursive(field reflect.StructField, isInput bool) FieldM
 		meta.Prefix = pfx
 	}
 
+	// requiredif:"siblingField=value"
+	if cond := field.Tag.Get("requiredif"); cond != "" {
+		parts := strings.SplitN(cond, "=", 2)
+		if len(parts) == 2 {
+			meta.RequiredIf = &RequiredIfCondition{Field: parts[0], Value: parts[1]}
+		}
+	}
+
+	// pattern:"..." / min:"..." / max:"..."
+	pattern := field.Tag.Get("pattern")
+	minTag := parseFloatTag(field.Tag.Get("min"))
+	maxTag := parseFloatTag(field.Tag.Get("max"))
+
 	//
 	// NESTING LOGIC
 	//
 	switch meta.Type {
 
 	case FieldTypeObject:
-		meta.Properties = parseObjectProperties(field.Type)
+		meta.Properties, meta.PropertyOrder = parseObjectProperties(field.Type, fieldPath, diags)
 
 	case FieldTypeArray:
-		meta.Item = parseArrayElement(field.Type)
+		meta.Item = parseArrayElement(field.Type, fieldPath+"[]", diags)
+		// The array itself has no scalar value to constrain, so a
+		// pattern/min/max tag on the slice field describes each element
+		// instead - see FieldMetadata.Pattern.
+		if meta.Item != nil {
+			meta.Item.Pattern = pattern
+			meta.Item.Min = minTag
+			meta.Item.Max = maxTag
+		}
+
+	default:
+		meta.Pattern = pattern
+		meta.Min = minTag
+		meta.Max = maxTag
 
 	}
 
 	return meta
 }
 
+// parseFloatTag parses s as a float64, returning nil if s is empty or not
+// a valid number - used for the `min:"..."`/`max:"..."` tags, which are
+// absent far more often than present.
+func parseFloatTag(s string) *float64 {
+	if s == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
 //
 // ────────────────────────────────────────────────
 //  DETECT TYPE: int, bool, []string, struct, nested
 // ────────────────────────────────────────────────
 //
 
-func inferFieldType(t reflect.Type) FieldType {
+// inferFieldType maps t to the FieldType used to describe it. The second
+// return value reports whether t's kind has no defined mapping and this
+// fell through to the text default as a guess rather than a deliberate
+// choice - the caller uses that to record a Diagnostic, since this is the
+// case most likely to indicate a modeling mistake (e.g. a complex128 or
+// chan field nobody meant to expose to the model).
+func inferFieldType(t reflect.Type) (FieldType, bool) {
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
@@ -160,25 +472,35 @@ func inferFieldType(t reflect.Type) FieldType {
 	switch t.Kind() {
 
 	case reflect.String:
-		return FieldTypeString
+		return FieldTypeString, false
 
 	case reflect.Bool:
-		return FieldTypeBool
+		return FieldTypeBool, false
 
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return FieldTypeInt
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return FieldTypeInt, false
 
 	case reflect.Slice:
 		if t.Elem().Kind() == reflect.Uint8 {
-			return FieldTypeImage
+			return FieldTypeImage, false
 		}
-		return FieldTypeArray
+		return FieldTypeArray, false
 
 	case reflect.Map, reflect.Struct:
-		return FieldTypeObject
+		return FieldTypeObject, false
+
+	case reflect.Interface:
+		// interface{}/any fields are opaque to reflection until a concrete
+		// value is assigned, so they're treated as free-form text rather
+		// than an object: validateStruct has no static shape to recurse
+		// into, so it checks an "any" field for required-ness and then
+		// leaves it alone rather than attempting nested validation. This is
+		// a deliberate choice, not a missing mapping, so it isn't flagged.
+		return FieldTypeText, false
 
 	default:
-		return FieldTypeText
+		return FieldTypeText, true
 	}
 }
 
@@ -188,27 +510,29 @@ func inferFieldType(t reflect.Type) FieldType {
 // ────────────────────────────────────────────────
 //
 
-func parseObjectProperties(t reflect.Type) map[string]*FieldMetadata {
+func parseObjectProperties(t reflect.Type, path string, diags *[]Diagnostic) (map[string]*FieldMetadata, []string) {
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
 
 	props := map[string]*FieldMetadata{}
 	if t.Kind() != reflect.Struct {
-		return props
+		return props, nil
 	}
 
+	order := make([]string, 0, t.NumField())
 	for i := 0; i < t.NumField(); i++ {
 		sf := t.Field(i)
 		if !sf.IsExported() {
 			continue
 		}
 
-		child := parseFieldMetadataRecursive(sf, true)
+		child := parseFieldMetadataRecursive(sf, true, path+"."+fieldSignatureName(sf), diags)
 		props[child.Name] = &child
+		order = append(order, child.Name)
 	}
 
-	return props
+	return props, order
 }
 
 //
@@ -217,7 +541,11 @@ func parseObjectProperties(t reflect.Type) map[string]*FieldMetadata {
 // ────────────────────────────────────────────────
 //
 
-func parseArrayElement(t reflect.Type) *FieldMetadata {
+// elemPath is already-fully-qualified (see parseFieldMetadataRecursive);
+// callers append "[]" to the array field's own path rather than letting
+// this append ".Name", since the fake element field's Name is often empty
+// (e.g. for []int, the element type has no name).
+func parseArrayElement(t reflect.Type, elemPath string, diags *[]Diagnostic) *FieldMetadata {
 	elem := t.Elem()
 
 	fakeField := reflect.StructField{
@@ -226,7 +554,7 @@ func parseArrayElement(t reflect.Type) *FieldMetadata {
 		Tag:  "",
 	}
 
-	meta := parseFieldMetadataRecursive(fakeField, true)
+	meta := parseFieldMetadataRecursive(fakeField, true, elemPath, diags)
 	return &meta
 }
 
@@ -236,47 +564,433 @@ func parseArrayElement(t reflect.Type) *FieldMetadata {
 // ────────────────────────────────────────────────
 //
 
-func validateStruct(value any, expected []FieldMetadata, fieldType string) error {
+// ValidationError reports a single validateStruct failure, carrying the
+// full dotted/indexed path from the validated root to the offending field
+// (e.g. "input.items[2].address.zip") as a structured field rather than
+// only embedding it in the message string - so a caller, such as an API
+// layer mapping errors back to form fields, doesn't have to parse Error()
+// to recover it.
+type ValidationError struct {
+	// Path is the dotted/indexed path from the validated root to the field
+	// that failed, e.g. "input.items[2].address.zip".
+	Path string
+	// Message describes what's wrong about the field at Path.
+	Message string
+	// Field is the metadata for the field at Path - its type, whether it
+	// was required, its declared name, and so on - so logging middleware
+	// can emit structured fields (e.g. for analytics on which fields users
+	// fumble) instead of re-parsing them out of Message. Nil for failures
+	// that aren't about a specific field (e.g. the validated value itself
+	// being nil or not a struct).
+	Field *FieldMetadata
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationOptions configures a single ValidateInput/ValidateOutput call.
+type ValidationOptions struct {
+	// AllowEmptyRequiredArrays, when true, lets a required array field be
+	// satisfied by a non-nil-but-empty slice. False (the default)
+	// requires a required array to be both non-nil and non-empty -
+	// reflect distinguishes the two via IsNil (absent) vs Len (provided
+	// but empty), and by default both count as "not provided".
+	AllowEmptyRequiredArrays bool
+
+	// RequiredAllowZero, when true, lets a required field of any type be
+	// satisfied by its zero value (empty string, zero-length array, etc.)
+	// instead of being rejected as absent. bool and int fields already get
+	// this treatment unconditionally - see isZeroExemptKind - since Go
+	// gives them no way to distinguish "not provided" from "provided false/
+	// 0" in the first place; this option extends the same leniency to
+	// every other scalar type for a caller who doesn't need that
+	// distinction either.
+	RequiredAllowZero bool
+}
+
+// ValidationOption allows for optional parameters to ValidateInput/ValidateOutput.
+type ValidationOption func(*ValidationOptions)
+
+// WithAllowEmptyRequiredArrays makes a required array field satisfied by a
+// non-nil-but-empty slice, instead of the default that also rejects empty.
+func WithAllowEmptyRequiredArrays() ValidationOption {
+	return func(o *ValidationOptions) {
+		o.AllowEmptyRequiredArrays = true
+	}
+}
+
+// WithRequiredAllowZero makes a required field of any type satisfied by its
+// zero value, instead of the default that rejects it as absent. See
+// ValidationOptions.RequiredAllowZero.
+func WithRequiredAllowZero() ValidationOption {
+	return func(o *ValidationOptions) {
+		o.RequiredAllowZero = true
+	}
+}
+
+func newValidationOptions(opts []ValidationOption) ValidationOptions {
+	var options ValidationOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// requiredIfConditionMet evaluates cond against the sibling field it names
+// (matched against either that field's Name or GoFieldName, so a condition
+// can reference a field by whichever one is more natural for the caller)
+// within v's fields, described by expected. It returns false, without
+// error, for a sibling that's present but zero-valued - same as any other
+// unset field - and an error only when cond names a field that isn't part
+// of expected at all, which is a signature-authoring mistake rather than a
+// validation failure proper.
+func requiredIfConditionMet(v reflect.Value, expected []FieldMetadata, cond *RequiredIfCondition) (bool, error) {
+	for _, sibling := range expected {
+		if sibling.Name != cond.Field && sibling.GoFieldName != cond.Field {
+			continue
+		}
+		siblingValue := v.FieldByName(sibling.GoFieldName)
+		if !siblingValue.IsValid() || siblingValue.IsZero() {
+			return false, nil
+		}
+		return fmt.Sprintf("%v", siblingValue.Interface()) == cond.Value, nil
+	}
+	return false, fmt.Errorf("requiredif references unknown field %q", cond.Field)
+}
+
+// validateScalarConstraints checks field against meta's Enum, Pattern, Min,
+// and Max constraints, returning a *ValidationError describing the first
+// violation found, or nil if field satisfies all of them (or field is
+// invalid/zero, since a constraint doesn't apply to a value that isn't
+// there - an absent optional field is handled separately by the
+// required-field check, not flagged here). fieldPath is the caller's
+// already-qualified path for the value being checked, which for an array
+// element is the indexed path (e.g. "input.tags[2]") rather than meta's own
+// Name.
+func validateScalarConstraints(field reflect.Value, meta FieldMetadata, fieldPath string) *ValidationError {
+	if !field.IsValid() || field.IsZero() {
+		return nil
+	}
+
+	if field.Kind() == reflect.String {
+		s := field.String()
+		if len(meta.Enum) > 0 && !enumContains(meta.Enum, s) {
+			return &ValidationError{
+				Path:    fieldPath,
+				Message: fmt.Sprintf("has value %q not in allowed enum %v", s, meta.Enum),
+				Field:   &meta,
+			}
+		}
+		if meta.Pattern != "" {
+			re, err := regexp.Compile(meta.Pattern)
+			if err == nil && !re.MatchString(s) {
+				return &ValidationError{
+					Path:    fieldPath,
+					Message: fmt.Sprintf("value %q does not match pattern %q", s, meta.Pattern),
+					Field:   &meta,
+				}
+			}
+		}
+	}
+
+	if meta.Min != nil || meta.Max != nil {
+		if n, ok := numericValue(field); ok {
+			if meta.Min != nil && n < *meta.Min {
+				return &ValidationError{
+					Path:    fieldPath,
+					Message: fmt.Sprintf("value %v is below minimum %v", n, *meta.Min),
+					Field:   &meta,
+				}
+			}
+			if meta.Max != nil && n > *meta.Max {
+				return &ValidationError{
+					Path:    fieldPath,
+					Message: fmt.Sprintf("value %v is above maximum %v", n, *meta.Max),
+					Field:   &meta,
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// numericValue extracts v's value as a float64 for comparison against
+// Min/Max, reporting false for a non-numeric kind.
+func numericValue(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func validateStruct(value any, expected []FieldMetadata, path string, opts ValidationOptions) error {
 	if value == nil {
-		return fmt.Errorf("%s cannot be nil", fieldType)
+		return &ValidationError{Path: path, Message: "cannot be nil"}
 	}
 
 	v := reflect.ValueOf(value)
 	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return &ValidationError{Path: path, Message: "cannot be nil"}
+		}
 		v = v.Elem()
 	}
 
+	if v.Kind() == reflect.Map {
+		return validateMap(v, expected, path, opts)
+	}
+
 	if v.Kind() != reflect.Struct {
-		return fmt.Errorf("%s must be struct", fieldType)
+		return &ValidationError{Path: path, Message: "must be struct"}
 	}
 
 	for _, expectedField := range expected {
 		field := v.FieldByName(expectedField.GoFieldName)
+		fieldPath := path + "." + expectedField.Name
 
-		if !expectedField.Required {
-			continue
+		if verr := validateScalarConstraints(field, expectedField, fieldPath); verr != nil {
+			return verr
 		}
 
-		if !field.IsValid() || field.IsZero() {
-			return fmt.Errorf("required %s field '%s' cannot be empty", fieldType, expectedField.Name)
+		required := expectedField.Required
+		if expectedField.RequiredIf != nil {
+			met, err := requiredIfConditionMet(v, expected, expectedField.RequiredIf)
+			if err != nil {
+				return &ValidationError{Path: fieldPath, Message: err.Error(), Field: &expectedField}
+			}
+			required = required || met
 		}
 
-		// Nested object validation
+		if required {
+			if isRequiredFieldMissing(field, opts) {
+				message := "required field cannot be empty"
+				if !expectedField.Required && expectedField.RequiredIf != nil {
+					message = fmt.Sprintf("required because %s=%q but field is empty", expectedField.RequiredIf.Field, expectedField.RequiredIf.Value)
+				}
+				return &ValidationError{Path: fieldPath, Message: message, Field: &expectedField}
+			}
+			if expectedField.Type == FieldTypeArray && !opts.AllowEmptyRequiredArrays && !opts.RequiredAllowZero &&
+				field.Kind() == reflect.Slice && field.Len() == 0 {
+				return &ValidationError{Path: fieldPath, Message: "required array cannot be empty", Field: &expectedField}
+			}
+		} else if !field.IsValid() || field.IsZero() {
+			// Absent and optional - nothing to recurse into.
+			continue
+		}
+
+		// Nested object validation. An optional object that's actually
+		// present (the IsZero check above only skips wholly-absent ones)
+		// still has its own required subfields validated - "if you provide
+		// the address, it must be complete" - even though the Address
+		// field itself wasn't required. Dynamic (interface{}/any) fields are
+		// FieldTypeText, not FieldTypeObject (see inferFieldType), so they're
+		// checked above for required-ness and then bypass this recursion -
+		// there's no static shape behind an any value to validate against.
 		if expectedField.Type == FieldTypeObject {
-			err := validateStruct(field.Interface(), flatten(expectedField.Properties), fieldType+"."+expectedField.Name)
-			if err != nil {
+			if err := validateStruct(field.Interface(), flatten(expectedField.Properties, expectedField.PropertyOrder), fieldPath, opts); err != nil {
 				return err
 			}
 		}
+
+		// Array-element validation: an object element (e.g. a slice of
+		// structs) recurses into its own required subfields, while a
+		// scalar element (string/int/...) is checked against Item's own
+		// Pattern/Min/Max/Enum constraints instead - a pattern/min/max tag
+		// on the slice field describes each element, not the slice itself,
+		// per FieldMetadata.Pattern.
+		if expectedField.Type == FieldTypeArray && expectedField.Item != nil {
+			for i := 0; i < field.Len(); i++ {
+				elemPath := fmt.Sprintf("%s[%d]", fieldPath, i)
+				elem := field.Index(i)
+				if expectedField.Item.Type == FieldTypeObject {
+					if err := validateStruct(elem.Interface(), flatten(expectedField.Item.Properties, expectedField.Item.PropertyOrder), elemPath, opts); err != nil {
+						return err
+					}
+					continue
+				}
+				if verr := validateScalarConstraints(elem, *expectedField.Item, elemPath); verr != nil {
+					return verr
+				}
+			}
+		}
 	}
 
 	return nil
 }
 
-func flatten(m map[string]*FieldMetadata) []FieldMetadata {
-	result := []FieldMetadata{}
-	for _, v := range m {
-		result = append(result, *v)
+// validateMap is validateStruct's counterpart for a map-based signature -
+// FromLegacySignature produces a TypedSignature[map[string]any,
+// map[string]any], and a map has no struct fields for reflect to walk, so
+// required keys are checked directly against the map using
+// FieldMetadata.Name rather than FieldByName(GoFieldName). Nested
+// object/array recursion is intentionally not attempted here:
+// FromLegacySignature's FieldMetadata has no Properties/Item of its own
+// (the legacy Field it's built from carries no nested shape either), so
+// there's nothing to recurse into.
+func validateMap(v reflect.Value, expected []FieldMetadata, path string, opts ValidationOptions) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return &ValidationError{Path: path, Message: "map key must be string"}
+	}
+
+	for _, expectedField := range expected {
+		fieldPath := path + "." + expectedField.Name
+		fieldValue := v.MapIndex(reflect.ValueOf(expectedField.Name))
+
+		if len(expectedField.Enum) > 0 && fieldValue.IsValid() {
+			if s, ok := fieldValue.Interface().(string); ok && s != "" && !enumContains(expectedField.Enum, s) {
+				return &ValidationError{
+					Path:    fieldPath,
+					Message: fmt.Sprintf("has value %q not in allowed enum %v", s, expectedField.Enum),
+					Field:   &expectedField,
+				}
+			}
+		}
+
+		required := expectedField.Required
+		if expectedField.RequiredIf != nil {
+			met, err := requiredIfConditionMetInMap(v, expected, expectedField.RequiredIf)
+			if err != nil {
+				return &ValidationError{Path: fieldPath, Message: err.Error(), Field: &expectedField}
+			}
+			required = required || met
+		}
+
+		if required && isRequiredMapFieldMissing(fieldValue, opts) {
+			message := "required field cannot be empty"
+			if !expectedField.Required && expectedField.RequiredIf != nil {
+				message = fmt.Sprintf("required because %s=%q but field is empty", expectedField.RequiredIf.Field, expectedField.RequiredIf.Value)
+			}
+			return &ValidationError{Path: fieldPath, Message: message, Field: &expectedField}
+		}
+	}
+
+	return nil
+}
+
+// requiredIfConditionMetInMap is requiredIfConditionMet's map counterpart -
+// see validateMap for why a map needs its own lookup instead of reflecting
+// into struct fields.
+func requiredIfConditionMetInMap(v reflect.Value, expected []FieldMetadata, cond *RequiredIfCondition) (bool, error) {
+	for _, sibling := range expected {
+		if sibling.Name != cond.Field && sibling.GoFieldName != cond.Field {
+			continue
+		}
+		siblingValue := v.MapIndex(reflect.ValueOf(sibling.Name))
+		if isZeroMapValue(siblingValue) {
+			return false, nil
+		}
+		return fmt.Sprintf("%v", siblingValue.Interface()) == cond.Value, nil
+	}
+	return false, fmt.Errorf("requiredif references unknown field %q", cond.Field)
+}
+
+// isZeroMapValue reports whether a map[string]any entry should count as
+// absent: either the key wasn't present at all, or it was present holding
+// the zero value of whatever concrete type it boxes (e.g. "" or 0) -
+// unlike reflect.Value.IsZero on an interface, which only reports whether
+// the interface itself is nil and would treat a boxed "" as non-zero.
+func isZeroMapValue(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	return !v.IsValid() || v.IsZero()
+}
+
+// isZeroExemptKind reports whether k's zero value is indistinguishable from
+// "not provided" in a way that's inherent to the type, not just a matter of
+// strictness - a plain (non-pointer) bool or int has no representation of
+// "unset" at all, so required-ness can never reject its zero value without
+// also rejecting a legitimate false/0 input. A caller that genuinely needs
+// to tell "unset" apart from "set to false/0" should use a pointer field
+// instead: IsZero on a nil *bool/*int reports true (absent) while IsZero on
+// a non-nil one pointing at false/0 reports false (present), since IsZero
+// on a Kind() == Ptr value is just IsNil.
+func isZeroExemptKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// isRequiredFieldMissing reports whether a required struct field should be
+// rejected as absent - field.IsZero(), except bool/int fields are always
+// treated as present (isZeroExemptKind) and opts.RequiredAllowZero extends
+// that same treatment to every other scalar type.
+func isRequiredFieldMissing(field reflect.Value, opts ValidationOptions) bool {
+	if !field.IsValid() {
+		return true
+	}
+	if isZeroExemptKind(field.Kind()) || opts.RequiredAllowZero {
+		return false
+	}
+	return field.IsZero()
+}
+
+// isRequiredMapFieldMissing is isRequiredFieldMissing's map counterpart,
+// unwrapping the interface value MapIndex returns the same way
+// isZeroMapValue does before checking its concrete kind.
+func isRequiredMapFieldMissing(v reflect.Value, opts ValidationOptions) bool {
+	if !v.IsValid() {
+		return true
+	}
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return true
+	}
+	if isZeroExemptKind(v.Kind()) || opts.RequiredAllowZero {
+		return false
+	}
+	return v.IsZero()
+}
+
+// enumContains reports whether value appears in values.
+func enumContains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// flatten converts a Properties map into a slice, ordered by order when
+// given (the declaration order captured in PropertyOrder) so validation
+// errors are reported deterministically instead of in Go's randomized map
+// iteration order. Any keys missing from order (e.g. a hand-built
+// FieldMetadata with no PropertyOrder set) are appended afterward.
+func flatten(m map[string]*FieldMetadata, order []string) []FieldMetadata {
+	result := make([]FieldMetadata, 0, len(m))
+	seen := make(map[string]bool, len(order))
+	for _, name := range order {
+		if v, ok := m[name]; ok {
+			result = append(result, *v)
+			seen[name] = true
+		}
+	}
+	for name, v := range m {
+		if !seen[name] {
+			result = append(result, *v)
+		}
 	}
 	return result
 }
@@ -294,10 +1008,10 @@ type TypedSignature[TInput, TOutput any] interface {
 	GetOutputType() reflect.Type
 
 	// ValidateInput performs compile-time and runtime validation of input
-	ValidateInput(input TInput) error
+	ValidateInput(input TInput, opts ...ValidationOption) error
 
 	// ValidateOutput performs compile-time and runtime validation of output
-	ValidateOutput(output TOutput) error
+	ValidateOutput(output TOutput, opts ...ValidationOption) error
 
 	// GetFieldMetadata returns parsed struct tag metadata
 	GetFieldMetadata() SignatureMetadata
@@ -331,12 +1045,129 @@ func getReflectTypes[TInput, TOutput any]() (reflect.Type, reflect.Type) {
 	return inputType, outputType
 }
 
-// NewTypedSignature creates a new typed signature for the given input/output types.
+// NewTypedSignature creates a new typed signature for the given input/output
+// types. The reflection walk behind it (see parseStructFields) is memoized
+// per type via structFieldsCache, so calling this repeatedly for the same
+// TInput/TOutput - e.g. once per incoming request in a server - doesn't
+// re-parse struct tags every time. NewTypedSignatureCached additionally
+// caches the assembled TypedSignature itself, skipping even the
+// construction overhead around that parse.
 func NewTypedSignature[TInput, TOutput any]() TypedSignature[TInput, TOutput] {
 	inputType, outputType := getReflectTypes[TInput, TOutput]()
 	return createTypedSignatureImpl[TInput, TOutput](inputType, outputType)
 }
 
+// NewTypedSignatureStrict behaves like NewTypedSignature but rejects field
+// kinds that cannot be meaningfully serialized to a prompt - channels, funcs,
+// complex numbers, and interfaces - instead of silently falling back to
+// FieldTypeText the way inferFieldType's lenient default does. Use this when
+// schema fidelity matters more than backward-compatible leniency.
+func NewTypedSignatureStrict[TInput, TOutput any]() (TypedSignature[TInput, TOutput], error) {
+	inputType, outputType := getReflectTypes[TInput, TOutput]()
+	if err := validateFieldKinds(inputType); err != nil {
+		return nil, fmt.Errorf("input type: %w", err)
+	}
+	if err := validateFieldKinds(outputType); err != nil {
+		return nil, fmt.Errorf("output type: %w", err)
+	}
+	return createTypedSignatureImpl[TInput, TOutput](inputType, outputType), nil
+}
+
+// unsupportedReflectKind reports whether a kind has no sensible prompt
+// serialization (channels, funcs, complex numbers, interfaces, unsafe
+// pointers).
+func unsupportedReflectKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.Interface, reflect.UnsafePointer:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateFieldKinds walks a struct type's exported fields (recursing into
+// nested structs and slice/array elements) and returns an error naming the
+// first field whose kind cannot be serialized to a prompt.
+func validateFieldKinds(t reflect.Type) error {
+	if t == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		checkKind := ft.Kind()
+		checkType := ft
+		if checkKind == reflect.Slice || checkKind == reflect.Array {
+			checkType = ft.Elem()
+			for checkType.Kind() == reflect.Ptr {
+				checkType = checkType.Elem()
+			}
+			checkKind = checkType.Kind()
+		}
+
+		if unsupportedReflectKind(checkKind) {
+			return fmt.Errorf("field %q has unsupported type %s (kind %s): channels, funcs, complex numbers, and interfaces cannot be serialized to a prompt", sf.Name, checkType, checkKind)
+		}
+
+		if ft.Kind() == reflect.Struct {
+			if err := validateFieldKinds(ft); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// NewTypedSignatureFromInstances creates a TypedSignature from concrete sample
+// values rather than compile-time type parameters. This is useful when a
+// caller only has values in hand - e.g. dynamic/plugin scenarios where the
+// input/output types are interface{} at the call site and NewTypedSignature's
+// zero-value reflection would otherwise yield nil types. Both samples must be
+// non-nil, concrete (non-interface) values; nil pointers are rejected because
+// their pointee type cannot be recovered via reflect.TypeOf.
+func NewTypedSignatureFromInstances(inputSample, outputSample any) (TypedSignature[any, any], error) {
+	if inputSample == nil {
+		return nil, fmt.Errorf("input sample cannot be nil")
+	}
+	if outputSample == nil {
+		return nil, fmt.Errorf("output sample cannot be nil")
+	}
+
+	inputType := reflect.TypeOf(inputSample)
+	outputType := reflect.TypeOf(outputSample)
+
+	if inputType.Kind() == reflect.Ptr {
+		if reflect.ValueOf(inputSample).IsNil() {
+			return nil, fmt.Errorf("input sample cannot be a nil pointer")
+		}
+		inputType = inputType.Elem()
+	}
+	if outputType.Kind() == reflect.Ptr {
+		if reflect.ValueOf(outputSample).IsNil() {
+			return nil, fmt.Errorf("output sample cannot be a nil pointer")
+		}
+		outputType = outputType.Elem()
+	}
+
+	return createTypedSignatureImpl[any, any](inputType, outputType), nil
+}
+
 // Global cache for TypedSignature instances to improve performance.
 var typedSignatureCache sync.Map
 
@@ -381,12 +1212,12 @@ func (ts *typedSignatureImpl[TInput, TOutput]) GetOutputType() reflect.Type {
 	return ts.outputType
 }
 
-func (ts *typedSignatureImpl[TInput, TOutput]) ValidateInput(input TInput) error {
-	return validateStruct(input, ts.metadata.Inputs, "input")
+func (ts *typedSignatureImpl[TInput, TOutput]) ValidateInput(input TInput, opts ...ValidationOption) error {
+	return validateStruct(input, ts.metadata.Inputs, "input", newValidationOptions(opts))
 }
 
-func (ts *typedSignatureImpl[TInput, TOutput]) ValidateOutput(output TOutput) error {
-	return validateStruct(output, ts.metadata.Outputs, "output")
+func (ts *typedSignatureImpl[TInput, TOutput]) ValidateOutput(output TOutput, opts ...ValidationOption) error {
+	return validateStruct(output, ts.metadata.Outputs, "output", newValidationOptions(opts))
 }
 
 func (ts *typedSignatureImpl[TInput, TOutput]) GetFieldMetadata() SignatureMetadata {
@@ -402,6 +1233,7 @@ func (ts *typedSignatureImpl[TInput, TOutput]) ToLegacySignature() Signature {
 				Name:        field.Name,
 				Description: field.Description,
 				Type:        field.Type,
+				Sensitive:   field.Sensitive,
 			},
 		}
 	}
@@ -413,6 +1245,8 @@ func (ts *typedSignatureImpl[TInput, TOutput]) ToLegacySignature() Signature {
 				Name:        field.Name,
 				Description: field.Description,
 				Type:        field.Type,
+				Sensitive:   field.Sensitive,
+				Transient:   field.Transient,
 			},
 		}
 	}
@@ -455,18 +1289,20 @@ func parseFieldMetadata(field reflect.StructField, isInput bool) FieldMetadata {
 		Description: field.Name,    // Auto-generate description from field name
 	}
 
-	// Parse dspy struct tag: `dspy:"fieldname,required"` (optional overrides)
+	// Parse dspy struct tag: `dspy:"fieldname,required,transient"` (optional overrides)
 	if dspyTag := field.Tag.Get("dspy"); dspyTag != "" {
 		parts := strings.Split(dspyTag, ",")
 		if len(parts) > 0 && parts[0] != "" {
 			metadata.Name = parts[0] // Override the lowercase default
 		}
 
-		// Check for required flag
+		// Check for required/transient flags
 		for _, part := range parts[1:] {
 			switch strings.TrimSpace(part) {
 			case "required":
 				metadata.Required = true
+			case "transient":
+				metadata.Transient = true
 			}
 		}
 	}
@@ -489,7 +1325,7 @@ func parseFieldMetadata(field reflect.StructField, isInput bool) FieldMetadata {
 	}
 
 	// Determine field type based on Go type
-	metadata.Type = inferFieldType(field.Type)
+	metadata.Type, _ = inferFieldType(field.Type)
 
 	return metadata
 }
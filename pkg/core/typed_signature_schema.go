@@ -0,0 +1,86 @@
+package core
+
+import "github.com/darwishdev/dspy-go/pkg/utils"
+
+// InputSchema builds an OBJECT utils.TypeSchema describing m's input
+// struct, suitable for use as a function declaration's "parameters" value
+// (see geminiFunctionDeclaration.Parameters in pkg/llms/gemini.go) -
+// Gemini has no "parameters" field on generationConfig itself; parameters
+// are part of a function declaration passed alongside the prompt, separate
+// from the responseSchema/responseJsonSchema pair generationConfig uses to
+// shape output. Call ToJSONSchema on the result to get the
+// map[string]interface{} a function declaration expects.
+//
+// Unlike utils.BuildSchemaFromStruct, which reflects on a value's `json`
+// tags, this walks m.Inputs - already parsed from `dspy` tags, with
+// required-ness, enums, and nested object/array shape resolved - so the
+// generated schema matches a typed signature's declared contract exactly.
+func (m SignatureMetadata) InputSchema() *utils.TypeSchema {
+	return FieldMetadataToSchema(m.Inputs)
+}
+
+// FieldMetadataToSchema builds an OBJECT utils.TypeSchema whose properties
+// are fields, mirroring fieldsToTypeSchema in signature_openapi.go but
+// operating on FieldMetadata (typed signatures) instead of Field (the
+// legacy, untyped signature API).
+func FieldMetadataToSchema(fields []FieldMetadata) *utils.TypeSchema {
+	schema := &utils.TypeSchema{
+		Type:       string(utils.TypeObject),
+		Properties: make(map[string]*utils.TypeSchema, len(fields)),
+	}
+
+	for _, f := range fields {
+		schema.Properties[f.Name] = fieldMetadataToTypeSchema(f)
+		schema.PropertyOrdering = append(schema.PropertyOrdering, f.Name)
+		if f.Required {
+			schema.Required = append(schema.Required, f.Name)
+		}
+	}
+
+	return schema
+}
+
+var fieldMetadataSchemaTypes = map[FieldType]utils.Type{
+	FieldTypeInt:  utils.TypeInteger,
+	FieldTypeBool: utils.TypeBoolean,
+}
+
+func fieldMetadataToTypeSchema(f FieldMetadata) *utils.TypeSchema {
+	switch f.Type {
+	case FieldTypeObject:
+		return objectFieldMetadataToTypeSchema(f)
+	case FieldTypeArray:
+		var items *utils.TypeSchema
+		if f.Item != nil {
+			items = fieldMetadataToTypeSchema(*f.Item)
+		}
+		return &utils.TypeSchema{Type: string(utils.TypeArray), Items: items, Description: f.Description, Enum: f.Enum}
+	default:
+		if t, ok := fieldMetadataSchemaTypes[f.Type]; ok {
+			return &utils.TypeSchema{Type: string(t), Description: f.Description, Enum: f.Enum}
+		}
+		// FieldTypeText, FieldTypeString, FieldTypeImage, FieldTypeAudio all
+		// surface as a plain STRING - none of them have a richer schema
+		// representation worth modeling here.
+		return &utils.TypeSchema{Type: string(utils.TypeString), Description: f.Description, Enum: f.Enum}
+	}
+}
+
+func objectFieldMetadataToTypeSchema(f FieldMetadata) *utils.TypeSchema {
+	properties := flatten(f.Properties, f.PropertyOrder)
+	schema := &utils.TypeSchema{
+		Type:        string(utils.TypeObject),
+		Description: f.Description,
+		Properties:  make(map[string]*utils.TypeSchema, len(properties)),
+	}
+
+	for _, prop := range properties {
+		schema.Properties[prop.Name] = fieldMetadataToTypeSchema(prop)
+		schema.PropertyOrdering = append(schema.PropertyOrdering, prop.Name)
+		if prop.Required {
+			schema.Required = append(schema.Required, prop.Name)
+		}
+	}
+
+	return schema
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/darwishdev/dspy-go/pkg/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -35,6 +36,52 @@ func TestNewTypedSignature(t *testing.T) {
 	assert.Equal(t, reflect.TypeOf(TestOutputs{}), sig.GetOutputType())
 }
 
+func TestNewTypedSignatureFromInstances(t *testing.T) {
+	sig, err := NewTypedSignatureFromInstances(TestInputs{}, TestOutputs{})
+	require.NoError(t, err)
+	assert.Equal(t, reflect.TypeOf(TestInputs{}), sig.GetInputType())
+	assert.Equal(t, reflect.TypeOf(TestOutputs{}), sig.GetOutputType())
+
+	input := TestInputs{Question: "What is AI?", Context: "AI is artificial intelligence"}
+	assert.NoError(t, sig.ValidateInput(input))
+}
+
+func TestNewTypedSignatureFromInstancesWithPointers(t *testing.T) {
+	sig, err := NewTypedSignatureFromInstances(&TestInputs{}, &TestOutputs{})
+	require.NoError(t, err)
+	assert.Equal(t, reflect.TypeOf(TestInputs{}), sig.GetInputType())
+	assert.Equal(t, reflect.TypeOf(TestOutputs{}), sig.GetOutputType())
+}
+
+func TestNewTypedSignatureFromInstancesNil(t *testing.T) {
+	_, err := NewTypedSignatureFromInstances(nil, TestOutputs{})
+	assert.Error(t, err)
+
+	_, err = NewTypedSignatureFromInstances(TestInputs{}, nil)
+	assert.Error(t, err)
+
+	_, err = NewTypedSignatureFromInstances((*TestInputs)(nil), TestOutputs{})
+	assert.Error(t, err)
+}
+
+type TestUnsupportedKindInputs struct {
+	Question string
+	Callback func() `dspy:"callback"`
+}
+
+func TestNewTypedSignatureStrictRejectsUnsupportedKinds(t *testing.T) {
+	_, err := NewTypedSignatureStrict[TestUnsupportedKindInputs, TestOutputs]()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Callback")
+	assert.Contains(t, err.Error(), "func")
+}
+
+func TestNewTypedSignatureStrictAllowsSupportedKinds(t *testing.T) {
+	sig, err := NewTypedSignatureStrict[TestInputs, TestOutputs]()
+	require.NoError(t, err)
+	assert.NotNil(t, sig)
+}
+
 func TestStructTagParsing(t *testing.T) {
 	sig := NewTypedSignature[TestInputs, TestOutputs]()
 	metadata := sig.GetFieldMetadata()
@@ -88,6 +135,22 @@ func TestFieldTypeInference(t *testing.T) {
 	assert.Equal(t, FieldTypeText, countField.Type) // int inferred as text
 }
 
+type TestUintInputs struct {
+	Small  uint   `dspy:"small"`
+	Medium uint32 `dspy:"medium"`
+	Large  uint64 `dspy:"large"`
+}
+
+func TestFieldTypeInferenceUint(t *testing.T) {
+	sig := NewTypedSignature[TestUintInputs, TestOutputs]()
+	metadata := sig.GetFieldMetadata()
+
+	require.Len(t, metadata.Inputs, 3)
+	for _, field := range metadata.Inputs {
+		assert.Equal(t, FieldTypeInt, field.Type, "field %s should infer as FieldTypeInt", field.Name)
+	}
+}
+
 func TestInputValidation(t *testing.T) {
 	sig := NewTypedSignature[TestInputs, TestOutputs]()
 
@@ -114,7 +177,7 @@ func TestInputValidation(t *testing.T) {
 				Optional: "some value",
 			},
 			wantErr: true,
-			errMsg:  "required input field 'context' cannot be empty",
+			errMsg:  "input.context: required field cannot be empty",
 		},
 		{
 			name: "empty required field",
@@ -123,7 +186,7 @@ func TestInputValidation(t *testing.T) {
 				Context:  "AI is artificial intelligence",
 			},
 			wantErr: true,
-			errMsg:  "required input field 'question' cannot be empty",
+			errMsg:  "input.question: required field cannot be empty",
 		},
 		{
 			name: "missing optional field is ok",
@@ -164,6 +227,22 @@ func TestOutputValidation(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestOutputValidationPointer(t *testing.T) {
+	sig := NewTypedSignature[TestInputs, *TestOutputs]()
+
+	output := &TestOutputs{
+		Answer:     "AI is artificial intelligence",
+		Confidence: 85,
+	}
+
+	err := sig.ValidateOutput(output)
+	assert.NoError(t, err)
+
+	err = sig.ValidateOutput((*TestOutputs)(nil))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "output: cannot be nil")
+}
+
 func TestPointerTypes(t *testing.T) {
 	sig := NewTypedSignature[*TestInputs, *TestOutputs]()
 
@@ -186,7 +265,7 @@ func TestNilPointerValidation(t *testing.T) {
 	// Test nil pointer
 	err := sig.ValidateInput((*TestInputs)(nil))
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "input cannot be nil")
+	assert.Contains(t, err.Error(), "input: cannot be nil")
 }
 
 func TestToLegacySignature(t *testing.T) {
@@ -251,6 +330,21 @@ func TestFromLegacySignature(t *testing.T) {
 	assert.NoError(t, err) // Should pass since legacy fields are optional by default
 }
 
+func TestValidateInputMapBasedRequiredFieldPresentAndAbsent(t *testing.T) {
+	expected := []FieldMetadata{
+		{Name: "question", GoFieldName: "question", Required: true},
+	}
+
+	err := validateStruct(map[string]any{"question": "What is machine learning?"}, expected, "input", ValidationOptions{})
+	assert.NoError(t, err)
+
+	err = validateStruct(map[string]any{}, expected, "input", ValidationOptions{})
+	require.Error(t, err)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "input.question", verr.Path)
+}
+
 func TestWithInstruction(t *testing.T) {
 	sig := NewTypedSignature[TestInputs, TestOutputs]()
 	instruction := "Answer the question using the provided context"
@@ -270,6 +364,48 @@ func TestWithInstruction(t *testing.T) {
 	assert.Equal(t, instruction, legacy.Instruction)
 }
 
+// TestInputsWithInstructionMethod co-locates its prompt instruction via the
+// Instruction() string method form.
+type TestInputsWithInstructionMethod struct {
+	Question string `dspy:"question,required"`
+}
+
+func (TestInputsWithInstructionMethod) Instruction() string {
+	return "Answer concisely, citing sources where possible"
+}
+
+// TestInputsWithInstructionTag co-locates its prompt instruction via an
+// instruction tag on an unexported marker field.
+type TestInputsWithInstructionTag struct {
+	Question string   `dspy:"question,required"`
+	_        struct{} `instruction:"Answer in a single sentence"`
+}
+
+func TestStructInstructionMethodForm(t *testing.T) {
+	sig := NewTypedSignature[TestInputsWithInstructionMethod, TestOutputs]()
+	metadata := sig.GetFieldMetadata()
+	assert.Equal(t, "Answer concisely, citing sources where possible", metadata.Instruction)
+}
+
+func TestStructInstructionTagForm(t *testing.T) {
+	sig := NewTypedSignature[TestInputsWithInstructionTag, TestOutputs]()
+	metadata := sig.GetFieldMetadata()
+	assert.Equal(t, "Answer in a single sentence", metadata.Instruction)
+}
+
+func TestStructInstructionFallsBackToOutputType(t *testing.T) {
+	sig := NewTypedSignature[TestInputs, TestInputsWithInstructionMethod]()
+	metadata := sig.GetFieldMetadata()
+	assert.Equal(t, "Answer concisely, citing sources where possible", metadata.Instruction)
+}
+
+func TestStructInstructionExplicitWithInstructionOverrides(t *testing.T) {
+	sig := NewTypedSignature[TestInputsWithInstructionMethod, TestOutputs]()
+	modifiedSig := sig.WithInstruction("Override the struct-level instruction")
+	metadata := modifiedSig.GetFieldMetadata()
+	assert.Equal(t, "Override the struct-level instruction", metadata.Instruction)
+}
+
 // Benchmark tests to ensure performance is acceptable.
 
 func BenchmarkStructTagParsing(b *testing.B) {
@@ -293,6 +429,64 @@ func BenchmarkInputValidation(b *testing.B) {
 	}
 }
 
+// BenchmarkParseStructFieldsUncached and BenchmarkParseStructFieldsCached
+// justify structFieldsCache: the former clears it on every iteration to
+// force a full reflection walk, as if the cache didn't exist, while the
+// latter warms it once and then measures only cache-hit overhead.
+func BenchmarkParseStructFieldsUncached(b *testing.B) {
+	typ := reflect.TypeOf(TestInputs{})
+
+	for i := 0; i < b.N; i++ {
+		structFieldsCache = sync.Map{}
+		var diags []Diagnostic
+		_ = parseStructFields(typ, true, "input", &diags)
+	}
+}
+
+func BenchmarkParseStructFieldsCached(b *testing.B) {
+	typ := reflect.TypeOf(TestInputs{})
+	var diags []Diagnostic
+	_ = parseStructFields(typ, true, "input", &diags) // warm the cache
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		diags = nil
+		_ = parseStructFields(typ, true, "input", &diags)
+	}
+}
+
+func TestParseStructFieldsCacheReturnsEquivalentFieldsOnHit(t *testing.T) {
+	structFieldsCache = sync.Map{}
+	typ := reflect.TypeOf(TestInputs{})
+
+	var diags1 []Diagnostic
+	first := parseStructFields(typ, true, "input", &diags1)
+
+	var diags2 []Diagnostic
+	second := parseStructFields(typ, true, "input", &diags2)
+
+	require.Equal(t, first, second)
+}
+
+func TestParseStructFieldsCacheIsKeyedByNamingStrategy(t *testing.T) {
+	structFieldsCache = sync.Map{}
+	orig := GlobalConfig.FieldNamingStrategy
+	defer func() { GlobalConfig.FieldNamingStrategy = orig }()
+
+	typ := reflect.TypeOf(fieldNamingTestInput{})
+
+	GlobalConfig.FieldNamingStrategy = FieldNamingSnakeCase
+	var snakeDiags []Diagnostic
+	snakeFields := parseStructFields(typ, true, "input", &snakeDiags)
+
+	GlobalConfig.FieldNamingStrategy = FieldNamingCamelCase
+	var camelDiags []Diagnostic
+	camelFields := parseStructFields(typ, true, "input", &camelDiags)
+
+	require.Equal(t, "first_name", snakeFields[0].Name)
+	require.Equal(t, "firstName", camelFields[0].Name)
+}
+
 func TestNewTypedSignatureCached(t *testing.T) {
 	// Test that caching returns the same instance for the same types
 	sig1 := NewTypedSignatureCached[TestInputs, TestOutputs]()
@@ -354,20 +548,20 @@ func TestZeroConfigStructs(t *testing.T) {
 func TestZeroConfigWithMinimalOverrides(t *testing.T) {
 	// Test Level 1: minimal overrides when needed
 	type InputWithOverrides struct {
-		Question string `dspy:",required"`     // Just mark as required
-		Context  string                       // Uses zero-config defaults
+		Question string `dspy:",required"` // Just mark as required
+		Context  string // Uses zero-config defaults
 	}
 
 	type OutputWithOverrides struct {
-		Answer string `dspy:"final_answer"`   // Custom field name only
+		Answer string `dspy:"final_answer"` // Custom field name only
 	}
 
 	sig := NewTypedSignature[InputWithOverrides, OutputWithOverrides]()
 	metadata := sig.GetFieldMetadata()
 
 	// Verify required override works
-	assert.True(t, metadata.Inputs[0].Required)   // Question is required
-	assert.False(t, metadata.Inputs[1].Required)  // Context uses default (optional)
+	assert.True(t, metadata.Inputs[0].Required)  // Question is required
+	assert.False(t, metadata.Inputs[1].Required) // Context uses default (optional)
 
 	// Verify field name override works
 	assert.Equal(t, "question", metadata.Inputs[0].Name)      // Uses lowercase default
@@ -425,3 +619,725 @@ func TestNewTypedSignatureCachedConcurrency(t *testing.T) {
 	assert.Len(t, metadata.Inputs, 3)
 	assert.Len(t, metadata.Outputs, 2)
 }
+
+type fieldNamingTestInput struct {
+	FirstName string
+	UserID    string
+}
+
+type fieldNamingTestOutput struct {
+	ResponseText string
+}
+
+func TestFieldNamingStrategyLowercaseDefault(t *testing.T) {
+	orig := GlobalConfig.FieldNamingStrategy
+	defer func() { GlobalConfig.FieldNamingStrategy = orig }()
+	GlobalConfig.FieldNamingStrategy = FieldNamingLowercase
+
+	sig := NewTypedSignature[fieldNamingTestInput, fieldNamingTestOutput]()
+	metadata := sig.GetFieldMetadata()
+
+	assert.Equal(t, "firstname", metadata.Inputs[0].Name)
+	assert.Equal(t, "userid", metadata.Inputs[1].Name)
+}
+
+func TestFieldNamingStrategySnakeCase(t *testing.T) {
+	orig := GlobalConfig.FieldNamingStrategy
+	defer func() { GlobalConfig.FieldNamingStrategy = orig }()
+	GlobalConfig.FieldNamingStrategy = FieldNamingSnakeCase
+
+	sig := NewTypedSignature[fieldNamingTestInput, fieldNamingTestOutput]()
+	metadata := sig.GetFieldMetadata()
+
+	assert.Equal(t, "first_name", metadata.Inputs[0].Name)
+	assert.Equal(t, "user_id", metadata.Inputs[1].Name)
+}
+
+func TestFieldNamingStrategyCamelCase(t *testing.T) {
+	orig := GlobalConfig.FieldNamingStrategy
+	defer func() { GlobalConfig.FieldNamingStrategy = orig }()
+	GlobalConfig.FieldNamingStrategy = FieldNamingCamelCase
+
+	sig := NewTypedSignature[fieldNamingTestInput, fieldNamingTestOutput]()
+	metadata := sig.GetFieldMetadata()
+
+	assert.Equal(t, "firstName", metadata.Inputs[0].Name)
+	assert.Equal(t, "userID", metadata.Inputs[1].Name)
+}
+
+func TestFieldNamingStrategyAsIs(t *testing.T) {
+	orig := GlobalConfig.FieldNamingStrategy
+	defer func() { GlobalConfig.FieldNamingStrategy = orig }()
+	GlobalConfig.FieldNamingStrategy = FieldNamingAsIs
+
+	sig := NewTypedSignature[fieldNamingTestInput, fieldNamingTestOutput]()
+	metadata := sig.GetFieldMetadata()
+
+	assert.Equal(t, "FirstName", metadata.Inputs[0].Name)
+	assert.Equal(t, "UserID", metadata.Inputs[1].Name)
+}
+
+func TestFieldNamingStrategyExplicitTagOverrides(t *testing.T) {
+	orig := GlobalConfig.FieldNamingStrategy
+	defer func() { GlobalConfig.FieldNamingStrategy = orig }()
+	GlobalConfig.FieldNamingStrategy = FieldNamingSnakeCase
+
+	type explicitNameInput struct {
+		FirstName string `dspy:"given_name"`
+	}
+
+	sig := NewTypedSignature[explicitNameInput, fieldNamingTestOutput]()
+	metadata := sig.GetFieldMetadata()
+
+	assert.Equal(t, "given_name", metadata.Inputs[0].Name)
+}
+
+type transientOutputFields struct {
+	Reasoning string `dspy:",transient"`
+	Answer    string `dspy:"answer"`
+}
+
+func TestFieldMetadataTransientTag(t *testing.T) {
+	sig := NewTypedSignature[fieldNamingTestInput, transientOutputFields]()
+	metadata := sig.GetFieldMetadata()
+
+	var reasoning, answer *FieldMetadata
+	for i := range metadata.Outputs {
+		switch metadata.Outputs[i].GoFieldName {
+		case "Reasoning":
+			reasoning = &metadata.Outputs[i]
+		case "Answer":
+			answer = &metadata.Outputs[i]
+		}
+	}
+	require.NotNil(t, reasoning)
+	require.NotNil(t, answer)
+	assert.True(t, reasoning.Transient)
+	assert.False(t, answer.Transient)
+}
+
+func TestToLegacySignaturePropagatesTransient(t *testing.T) {
+	sig := NewTypedSignature[fieldNamingTestInput, transientOutputFields]()
+	legacy := sig.ToLegacySignature()
+
+	var reasoning *OutputField
+	for i := range legacy.Outputs {
+		if legacy.Outputs[i].Name == "reasoning" {
+			reasoning = &legacy.Outputs[i]
+		}
+	}
+	require.NotNil(t, reasoning)
+	assert.True(t, reasoning.Transient)
+}
+
+type anyFieldInputs struct {
+	Question string      `dspy:"question,required"`
+	Metadata interface{} `dspy:"metadata,required"`
+}
+
+func TestAnyFieldInferredAsTextAndHonorsRequired(t *testing.T) {
+	sig := NewTypedSignature[anyFieldInputs, TestOutputs]()
+	metadata := sig.GetFieldMetadata()
+
+	var metadataField *FieldMetadata
+	for i := range metadata.Inputs {
+		if metadata.Inputs[i].GoFieldName == "Metadata" {
+			metadataField = &metadata.Inputs[i]
+		}
+	}
+	require.NotNil(t, metadataField)
+	assert.Equal(t, FieldTypeText, metadataField.Type)
+	assert.True(t, metadataField.Required)
+
+	err := sig.ValidateInput(anyFieldInputs{Question: "q"})
+	assert.Error(t, err, "required any field left nil should fail validation")
+	assert.Contains(t, err.Error(), "metadata")
+
+	err = sig.ValidateInput(anyFieldInputs{Question: "q", Metadata: map[string]any{"k": "v"}})
+	assert.NoError(t, err, "required any field bypasses nested validation once populated")
+}
+
+func TestToSnakeCaseAcronymBoundaries(t *testing.T) {
+	cases := map[string]string{
+		"UserID":      "user_id",
+		"ID":          "id",
+		"URL":         "url",
+		"URLPath":     "url_path",
+		"UserURLPath": "user_url_path",
+		"FirstName":   "first_name",
+		"HTTPServer2": "http_server2",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, toSnakeCase(in), "toSnakeCase(%q)", in)
+	}
+}
+
+func TestToSnakeCaseAccentedCharacters(t *testing.T) {
+	assert.Equal(t, "école_name", toSnakeCase("ÉcoleName"))
+	assert.Equal(t, "naïve_user", toSnakeCase("NaïveUser"))
+}
+
+type nestedArrayStructItem struct {
+	Value string
+}
+
+type nestedArrayTestInputs struct {
+	Matrix [][]string
+	Grid   [][]nestedArrayStructItem
+}
+
+func TestFieldMetadataNestedSliceOfSlice(t *testing.T) {
+	sig := NewTypedSignature[nestedArrayTestInputs, TestOutputs]()
+	metadata := sig.GetFieldMetadata()
+
+	var matrix *FieldMetadata
+	for i := range metadata.Inputs {
+		if metadata.Inputs[i].GoFieldName == "Matrix" {
+			matrix = &metadata.Inputs[i]
+		}
+	}
+	require.NotNil(t, matrix)
+	assert.Equal(t, FieldTypeArray, matrix.Type)
+	require.NotNil(t, matrix.Item)
+	assert.Equal(t, FieldTypeArray, matrix.Item.Type)
+	require.NotNil(t, matrix.Item.Item)
+	assert.Equal(t, FieldTypeString, matrix.Item.Item.Type)
+}
+
+type validationPathAddress struct {
+	City string `dspy:"city,required"`
+	Zip  string `dspy:"zip,required"`
+}
+
+type validationPathUser struct {
+	Name    string                `dspy:"name,required"`
+	Address validationPathAddress `dspy:"address,required"`
+}
+
+type validationPathInputs struct {
+	User validationPathUser `dspy:"user,required"`
+}
+
+func TestValidateInputNestedObjectErrorPath(t *testing.T) {
+	sig := NewTypedSignature[validationPathInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathInputs{
+		User: validationPathUser{
+			Name:    "Ada",
+			Address: validationPathAddress{City: "Paris"}, // Zip left empty
+		},
+	})
+
+	require.Error(t, err)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "input.user.address.zip", verr.Path)
+}
+
+type validationPathItem struct {
+	Name string `dspy:"name,required"`
+}
+
+type validationPathArrayInputs struct {
+	Items []validationPathItem `dspy:"items,required"`
+}
+
+func TestValidateInputArrayElementErrorPath(t *testing.T) {
+	sig := NewTypedSignature[validationPathArrayInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathArrayInputs{
+		Items: []validationPathItem{
+			{Name: "first"},
+			{Name: "second"},
+			{}, // Name left empty
+		},
+	})
+
+	require.Error(t, err)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "input.items[2].name", verr.Path)
+}
+
+func TestValidateInputRequiredArrayNilFails(t *testing.T) {
+	sig := NewTypedSignature[validationPathArrayInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathArrayInputs{Items: nil})
+
+	require.Error(t, err)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "input.items", verr.Path)
+}
+
+func TestValidateInputRequiredArrayEmptyFailsByDefault(t *testing.T) {
+	sig := NewTypedSignature[validationPathArrayInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathArrayInputs{Items: []validationPathItem{}})
+
+	require.Error(t, err)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "input.items", verr.Path)
+}
+
+func TestValidateInputRequiredArrayEmptyPassesWithAllowEmptyOption(t *testing.T) {
+	sig := NewTypedSignature[validationPathArrayInputs, TestOutputs]()
+
+	err := sig.ValidateInput(
+		validationPathArrayInputs{Items: []validationPathItem{}},
+		WithAllowEmptyRequiredArrays(),
+	)
+
+	assert.NoError(t, err)
+}
+
+func TestValidateInputRequiredArrayPopulatedPasses(t *testing.T) {
+	sig := NewTypedSignature[validationPathArrayInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathArrayInputs{
+		Items: []validationPathItem{{Name: "first"}},
+	})
+
+	assert.NoError(t, err)
+}
+
+type validationPathTaggedArrayInputs struct {
+	Tags   []string `dspy:"tags" pattern:"^[a-z]+$"`
+	Scores []int    `dspy:"scores" min:"0" max:"100"`
+}
+
+func TestValidateInputArrayElementPatternFailsOnMiddleElement(t *testing.T) {
+	sig := NewTypedSignature[validationPathTaggedArrayInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathTaggedArrayInputs{
+		Tags: []string{"ok", "Not-OK", "alsook"},
+	})
+
+	require.Error(t, err)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "input.tags[1]", verr.Path)
+	assert.Contains(t, verr.Message, "does not match pattern")
+}
+
+func TestValidateInputArrayElementPatternAllValidPasses(t *testing.T) {
+	sig := NewTypedSignature[validationPathTaggedArrayInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathTaggedArrayInputs{
+		Tags: []string{"ok", "fine", "alsook"},
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestValidateInputArrayElementMaxFailsOnMiddleElement(t *testing.T) {
+	sig := NewTypedSignature[validationPathTaggedArrayInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathTaggedArrayInputs{
+		Scores: []int{10, 150, 50},
+	})
+
+	require.Error(t, err)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "input.scores[1]", verr.Path)
+	assert.Contains(t, verr.Message, "above maximum")
+}
+
+func TestValidateInputArrayElementMinMaxWithinRangePasses(t *testing.T) {
+	sig := NewTypedSignature[validationPathTaggedArrayInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathTaggedArrayInputs{
+		Scores: []int{0, 50, 100},
+	})
+
+	assert.NoError(t, err)
+}
+
+type validationPathContactInputs struct {
+	ContactMethod string `dspy:"contactMethod"`
+	Phone         string `dspy:"phone" requiredif:"contactMethod=phone"`
+}
+
+func TestValidateInputRequiredIfConditionMetAndFieldEmptyFails(t *testing.T) {
+	sig := NewTypedSignature[validationPathContactInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathContactInputs{ContactMethod: "phone"})
+
+	require.Error(t, err)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "input.phone", verr.Path)
+	assert.Contains(t, verr.Message, "contactMethod=\"phone\"")
+}
+
+func TestValidateInputRequiredIfConditionNotMetPasses(t *testing.T) {
+	sig := NewTypedSignature[validationPathContactInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathContactInputs{ContactMethod: "email"})
+
+	assert.NoError(t, err)
+}
+
+func TestValidateInputRequiredIfConditionMetAndFieldPopulatedPasses(t *testing.T) {
+	sig := NewTypedSignature[validationPathContactInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathContactInputs{
+		ContactMethod: "phone",
+		Phone:         "555-0100",
+	})
+
+	assert.NoError(t, err)
+}
+
+type validationPathScalarInputs struct {
+	Active bool   `dspy:"active,required"`
+	Count  int    `dspy:"count,required"`
+	Label  string `dspy:"label,required"`
+}
+
+func TestValidateInputRequiredBoolFalsePasses(t *testing.T) {
+	sig := NewTypedSignature[validationPathScalarInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathScalarInputs{Active: false, Count: 1, Label: "x"})
+
+	assert.NoError(t, err)
+}
+
+func TestValidateInputRequiredIntZeroPasses(t *testing.T) {
+	sig := NewTypedSignature[validationPathScalarInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathScalarInputs{Active: true, Count: 0, Label: "x"})
+
+	assert.NoError(t, err)
+}
+
+func TestValidateInputRequiredStringEmptyStillFailsByDefault(t *testing.T) {
+	sig := NewTypedSignature[validationPathScalarInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathScalarInputs{Active: true, Count: 1, Label: ""})
+
+	require.Error(t, err)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "input.label", verr.Path)
+}
+
+func TestValidateInputRequiredStringEmptyPassesWithRequiredAllowZero(t *testing.T) {
+	sig := NewTypedSignature[validationPathScalarInputs, TestOutputs]()
+
+	err := sig.ValidateInput(
+		validationPathScalarInputs{Active: true, Count: 1, Label: ""},
+		WithRequiredAllowZero(),
+	)
+
+	assert.NoError(t, err)
+}
+
+func TestValidateInputMapBasedRequiredBoolFalseAndIntZeroPass(t *testing.T) {
+	expected := []FieldMetadata{
+		{Name: "active", GoFieldName: "Active", Required: true, Type: FieldTypeBool},
+		{Name: "count", GoFieldName: "Count", Required: true, Type: FieldTypeInt},
+	}
+
+	err := validateStruct(map[string]any{"active": false, "count": 0}, expected, "input", ValidationOptions{})
+
+	assert.NoError(t, err)
+}
+
+func TestValidateInputErrorExposesFieldMetadata(t *testing.T) {
+	sig := NewTypedSignature[validationPathInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationPathInputs{
+		User: validationPathUser{
+			Name:    "Ada",
+			Address: validationPathAddress{City: "Paris"}, // Zip left empty
+		},
+	})
+
+	require.Error(t, err)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.NotNil(t, verr.Field)
+	assert.Equal(t, FieldTypeString, verr.Field.Type)
+	assert.True(t, verr.Field.Required)
+	assert.Equal(t, "zip", verr.Field.Name)
+}
+
+type validationOptionalObjectInputs struct {
+	Name    string                 `dspy:"name,required"`
+	Address *validationPathAddress `dspy:"address"`
+}
+
+func TestValidateInputOptionalObjectPresentButIncompleteFails(t *testing.T) {
+	sig := NewTypedSignature[validationOptionalObjectInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationOptionalObjectInputs{
+		Name:    "Ada",
+		Address: &validationPathAddress{City: "Paris"}, // Zip left empty
+	})
+
+	require.Error(t, err)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "input.address.zip", verr.Path)
+}
+
+func TestValidateInputOptionalObjectAbsentIsSkipped(t *testing.T) {
+	sig := NewTypedSignature[validationOptionalObjectInputs, TestOutputs]()
+
+	err := sig.ValidateInput(validationOptionalObjectInputs{Name: "Ada"})
+
+	assert.NoError(t, err)
+}
+
+func TestFieldMetadataNestedSliceOfStructSlice(t *testing.T) {
+	sig := NewTypedSignature[nestedArrayTestInputs, TestOutputs]()
+	metadata := sig.GetFieldMetadata()
+
+	var grid *FieldMetadata
+	for i := range metadata.Inputs {
+		if metadata.Inputs[i].GoFieldName == "Grid" {
+			grid = &metadata.Inputs[i]
+		}
+	}
+	require.NotNil(t, grid)
+	assert.Equal(t, FieldTypeArray, grid.Type)
+	require.NotNil(t, grid.Item)
+	assert.Equal(t, FieldTypeArray, grid.Item.Type)
+	require.NotNil(t, grid.Item.Item)
+	assert.Equal(t, FieldTypeObject, grid.Item.Item.Type)
+	assert.Contains(t, grid.Item.Item.Properties, "value")
+}
+
+type orderedPropsAddress struct {
+	Zip    string
+	Street string
+	City   string
+}
+
+type orderedPropsInputs struct {
+	Address orderedPropsAddress
+}
+
+func TestFieldMetadataPropertyOrderMatchesDeclarationOrder(t *testing.T) {
+	expected := []string{"zip", "street", "city"}
+
+	for i := 0; i < 10; i++ {
+		sig := NewTypedSignature[orderedPropsInputs, TestOutputs]()
+		metadata := sig.GetFieldMetadata()
+
+		var address *FieldMetadata
+		for j := range metadata.Inputs {
+			if metadata.Inputs[j].GoFieldName == "Address" {
+				address = &metadata.Inputs[j]
+			}
+		}
+		require.NotNil(t, address)
+		assert.Equal(t, expected, address.PropertyOrder)
+	}
+}
+
+// enumTestStatus is a named string type registered with utils.RegisterEnum
+// below, so fields of this type get an automatic schema/validation Enum
+// with no struct tag needed.
+type enumTestStatus string
+
+const (
+	enumTestStatusOpen   enumTestStatus = "open"
+	enumTestStatusClosed enumTestStatus = "closed"
+)
+
+func init() {
+	utils.RegisterEnum(enumTestStatusOpen, enumTestStatusClosed)
+}
+
+// enumTestUnregistered is a named string type that is never passed to
+// RegisterEnum, used to confirm the fallback to a plain, unconstrained
+// string.
+type enumTestUnregistered string
+
+type enumTestInput struct {
+	Status enumTestStatus       `dspy:"status,required"`
+	Kind   enumTestUnregistered `dspy:"kind"`
+}
+
+func TestFieldMetadataRegisteredEnumPopulatesEnumValues(t *testing.T) {
+	sig := NewTypedSignature[enumTestInput, TestOutputs]()
+	metadata := sig.GetFieldMetadata()
+
+	var status, kind *FieldMetadata
+	for i := range metadata.Inputs {
+		switch metadata.Inputs[i].GoFieldName {
+		case "Status":
+			status = &metadata.Inputs[i]
+		case "Kind":
+			kind = &metadata.Inputs[i]
+		}
+	}
+	require.NotNil(t, status)
+	require.NotNil(t, kind)
+	assert.Equal(t, []string{"open", "closed"}, status.Enum)
+	assert.Empty(t, kind.Enum, "an unregistered named string type should fall back to a plain string with no Enum")
+}
+
+func TestValidateInputRejectsValueOutsideRegisteredEnum(t *testing.T) {
+	sig := NewTypedSignature[enumTestInput, TestOutputs]()
+
+	err := sig.ValidateInput(enumTestInput{Status: enumTestStatusOpen})
+	assert.NoError(t, err)
+
+	err = sig.ValidateInput(enumTestInput{Status: enumTestStatus("archived")})
+	assert.Error(t, err)
+}
+
+func TestFlattenOrdersByPropertyOrder(t *testing.T) {
+	props := map[string]*FieldMetadata{
+		"zip":    {Name: "zip", GoFieldName: "Zip"},
+		"street": {Name: "street", GoFieldName: "Street"},
+		"city":   {Name: "city", GoFieldName: "City"},
+	}
+	order := []string{"zip", "street", "city"}
+
+	for i := 0; i < 10; i++ {
+		result := flatten(props, order)
+		require.Len(t, result, 3)
+		assert.Equal(t, "zip", result[0].Name)
+		assert.Equal(t, "street", result[1].Name)
+		assert.Equal(t, "city", result[2].Name)
+	}
+}
+
+func TestCoerceToOutputConvertsFloat64ToInt(t *testing.T) {
+	sig := NewTypedSignature[TestComplexInputs, TestOutputs]()
+
+	out, err := CoerceToOutput(map[string]any{
+		"answer":     "42",
+		"confidence": float64(95), // json.Unmarshal decodes all numbers as float64
+	}, sig)
+
+	require.NoError(t, err)
+	assert.Equal(t, "42", out.Answer)
+	assert.Equal(t, 95, out.Confidence)
+}
+
+type coerceOutputAddress struct {
+	City string `dspy:"city"`
+	Zip  string `dspy:"zip"`
+}
+
+type coerceOutputUser struct {
+	Name    string              `dspy:"name"`
+	Address coerceOutputAddress `dspy:"address"`
+}
+
+type coerceOutputOutputs struct {
+	User coerceOutputUser `dspy:"user"`
+}
+
+func TestCoerceToOutputConvertsNestedObject(t *testing.T) {
+	sig := NewTypedSignature[TestComplexInputs, coerceOutputOutputs]()
+
+	out, err := CoerceToOutput(map[string]any{
+		"user": map[string]any{
+			"name": "Ada",
+			"address": map[string]any{
+				"city": "Paris",
+				"zip":  "75001",
+			},
+		},
+	}, sig)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Ada", out.User.Name)
+	assert.Equal(t, "Paris", out.User.Address.City)
+	assert.Equal(t, "75001", out.User.Address.Zip)
+}
+
+func TestCoerceToOutputConvertsArrayOfObjects(t *testing.T) {
+	sig := NewTypedSignature[TestComplexInputs, validationPathArrayInputs]()
+
+	out, err := CoerceToOutput(map[string]any{
+		"items": []any{
+			map[string]any{"name": "first"},
+			map[string]any{"name": "second"},
+		},
+	}, sig)
+
+	require.NoError(t, err)
+	require.Len(t, out.Items, 2)
+	assert.Equal(t, "first", out.Items[0].Name)
+	assert.Equal(t, "second", out.Items[1].Name)
+}
+
+func TestCoerceToOutputReturnsFieldPathOnTypeMismatch(t *testing.T) {
+	sig := NewTypedSignature[TestComplexInputs, coerceOutputOutputs]()
+
+	_, err := CoerceToOutput(map[string]any{
+		"user": "not an object",
+	}, sig)
+
+	require.Error(t, err)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "output.user", verr.Path)
+}
+
+func TestCoerceToOutputReturnsFieldPathOnArrayElementTypeMismatch(t *testing.T) {
+	sig := NewTypedSignature[TestComplexInputs, validationPathArrayInputs]()
+
+	_, err := CoerceToOutput(map[string]any{
+		"items": []any{
+			map[string]any{"name": "first"},
+			"not an object",
+		},
+	}, sig)
+
+	require.Error(t, err)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "output.items[1]", verr.Path)
+}
+
+type diagnosticsUnsupportedInputs struct {
+	Question string     `dspy:"question,required"`
+	Weight   complex128 `dspy:"weight"`
+}
+
+func TestSignatureMetadataDiagnosticsFlagsUnsupportedFieldType(t *testing.T) {
+	sig := NewTypedSignature[diagnosticsUnsupportedInputs, TestOutputs]()
+
+	diagnostics := sig.GetFieldMetadata().Diagnostics()
+
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, "input.weight", diagnostics[0].Path)
+	assert.Contains(t, diagnostics[0].Message, "complex128")
+	assert.Contains(t, diagnostics[0].Message, "defaulted to text")
+}
+
+func TestSignatureMetadataDiagnosticsEmptyForSupportedFields(t *testing.T) {
+	sig := NewTypedSignature[TestInputs, TestOutputs]()
+
+	assert.Empty(t, sig.GetFieldMetadata().Diagnostics())
+}
+
+func TestInputSchemaPopulatedFromStruct(t *testing.T) {
+	sig := NewTypedSignature[validationPathInputs, TestOutputs]()
+
+	schema := sig.GetFieldMetadata().InputSchema()
+
+	require.Equal(t, "OBJECT", schema.Type)
+	require.Equal(t, []string{"user"}, schema.Required)
+
+	user := schema.Properties["user"]
+	require.NotNil(t, user)
+	assert.Equal(t, "OBJECT", user.Type)
+	assert.ElementsMatch(t, []string{"name", "address"}, user.Required)
+
+	address := user.Properties["address"]
+	require.NotNil(t, address)
+	assert.Equal(t, "OBJECT", address.Type)
+	assert.ElementsMatch(t, []string{"city", "zip"}, address.Required)
+	assert.Equal(t, "STRING", address.Properties["city"].Type)
+}
@@ -0,0 +1,113 @@
+package core
+
+import "sort"
+
+// UIFieldType is the type vocabulary used by UIFormField, chosen to map
+// directly onto HTML input types/common form-builder schemas rather than
+// FieldType's prompt-rendering vocabulary.
+type UIFieldType string
+
+const (
+	UIFieldTypeText    UIFieldType = "text"
+	UIFieldTypeNumber  UIFieldType = "number"
+	UIFieldTypeBoolean UIFieldType = "boolean"
+	UIFieldTypeImage   UIFieldType = "image"
+	UIFieldTypeAudio   UIFieldType = "audio"
+	UIFieldTypeArray   UIFieldType = "array"
+	UIFieldTypeObject  UIFieldType = "object"
+)
+
+// UIFormField describes one form field derived from a Signature input, for
+// front ends that render an HTML form from the same Signature driving the
+// LLM call. Nested objects become Fields (a field group); arrays carry a
+// single Items schema describing each element.
+type UIFormField struct {
+	Name     string        `json:"name"`
+	Label    string        `json:"label"`
+	Type     UIFieldType   `json:"type"`
+	Required bool          `json:"required"`
+	Enum     []string      `json:"enum,omitempty"`
+	Min      *float64      `json:"min,omitempty"`
+	Max      *float64      `json:"max,omitempty"`
+	Pattern  string        `json:"pattern,omitempty"`
+	Items    *UIFormField  `json:"items,omitempty"`
+	Fields   []UIFormField `json:"fields,omitempty"`
+}
+
+// uiFieldTypes maps FieldType to the UIFieldType a front end should render
+// it as. FieldTypeString and FieldTypeInt both render as UIFieldTypeText
+// and UIFieldTypeNumber respectively; anything absent from this map (e.g.
+// FieldTypeArray, FieldTypeObject) is handled structurally in fieldToUIFormField
+// instead, since it needs Items/Fields rather than a flat Type.
+var uiFieldTypes = map[FieldType]UIFieldType{
+	FieldTypeText:   UIFieldTypeText,
+	FieldTypeString: UIFieldTypeText,
+	FieldTypeInt:    UIFieldTypeNumber,
+	FieldTypeBool:   UIFieldTypeBoolean,
+	FieldTypeImage:  UIFieldTypeImage,
+	FieldTypeAudio:  UIFieldTypeAudio,
+}
+
+// UIFormSchema turns s's inputs into a UI-schema-ish description suitable
+// for generating an HTML form: field name, a label derived from the
+// field's description (falling back to its name), type, required, and any
+// enum/min/max/pattern constraints already recorded on the field (see
+// WithEnum, WithMin, WithMax, WithPattern). Nested objects (FieldTypeObject)
+// become field groups via UIFormField.Fields; the same Signature keeps
+// driving both the LLM call and the human-facing form.
+func (s Signature) UIFormSchema() []UIFormField {
+	fields := make([]UIFormField, 0, len(s.Inputs))
+	for _, input := range s.Inputs {
+		fields = append(fields, fieldToUIFormField(input.Field))
+	}
+	return fields
+}
+
+func fieldToUIFormField(f Field) UIFormField {
+	uiField := UIFormField{
+		Name:     f.Name,
+		Label:    f.Description,
+		Required: !f.Optional,
+		Enum:     f.Enum,
+		Min:      f.Min,
+		Max:      f.Max,
+		Pattern:  f.Pattern,
+	}
+	if uiField.Label == "" {
+		uiField.Label = f.Name
+	}
+
+	switch f.Type {
+	case FieldTypeArray:
+		uiField.Type = UIFieldTypeArray
+		if f.Items != nil {
+			item := fieldToUIFormField(*f.Items)
+			uiField.Items = &item
+		}
+	case FieldTypeObject:
+		uiField.Type = UIFieldTypeObject
+		// Field.Properties is a map with no declared ordering, so property
+		// names are sorted for a deterministic, diffable form schema (see
+		// the same pattern in objectFieldToTypeSchema).
+		names := make([]string, 0, len(f.Properties))
+		for name := range f.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		uiField.Fields = make([]UIFormField, 0, len(names))
+		for _, name := range names {
+			if prop := f.Properties[name]; prop != nil {
+				uiField.Fields = append(uiField.Fields, fieldToUIFormField(*prop))
+			}
+		}
+	default:
+		if uiType, ok := uiFieldTypes[f.Type]; ok {
+			uiField.Type = uiType
+		} else {
+			uiField.Type = UIFieldTypeText
+		}
+	}
+
+	return uiField
+}
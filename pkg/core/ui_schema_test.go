@@ -0,0 +1,73 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignature_UIFormSchema(t *testing.T) {
+	minAge := 0.0
+	maxAge := 120.0
+
+	sig := NewSignature(
+		[]InputField{
+			{Field: Field{Name: "question", Description: "The question to answer", Type: FieldTypeString}},
+			{Field: Field{Name: "priority", Type: FieldTypeString, Enum: []string{"low", "high"}, Optional: true}},
+			{Field: Field{Name: "age", Type: FieldTypeInt, Min: &minAge, Max: &maxAge}},
+			{Field: Field{
+				Name:  "tags",
+				Type:  FieldTypeArray,
+				Items: &Field{Type: FieldTypeString},
+			}},
+			{Field: Field{
+				Name: "address",
+				Type: FieldTypeObject,
+				Properties: map[string]*Field{
+					"city": {Name: "city", Type: FieldTypeString},
+					"zip":  {Name: "zip", Type: FieldTypeString, Pattern: `^\d{5}$`},
+				},
+			}},
+		},
+		nil,
+	)
+
+	fields := sig.UIFormSchema()
+	require.Len(t, fields, 5)
+
+	question := fields[0]
+	assert.Equal(t, "question", question.Name)
+	assert.Equal(t, "The question to answer", question.Label)
+	assert.Equal(t, UIFieldTypeText, question.Type)
+	assert.True(t, question.Required)
+
+	priority := fields[1]
+	assert.Equal(t, "priority", priority.Label, "label falls back to name when Description is empty")
+	assert.False(t, priority.Required)
+	assert.Equal(t, []string{"low", "high"}, priority.Enum)
+
+	age := fields[2]
+	assert.Equal(t, UIFieldTypeNumber, age.Type)
+	require.NotNil(t, age.Min)
+	require.NotNil(t, age.Max)
+	assert.Equal(t, 0.0, *age.Min)
+	assert.Equal(t, 120.0, *age.Max)
+
+	tags := fields[3]
+	assert.Equal(t, UIFieldTypeArray, tags.Type)
+	require.NotNil(t, tags.Items)
+	assert.Equal(t, UIFieldTypeText, tags.Items.Type)
+
+	address := fields[4]
+	assert.Equal(t, UIFieldTypeObject, address.Type)
+	require.Len(t, address.Fields, 2)
+	assert.Equal(t, "city", address.Fields[0].Name)
+	assert.Equal(t, "zip", address.Fields[1].Name)
+	assert.Equal(t, `^\d{5}$`, address.Fields[1].Pattern)
+}
+
+func TestSignature_UIFormSchema_EmptySignature(t *testing.T) {
+	sig := NewSignature(nil, nil)
+	assert.Empty(t, sig.UIFormSchema())
+}
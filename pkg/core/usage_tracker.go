@@ -0,0 +1,74 @@
+package core
+
+import "sync"
+
+// UsageSnapshot is a point-in-time read of a UsageTracker's accumulated
+// totals.
+type UsageSnapshot struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	Calls            int
+}
+
+// ModelPricing holds the cost per token for a given model, letting a
+// UsageSnapshot be converted into a dollar estimate.
+type ModelPricing struct {
+	PromptCostPerToken     float64
+	CompletionCostPerToken float64
+}
+
+// EstimateCost converts the snapshot's token counts into a dollar estimate
+// using pricing.
+func (s UsageSnapshot) EstimateCost(pricing ModelPricing) float64 {
+	return float64(s.PromptTokens)*pricing.PromptCostPerToken + float64(s.CompletionTokens)*pricing.CompletionCostPerToken
+}
+
+// UsageTracker accumulates token usage across many LLM calls, safe for
+// concurrent use by batch/parallel runs (e.g. EvaluateDataset or an
+// optimizer's Compile). It is opt-in: an LLM only reports into one when a
+// caller attaches it via WithUsageTracker.
+type UsageTracker struct {
+	mu    sync.Mutex
+	usage TokenInfo
+	calls int
+}
+
+// NewUsageTracker creates an empty UsageTracker.
+func NewUsageTracker() *UsageTracker {
+	return &UsageTracker{}
+}
+
+// Record adds usage to the running totals. A nil usage is a no-op, since
+// not every LLM response carries token counts.
+func (t *UsageTracker) Record(usage *TokenInfo) {
+	if t == nil || usage == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.usage.PromptTokens += usage.PromptTokens
+	t.usage.CompletionTokens += usage.CompletionTokens
+	t.usage.TotalTokens += usage.TotalTokens
+	t.calls++
+}
+
+// Snapshot returns the current accumulated totals.
+func (t *UsageTracker) Snapshot() UsageSnapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return UsageSnapshot{
+		PromptTokens:     t.usage.PromptTokens,
+		CompletionTokens: t.usage.CompletionTokens,
+		TotalTokens:      t.usage.TotalTokens,
+		Calls:            t.calls,
+	}
+}
+
+// Reset zeroes the accumulated totals.
+func (t *UsageTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.usage = TokenInfo{}
+	t.calls = 0
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageTrackerRecordAccumulates(t *testing.T) {
+	tracker := NewUsageTracker()
+
+	tracker.Record(&TokenInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15})
+	tracker.Record(&TokenInfo{PromptTokens: 20, CompletionTokens: 8, TotalTokens: 28})
+
+	snapshot := tracker.Snapshot()
+	assert.Equal(t, 30, snapshot.PromptTokens)
+	assert.Equal(t, 13, snapshot.CompletionTokens)
+	assert.Equal(t, 43, snapshot.TotalTokens)
+	assert.Equal(t, 2, snapshot.Calls)
+}
+
+func TestUsageTrackerRecordIgnoresNil(t *testing.T) {
+	tracker := NewUsageTracker()
+	tracker.Record(nil)
+
+	snapshot := tracker.Snapshot()
+	assert.Equal(t, UsageSnapshot{}, snapshot)
+}
+
+func TestUsageTrackerReset(t *testing.T) {
+	tracker := NewUsageTracker()
+	tracker.Record(&TokenInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15})
+
+	tracker.Reset()
+
+	snapshot := tracker.Snapshot()
+	assert.Equal(t, UsageSnapshot{}, snapshot)
+}
+
+func TestUsageTrackerConcurrentRecord(t *testing.T) {
+	tracker := NewUsageTracker()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tracker.Record(&TokenInfo{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2})
+		}()
+	}
+	wg.Wait()
+
+	snapshot := tracker.Snapshot()
+	assert.Equal(t, 100, snapshot.PromptTokens)
+	assert.Equal(t, 100, snapshot.Calls)
+}
+
+func TestUsageSnapshotEstimateCost(t *testing.T) {
+	snapshot := UsageSnapshot{PromptTokens: 1000, CompletionTokens: 500}
+	pricing := ModelPricing{PromptCostPerToken: 0.000001, CompletionCostPerToken: 0.000002}
+
+	cost := snapshot.EstimateCost(pricing)
+	assert.InDelta(t, 0.002, cost, 1e-9)
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/darwishdev/dspy-go/pkg/utils"
+)
+
+// GenerateWithJSONRetry calls llm.GenerateWithJSON and, when opts includes a
+// ResponseSchema (via WithResponseSchema/WithJSONMode), checks the result
+// against it with validateJSONAgainstSchema. On a validation failure it
+// retries - appending the validation error to the prompt so the model can
+// correct itself - up to ValidationRetry additional times (see
+// WithValidationRetry) before giving up. It returns the number of attempts
+// actually made (1 on a first-try success) alongside the result.
+//
+// A provider error from GenerateWithJSON itself is returned immediately
+// with no retry, since a network/auth failure isn't something a corrected
+// prompt can fix. A schema-less call (no ResponseSchema set) returns
+// whatever GenerateWithJSON produces on the first attempt, same as calling
+// it directly.
+func GenerateWithJSONRetry(ctx context.Context, llm LLM, prompt string, options ...GenerateOption) (map[string]interface{}, int, error) {
+	opts := NewGenerateOptions()
+	for _, opt := range options {
+		opt(opts)
+	}
+
+	attemptPrompt := prompt
+	attempt := 0
+	for {
+		attempt++
+
+		result, err := llm.GenerateWithJSON(ctx, attemptPrompt, options...)
+		if err != nil {
+			return nil, attempt, err
+		}
+
+		verr := validateJSONAgainstSchema(result, opts.ResponseSchema)
+		if verr == nil {
+			return result, attempt, nil
+		}
+		if attempt > opts.ValidationRetry {
+			return result, attempt, verr
+		}
+
+		attemptPrompt = fmt.Sprintf(
+			"%s\n\nYour previous response was invalid: %s. Please correct it and respond again.",
+			prompt, verr,
+		)
+	}
+}
+
+// validateJSONAgainstSchema checks result against schema's Required fields
+// and each declared property's Type, returning the first problem found, or
+// nil if schema is nil or result satisfies it. It only checks properties
+// schema actually declares a Type for and that are present in result -
+// missing optional fields and undeclared extra fields in result are not
+// flagged.
+func validateJSONAgainstSchema(result map[string]interface{}, schema *utils.TypeSchema) error {
+	if schema == nil {
+		return nil
+	}
+
+	for _, name := range schema.Required {
+		if _, ok := result[name]; !ok {
+			return fmt.Errorf("missing required field %q", name)
+		}
+	}
+
+	for name, prop := range schema.Properties {
+		if prop == nil || prop.Type == "" {
+			continue
+		}
+		value, ok := result[name]
+		if !ok {
+			continue
+		}
+		if !jsonValueMatchesSchemaType(value, prop.Type) {
+			return fmt.Errorf("field %q has type %s, expected %s", name, jsonValueTypeName(value), strings.ToLower(prop.Type))
+		}
+	}
+
+	return nil
+}
+
+// jsonValueMatchesSchemaType reports whether value - as decoded by
+// encoding/json, so a JSON number is always a Go float64 - is consistent
+// with schemaType. An unrecognized schemaType is treated as a match, since
+// it's not this function's place to reject a schema it doesn't understand.
+func jsonValueMatchesSchemaType(value interface{}, schemaType string) bool {
+	switch utils.Type(strings.ToUpper(schemaType)) {
+	case utils.TypeString:
+		_, ok := value.(string)
+		return ok
+	case utils.TypeInteger, utils.TypeNumber:
+		_, ok := value.(float64)
+		return ok
+	case utils.TypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case utils.TypeArray:
+		_, ok := value.([]interface{})
+		return ok
+	case utils.TypeObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	default:
+		return true
+	}
+}
+
+// jsonValueTypeName names value's JSON type for a validation error message.
+func jsonValueTypeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/darwishdev/dspy-go/pkg/utils"
+)
+
+func TestGenerateWithJSONRetryCorrectsAfterInvalidResponse(t *testing.T) {
+	schema := &utils.TypeSchema{
+		Type:     string(utils.TypeObject),
+		Required: []string{"answer"},
+		Properties: map[string]*utils.TypeSchema{
+			"answer": {Type: string(utils.TypeString)},
+		},
+	}
+
+	llm := &MockBaseLLM{}
+	llm.On("GenerateWithJSON", mock.Anything, mock.Anything, mock.Anything).
+		Return(map[string]interface{}{"wrong": "field"}, nil).Once()
+	llm.On("GenerateWithJSON", mock.Anything, mock.Anything, mock.Anything).
+		Return(map[string]interface{}{"answer": "42"}, nil).Once()
+
+	result, attempts, err := GenerateWithJSONRetry(context.Background(), llm, "what is the answer?",
+		WithResponseSchema(schema), WithValidationRetry(1))
+
+	if err != nil {
+		t.Fatalf("expected success after retry, got error: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+	if result["answer"] != "42" {
+		t.Errorf("expected corrected answer, got %v", result)
+	}
+	llm.AssertExpectations(t)
+}
+
+func TestGenerateWithJSONRetryGivesUpAfterExhaustingRetries(t *testing.T) {
+	schema := &utils.TypeSchema{
+		Type:     string(utils.TypeObject),
+		Required: []string{"answer"},
+	}
+
+	llm := &MockBaseLLM{}
+	llm.On("GenerateWithJSON", mock.Anything, mock.Anything, mock.Anything).
+		Return(map[string]interface{}{"wrong": "field"}, nil)
+
+	result, attempts, err := GenerateWithJSONRetry(context.Background(), llm, "what is the answer?",
+		WithResponseSchema(schema), WithValidationRetry(2))
+
+	if err == nil {
+		t.Fatal("expected a validation error after exhausting retries")
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts (1 initial + 2 retries), got %d", attempts)
+	}
+	if result == nil {
+		t.Error("expected the last (invalid) result to be returned alongside the error")
+	}
+}
+
+func TestGenerateWithJSONRetryReturnsProviderErrorImmediately(t *testing.T) {
+	llm := &MockBaseLLM{}
+	llm.On("GenerateWithJSON", mock.Anything, mock.Anything, mock.Anything).
+		Return(map[string]interface{}(nil), context.DeadlineExceeded).Once()
+
+	_, attempts, err := GenerateWithJSONRetry(context.Background(), llm, "prompt",
+		WithValidationRetry(3))
+
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected provider error to be returned as-is, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("expected no retry on a provider error, got %d attempts", attempts)
+	}
+}
+
+func TestValidateJSONAgainstSchemaNilSchemaPasses(t *testing.T) {
+	if err := validateJSONAgainstSchema(map[string]interface{}{"x": 1}, nil); err != nil {
+		t.Errorf("expected nil schema to pass, got %v", err)
+	}
+}
+
+func TestValidateJSONAgainstSchemaDetectsTypeMismatch(t *testing.T) {
+	schema := &utils.TypeSchema{
+		Type: string(utils.TypeObject),
+		Properties: map[string]*utils.TypeSchema{
+			"count": {Type: string(utils.TypeInteger)},
+		},
+	}
+
+	err := validateJSONAgainstSchema(map[string]interface{}{"count": "not-a-number"}, schema)
+	if err == nil {
+		t.Fatal("expected a type-mismatch error")
+	}
+}
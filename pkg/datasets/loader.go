@@ -0,0 +1,133 @@
+package datasets
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/darwishdev/dspy-go/pkg/core"
+)
+
+// LoadError reports a malformed record encountered while loading examples,
+// naming the 1-based line (JSONL) or array position (JSON) at which it
+// occurred so a caller can point a user at the offending record.
+type LoadError struct {
+	Line int
+	Err  error
+}
+
+func (e *LoadError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// rawExample is the on-disk JSON shape for a single example record.
+type rawExample struct {
+	Inputs  map[string]interface{} `json:"inputs"`
+	Outputs map[string]interface{} `json:"outputs"`
+}
+
+// LoadExamplesFromJSON loads examples from a JSON file containing a single
+// array of {"inputs": {...}, "outputs": {...}} records, validating each one
+// against signature. The returned error is a *LoadError naming the 1-based
+// position of the first malformed record, or position 1 when the file as a
+// whole is not a valid JSON array.
+func LoadExamplesFromJSON(path string, signature core.Signature) ([]core.Example, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var raw []rawExample
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, &LoadError{Line: 1, Err: err}
+	}
+
+	examples := make([]core.Example, 0, len(raw))
+	for i, r := range raw {
+		example := core.Example{Inputs: r.Inputs, Outputs: r.Outputs}
+		if err := validateExample(example, signature); err != nil {
+			return nil, &LoadError{Line: i + 1, Err: err}
+		}
+		examples = append(examples, example)
+	}
+	return examples, nil
+}
+
+// LoadExamplesFromJSONL loads examples from a file containing one
+// {"inputs": {...}, "outputs": {...}} JSON record per line, validating each
+// one against signature. The returned error is a *LoadError naming the
+// 1-based line number of the first malformed record. Blank lines are
+// skipped.
+func LoadExamplesFromJSONL(path string, signature core.Signature) ([]core.Example, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var examples []core.Example
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var r rawExample
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			return nil, &LoadError{Line: lineNum, Err: err}
+		}
+
+		example := core.Example{Inputs: r.Inputs, Outputs: r.Outputs}
+		if err := validateExample(example, signature); err != nil {
+			return nil, &LoadError{Line: lineNum, Err: err}
+		}
+		examples = append(examples, example)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return examples, nil
+}
+
+// validateExample checks that example has a value for every field declared
+// in signature's inputs and outputs.
+func validateExample(example core.Example, signature core.Signature) error {
+	for _, field := range signature.Inputs {
+		if _, ok := example.Inputs[field.Name]; !ok {
+			return fmt.Errorf("missing required input field %q", field.Name)
+		}
+	}
+	for _, field := range signature.Outputs {
+		if _, ok := example.Outputs[field.Name]; !ok {
+			return fmt.Errorf("missing required output field %q", field.Name)
+		}
+	}
+	return nil
+}
+
+// SplitTrainDev splits examples into a training set and a dev set.
+// trainFraction is clamped to [0, 1]; the training set gets the first
+// len(examples)*trainFraction examples and the dev set gets the rest.
+func SplitTrainDev(examples []core.Example, trainFraction float64) (train, dev []core.Example) {
+	if trainFraction < 0 {
+		trainFraction = 0
+	}
+	if trainFraction > 1 {
+		trainFraction = 1
+	}
+
+	splitAt := int(float64(len(examples)) * trainFraction)
+	train = append([]core.Example{}, examples[:splitAt]...)
+	dev = append([]core.Example{}, examples[splitAt:]...)
+	return train, dev
+}
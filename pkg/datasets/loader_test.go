@@ -0,0 +1,157 @@
+package datasets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/darwishdev/dspy-go/pkg/core"
+)
+
+func testSignature() core.Signature {
+	return core.NewSignature(
+		[]core.InputField{{Field: core.NewField("question")}},
+		[]core.OutputField{{Field: core.NewField("answer")}},
+	)
+}
+
+func TestLoadExamplesFromJSON(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "examples.json")
+	content := `[
+		{"inputs": {"question": "What is the capital of France?"}, "outputs": {"answer": "Paris"}},
+		{"inputs": {"question": "What is the capital of Germany?"}, "outputs": {"answer": "Berlin"}}
+	]`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	examples, err := LoadExamplesFromJSON(path, testSignature())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(examples) != 2 {
+		t.Fatalf("expected 2 examples, got %d", len(examples))
+	}
+	if examples[0].Outputs["answer"] != "Paris" {
+		t.Errorf("expected first example's answer to be Paris, got %v", examples[0].Outputs["answer"])
+	}
+}
+
+func TestLoadExamplesFromJSONMalformed(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "examples.json")
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	_, err := LoadExamplesFromJSON(path, testSignature())
+	if err == nil {
+		t.Fatal("expected error for malformed JSON")
+	}
+	if _, ok := err.(*LoadError); !ok {
+		t.Fatalf("expected *LoadError, got %T: %v", err, err)
+	}
+}
+
+func TestLoadExamplesFromJSONMissingField(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "examples.json")
+	content := `[
+		{"inputs": {"question": "ok"}, "outputs": {"answer": "fine"}},
+		{"inputs": {}, "outputs": {"answer": "missing question"}}
+	]`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	_, err := LoadExamplesFromJSON(path, testSignature())
+	if err == nil {
+		t.Fatal("expected error for missing required field")
+	}
+	loadErr, ok := err.(*LoadError)
+	if !ok {
+		t.Fatalf("expected *LoadError, got %T: %v", err, err)
+	}
+	if loadErr.Line != 2 {
+		t.Errorf("expected error to point at record 2, got line %d", loadErr.Line)
+	}
+}
+
+func TestLoadExamplesFromJSONL(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "examples.jsonl")
+	content := `{"inputs": {"question": "Q1"}, "outputs": {"answer": "A1"}}
+
+{"inputs": {"question": "Q2"}, "outputs": {"answer": "A2"}}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	examples, err := LoadExamplesFromJSONL(path, testSignature())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(examples) != 2 {
+		t.Fatalf("expected 2 examples (blank lines skipped), got %d", len(examples))
+	}
+}
+
+func TestLoadExamplesFromJSONLMalformedLine(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "examples.jsonl")
+	content := `{"inputs": {"question": "Q1"}, "outputs": {"answer": "A1"}}
+not json
+{"inputs": {"question": "Q2"}, "outputs": {"answer": "A2"}}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	_, err := LoadExamplesFromJSONL(path, testSignature())
+	if err == nil {
+		t.Fatal("expected error for malformed line")
+	}
+	loadErr, ok := err.(*LoadError)
+	if !ok {
+		t.Fatalf("expected *LoadError, got %T: %v", err, err)
+	}
+	if loadErr.Line != 2 {
+		t.Errorf("expected error to point at line 2, got line %d", loadErr.Line)
+	}
+}
+
+func TestSplitTrainDev(t *testing.T) {
+	examples := []core.Example{
+		{Inputs: map[string]interface{}{"question": "Q1"}},
+		{Inputs: map[string]interface{}{"question": "Q2"}},
+		{Inputs: map[string]interface{}{"question": "Q3"}},
+		{Inputs: map[string]interface{}{"question": "Q4"}},
+	}
+
+	train, dev := SplitTrainDev(examples, 0.75)
+	if len(train) != 3 {
+		t.Errorf("expected 3 train examples, got %d", len(train))
+	}
+	if len(dev) != 1 {
+		t.Errorf("expected 1 dev example, got %d", len(dev))
+	}
+}
+
+func TestSplitTrainDevClampsFraction(t *testing.T) {
+	examples := []core.Example{
+		{Inputs: map[string]interface{}{"question": "Q1"}},
+		{Inputs: map[string]interface{}{"question": "Q2"}},
+	}
+
+	train, dev := SplitTrainDev(examples, 2.0)
+	if len(train) != 2 || len(dev) != 0 {
+		t.Errorf("expected fraction > 1 to be clamped to all-train, got train=%d dev=%d", len(train), len(dev))
+	}
+
+	train, dev = SplitTrainDev(examples, -1.0)
+	if len(train) != 0 || len(dev) != 2 {
+		t.Errorf("expected negative fraction to be clamped to all-dev, got train=%d dev=%d", len(train), len(dev))
+	}
+}
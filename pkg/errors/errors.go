@@ -22,6 +22,7 @@ const (
 	LLMGenerationFailed
 	TokenLimitExceeded
 	InvalidResponse
+	ContentBlocked
 	ModelNotSupported
 	ProviderNotFound
 	ConfigurationError
@@ -56,7 +57,7 @@ func (e *Error) Error() string {
 	if len(e.fields) > 0 {
 		b.WriteString(" [")
 		for k, v := range e.fields {
-			fmt.Fprintf(&b, "%s=%v ", k, v)
+			fmt.Fprintf(&b, "%s=%s ", k, truncateFieldValue(v))
 		}
 		b.WriteString("]")
 	}
@@ -64,6 +65,20 @@ func (e *Error) Error() string {
 	return strings.TrimSpace(b.String())
 }
 
+// maxFieldValueLen caps how much of a single field's value Error() renders,
+// so a field carrying a large payload (e.g. the full raw text behind a
+// parse failure) doesn't balloon a one-line error message. Fields() always
+// returns the untruncated value - only the Error() string is shortened.
+const maxFieldValueLen = 200
+
+func truncateFieldValue(v interface{}) string {
+	s := fmt.Sprintf("%v", v)
+	if len(s) <= maxFieldValueLen {
+		return s
+	}
+	return s[:maxFieldValueLen] + "...(truncated)"
+}
+
 func (e *Error) Unwrap() error {
 	return e.original
 }
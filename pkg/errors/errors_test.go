@@ -2,6 +2,7 @@ package errors
 
 import (
 	stderrors "errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -559,6 +560,22 @@ func TestFieldsMethodEdgeCases(t *testing.T) {
 	})
 }
 
+func TestErrorString_TruncatesLargeFieldValues(t *testing.T) {
+	large := strings.Repeat("a", 1000)
+	err := &Error{
+		code:    InvalidResponse,
+		message: "failed to parse JSON",
+		fields:  Fields{"raw_response": large},
+	}
+
+	result := err.Error()
+	assert.Less(t, len(result), len(large))
+	assert.Contains(t, result, "...(truncated)")
+
+	// Fields() must still return the value untruncated.
+	assert.Equal(t, large, err.Fields()["raw_response"])
+}
+
 // TestErrorChainIntegration tests complex error chains.
 func TestErrorChainIntegration(t *testing.T) {
 	t.Run("Deep error chain with fields", func(t *testing.T) {
@@ -4,43 +4,192 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"golang.org/x/sync/singleflight"
+
+	"github.com/darwishdev/dspy-go/pkg/cache"
 	"github.com/darwishdev/dspy-go/pkg/core"
 	"github.com/darwishdev/dspy-go/pkg/errors"
 	"github.com/darwishdev/dspy-go/pkg/logging"
 	"github.com/darwishdev/dspy-go/pkg/utils"
 )
 
-func geminiConfigFromCoreConfig(opts *core.GenerateOptions) geminiGenerationConfig {
-	return geminiGenerationConfig{
-		Temperature:        opts.Temperature,
-		MaxOutputTokens:    opts.MaxTokens,
-		TopP:               opts.TopP,
-		ResponseSchema:     opts.ResponseSchema,
-		ResponseJsonSchema: opts.ResponseJSONSchema,
-		ResponseMIMEType:   opts.ResponseMIMEType,
+// geminiMaxOutputTokensByModel records each supported Gemini model's known
+// maxOutputTokens ceiling, so requested/default token counts can be
+// clamped before they reach the API and come back as a 400.
+var geminiMaxOutputTokensByModel = map[core.ModelID]int{
+	core.ModelGoogleGeminiPro:         65536,
+	core.ModelGoogleGeminiFlash:       8192,
+	core.ModelGoogleGeminiFlashLite:   8192,
+	core.ModelGoogleGemini3ProPreview: 65536,
+	core.ModelGoogleGemini20Flash:     8192,
+	core.ModelGoogleGemini20FlashLite: 8192,
+}
+
+// geminiConfig builds the generationConfig for a request. When opts.MaxTokens
+// is unset, it falls back to g.DefaultMaxOutputTokens; either way, the
+// resulting value is clamped to the model's known maxOutputTokens ceiling
+// (logging a warning when clamping actually changes the value) so callers
+// get a truncated-but-valid response instead of an API error.
+func (g *GeminiLLM) geminiConfig(ctx context.Context, opts *core.GenerateOptions) geminiGenerationConfig {
+	maxTokens := opts.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = g.DefaultMaxOutputTokens
+	}
+
+	if limit, ok := geminiMaxOutputTokensByModel[core.ModelID(g.ModelID())]; ok && maxTokens > limit {
+		logging.GetLogger().Warn(ctx, "clamping maxOutputTokens for model %s from %d to %d", g.ModelID(), maxTokens, limit)
+		maxTokens = limit
+	}
+
+	config := geminiGenerationConfig{
+		Temperature:      opts.Temperature,
+		MaxOutputTokens:  maxTokens,
+		TopP:             opts.TopP,
+		ResponseMIMEType: opts.ResponseMIMEType,
 	}
+
+	// The API rejects a request that sets both responseSchema and
+	// responseJsonSchema. ResponseJSONSchema is the newer, standards-based
+	// style, so it wins when a caller (mistakenly or not) sets both.
+	if opts.ResponseJSONSchema != nil {
+		config.ResponseJsonSchema = opts.ResponseJSONSchema
+	} else {
+		config.ResponseSchema = opts.ResponseSchema
+	}
+
+	if opts.ThinkingBudget != 0 || opts.IncludeThoughts {
+		config.ThinkingConfig = &geminiThinkingConfig{
+			ThinkingBudget:  opts.ThinkingBudget,
+			IncludeThoughts: opts.IncludeThoughts,
+		}
+	}
+
+	return config
 }
 
 // GeminiLLM implements the core.LLM interface for Google's Gemini model.
+//
+// A *GeminiLLM is immutable after construction: Generate and friends read
+// DefaultMaxOutputTokens on every call without synchronization, so mutating
+// it on a shared instance from another goroutine is a data race. Use
+// WithDefaultMaxOutputTokens, which returns a new *GeminiLLM instead of
+// mutating the receiver, to change it once a GeminiLLM may already be in
+// use.
 type GeminiLLM struct {
 	*core.BaseLLM
 	apiKey string
+
+	// tokenSource, when set, switches authentication from an API key
+	// carried as a URL query parameter to an OAuth bearer token (e.g. a
+	// Vertex AI service account) carried in the Authorization header,
+	// refreshed via tokenSource on every request. See NewGeminiVertexLLM.
+	tokenSource core.TokenSource
+
+	// DefaultMaxOutputTokens is used as maxOutputTokens whenever a call
+	// doesn't set WithMaxTokens, instead of omitting the field and letting
+	// the model fall back to its own (often small) default. Zero means no
+	// override - the field is simply omitted, as before.
+	DefaultMaxOutputTokens int
+
+	// dedup, when set via WithRequestDedup, collapses concurrent Generate
+	// calls with the same HashRequest key (prompt, response schema and
+	// generationConfig) into a single upstream call, with
+	// every caller receiving the same result or error. Nil (the default)
+	// disables dedup, so every call reaches the API directly.
+	dedup *singleflight.Group
+
+	// SchemaFallbackOnRejection, when enabled via
+	// WithSchemaFallbackOnRejection, makes generate retry once without a
+	// native responseSchema if the API rejects it (see
+	// isSchemaRejectionError), embedding the schema into the prompt as
+	// text instructions instead and validating the result client-side.
+	// False (the default) leaves a schema rejection as a hard failure.
+	SchemaFallbackOnRejection bool
+
+	// tokenCountCache, when set via WithTokenCountCache, memoizes
+	// CountTokens results keyed on a hash of (model, content), so
+	// repeatedly counting the same static prefix - a system instruction, a
+	// fixed RAG corpus - skips the API call on every call after the first.
+	// Nil (the default) disables caching, so every call reaches the API.
+	tokenCountCache cache.Cache
+}
+
+// WithDefaultMaxOutputTokens returns a copy of g with DefaultMaxOutputTokens
+// set to tokens, leaving g itself untouched. Prefer this over assigning
+// g.DefaultMaxOutputTokens directly once g may be shared across goroutines.
+func (g *GeminiLLM) WithDefaultMaxOutputTokens(tokens int) *GeminiLLM {
+	clone := *g
+	clone.DefaultMaxOutputTokens = tokens
+	return &clone
+}
+
+// WithRequestDedup returns a copy of g with singleflight-based request
+// deduplication enabled: concurrent Generate calls whose prompt, response
+// schema and generationConfig hash identically (see HashRequest) collapse
+// into a single upstream request, and every caller receives that call's
+// result or error. Other options, such as a "cached_content" param or
+// extra metadata, are not part of that key, so calls differing only in
+// them are collapsed as well. This only affects Generate -
+// StreamGenerate always issues its own request, since a stream can't be
+// replayed to multiple waiters. Dedup is opt-in; g.dedup is nil (disabled)
+// until WithRequestDedup is called.
+func (g *GeminiLLM) WithRequestDedup() *GeminiLLM {
+	clone := *g
+	clone.dedup = &singleflight.Group{}
+	return &clone
+}
+
+// WithSchemaFallbackOnRejection returns a copy of g with
+// SchemaFallbackOnRejection enabled: if the API rejects opts.ResponseSchema
+// as too complex, generate retries once with the schema embedded in the
+// prompt instead of the native responseSchema field, records
+// core.MetadataKeySchemaDropped on the response, and does a best-effort
+// client-side check of the result (see validateAgainstSchema). Disabled by
+// default, so an unsupported schema still fails the call as before.
+func (g *GeminiLLM) WithSchemaFallbackOnRejection() *GeminiLLM {
+	clone := *g
+	clone.SchemaFallbackOnRejection = true
+	return &clone
+}
+
+// WithTokenCountCache returns a copy of g with CountTokens results cached
+// in a bounded in-memory cache, evicting least-recently-used entries once
+// maxSizeBytes is exceeded. Pass 0 for an unbounded cache. Disabled by
+// default, so every CountTokens call reaches the API until this is called.
+func (g *GeminiLLM) WithTokenCountCache(maxSizeBytes int64) *GeminiLLM {
+	memCache, err := cache.NewMemoryCache(cache.CacheConfig{Type: "memory", MaxSize: maxSizeBytes})
+	if err != nil {
+		// NewMemoryCache only fails to construct a cache it itself defines
+		// the config for; treat as unreachable rather than threading an
+		// error return through every With* builder in this file.
+		return g
+	}
+
+	clone := *g
+	clone.tokenCountCache = memCache
+	return &clone
 }
 
 // GeminiRequest represents the request structure for Gemini API.
 type geminiRequest struct {
 	Contents         []geminiContent        `json:"contents"`
 	GenerationConfig geminiGenerationConfig `json:"generationConfig,omitempty"`
+	// CachedContent references a handle created by CreateCachedContent
+	// ("cachedContents/xxxx"), set via core.WithGenerateParams(map[string]interface{}{"cached_content": handle.Name}).
+	CachedContent string `json:"cachedContent,omitempty"`
 }
 
 // Add this to your existing geminiRequest struct or create a new one for function calling.
@@ -91,6 +240,27 @@ type geminiGenerationConfig struct {
 	ResponseJsonSchema any               `json:"responseJsonSchema,omitempty"`
 	ResponseMIMEType   string            `json:"responseMimeType,omitempty"`
 	ResponseSchema     *utils.TypeSchema `json:"responseSchema,omitempty"`
+
+	// ThinkingConfig is nil unless core.WithThinkingBudget or
+	// core.WithIncludeThoughts was used, so a call that doesn't ask for
+	// thinking mode omits the field entirely rather than sending a
+	// zero-valued thinkingConfig the API would interpret as a real setting.
+	ThinkingConfig *geminiThinkingConfig `json:"thinkingConfig,omitempty"`
+}
+
+// geminiThinkingConfig configures Gemini's thinking mode: ThinkingBudget
+// caps how many tokens the model may spend on internal reasoning before
+// answering, and IncludeThoughts asks the API to return that reasoning as
+// parts marked "thought": true instead of discarding it.
+type geminiThinkingConfig struct {
+	ThinkingBudget  int  `json:"thinkingBudget,omitempty"`
+	IncludeThoughts bool `json:"includeThoughts,omitempty"`
+}
+
+// geminiPromptFeedback carries the reason a prompt was blocked when the API
+// returns a 200 response with no candidates (e.g. safety filtering).
+type geminiPromptFeedback struct {
+	BlockReason string `json:"blockReason,omitempty"`
 }
 
 // GeminiResponse represents the response structure from Gemini API.
@@ -98,11 +268,14 @@ type geminiResponse struct {
 	Candidates []struct {
 		Content struct {
 			Parts []struct {
-				Text string `json:"text"`
+				Text    string `json:"text"`
+				Thought bool   `json:"thought,omitempty"`
 			} `json:"parts"`
 		} `json:"content"`
+		FinishReason string `json:"finishReason,omitempty"`
 	} `json:"candidates"`
-	UsageMetadata struct {
+	PromptFeedback geminiPromptFeedback `json:"promptFeedback,omitempty"`
+	UsageMetadata  struct {
 		PromptTokenCount     int `json:"promptTokenCount"`
 		CandidatesTokenCount int `json:"candidatesTokenCount"`
 		TotalTokenCount      int `json:"totalTokenCount"`
@@ -118,17 +291,156 @@ type geminiFunctionResponse struct {
 			} `json:"parts"`
 		} `json:"content"`
 	} `json:"candidates"`
-	UsageMetadata struct {
+	PromptFeedback geminiPromptFeedback `json:"promptFeedback,omitempty"`
+	UsageMetadata  struct {
 		PromptTokenCount     int `json:"promptTokenCount"`
 		CandidatesTokenCount int `json:"candidatesTokenCount"`
 		TotalTokenCount      int `json:"totalTokenCount"`
 	} `json:"usageMetadata"`
 }
+
+// HashRequest returns a stable, content-addressed hash of a fully-specified
+// Gemini request (prompt, response schema, and generation config), suitable
+// as a cache key or for matching requests during record/replay. Equal
+// (prompt, schema, cfg) values always hash identically: encoding/json
+// already sorts map keys and marshals struct fields in a fixed order, so
+// marshaling the three together is enough to canonicalize them.
+func HashRequest(prompt string, schema *utils.TypeSchema, cfg geminiGenerationConfig) string {
+	canonical := struct {
+		Prompt string                 `json:"prompt"`
+		Schema *utils.TypeSchema      `json:"schema,omitempty"`
+		Config geminiGenerationConfig `json:"config"`
+	}{
+		Prompt: prompt,
+		Schema: schema,
+		Config: cfg,
+	}
+
+	data, err := json.Marshal(canonical)
+	if err != nil {
+		// A well-known request structure should never fail to marshal; if it
+		// somehow does, fold the error into the hash so callers still get a
+		// deterministic key rather than a crash.
+		data = []byte(err.Error())
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// noCandidatesError builds the error returned when a Gemini response
+// contains no candidates. If the prompt was blocked, promptFeedback carries
+// the block reason, which is surfaced as a ContentBlocked error instead of
+// the generic "no candidates" InvalidResponse - the two cases need
+// different handling upstream (e.g. retrying vs. rewording the prompt).
+func noCandidatesError(modelID string, promptFeedback geminiPromptFeedback) error {
+	if promptFeedback.BlockReason != "" {
+		return errors.WithFields(
+			errors.New(errors.ContentBlocked, fmt.Sprintf("ContentBlocked: prompt was blocked: %s", promptFeedback.BlockReason)),
+			errors.Fields{
+				"model":       modelID,
+				"blockReason": promptFeedback.BlockReason,
+			})
+	}
+	return errors.WithFields(
+		errors.New(errors.InvalidResponse, "InvalidResponse: no candidates in response"),
+		errors.Fields{
+			"model": modelID,
+		})
+}
+
+// recordRequestMetrics reports request latency and, on error, an
+// errors-by-code counter to g's MetricsHook. kind distinguishes the call
+// site (e.g. "generate", "embedding", "stream") so dashboards can break
+// down latency and error rate per request shape.
+func (g *GeminiLLM) recordRequestMetrics(kind string, start time.Time, err error) {
+	hook := g.GetMetricsHook()
+	tags := map[string]string{
+		"provider": g.ProviderName(),
+		"model":    g.ModelID(),
+		"kind":     kind,
+	}
+	hook.IncrementCounter("llm_requests_total", tags)
+	hook.ObserveHistogram("llm_request_duration_seconds", time.Since(start).Seconds(), tags)
+	if err != nil {
+		errTags := map[string]string{
+			"provider": g.ProviderName(),
+			"model":    g.ModelID(),
+			"kind":     kind,
+			"code":     geminiErrorCode(err),
+		}
+		hook.IncrementCounter("llm_errors_total", errTags)
+	}
+}
+
+// recordTokenMetrics reports prompt/completion/total token counts to g's
+// MetricsHook after a successful generation.
+func (g *GeminiLLM) recordTokenMetrics(kind string, usage *core.TokenInfo) {
+	if usage == nil {
+		return
+	}
+	hook := g.GetMetricsHook()
+	tags := map[string]string{
+		"provider": g.ProviderName(),
+		"model":    g.ModelID(),
+		"kind":     kind,
+	}
+	hook.ObserveHistogram("llm_prompt_tokens", float64(usage.PromptTokens), tags)
+	hook.ObserveHistogram("llm_completion_tokens", float64(usage.CompletionTokens), tags)
+	hook.ObserveHistogram("llm_total_tokens", float64(usage.TotalTokens), tags)
+}
+
+// geminiErrorCode extracts the dspy error code from err for use as a
+// metrics tag, falling back to "unknown" for errors that didn't originate
+// from pkg/errors.
+func geminiErrorCode(err error) string {
+	if dspyErr, ok := err.(*errors.Error); ok {
+		return strconv.Itoa(int(dspyErr.Code()))
+	}
+	return "unknown"
+}
+
 type geminiFunctionCall struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments"`
 }
 
+// geminiStreamChunk is the shape of a single SSE "data:" payload when
+// streaming, covering text deltas, thought deltas (Thought set when
+// IncludeThoughts was requested), and function-call parts.
+//
+// UsageMetadata, when present, is cumulative - the totals-so-far, not a
+// delta since the previous chunk - matching the non-streaming response's
+// usageMetadata field. Gemini only guarantees it on the final chunk, but
+// some responses also carry it on intermediate ones; handleGeminiStreamResponse
+// attaches it to every StreamChunk emitted from a payload that has it, so
+// whichever chunk happens to be last still leaves the aggregator with the
+// right running total.
+type geminiStreamChunk struct {
+	Candidates []struct {
+		Content struct {
+			Parts []struct {
+				Text         string              `json:"text,omitempty"`
+				Thought      bool                `json:"thought,omitempty"`
+				FunctionCall *geminiFunctionCall `json:"function_call,omitempty"`
+			} `json:"parts"`
+		} `json:"content"`
+	} `json:"candidates"`
+	UsageMetadata *struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata,omitempty"`
+}
+
+// geminiStreamState accumulates partial tool-call state across the SSE
+// chunks of a single stream, since a call's arguments may be split across
+// multiple chunks before they form valid JSON.
+type geminiStreamState struct {
+	toolCallName string
+	toolCallArgs strings.Builder
+}
+
 // Request and response structures for Gemini embeddings.
 type geminiEmbeddingRequest struct {
 	Model   string `json:"model"`
@@ -212,7 +524,7 @@ func NewGeminiLLM(apiKey string, model core.ModelID) (*GeminiLLM, error) {
 			errors.Fields{"model": model})
 	}
 	endpoint := &core.EndpointConfig{
-		BaseURL: "https://generativelanguage.googleapis.com/v1beta",
+		BaseURL: "https://generativelanguage.googleapis.com/" + defaultGeminiAPIVersion,
 		Path:    fmt.Sprintf("/models/%s:generateContent", model),
 		Headers: map[string]string{
 			"Content-Type": "application/json",
@@ -226,6 +538,64 @@ func NewGeminiLLM(apiKey string, model core.ModelID) (*GeminiLLM, error) {
 	}, nil
 }
 
+// defaultVertexAPIVersion is the Vertex AI REST API version used to build
+// the regional endpoint URL in NewGeminiVertexLLM.
+const defaultVertexAPIVersion = "v1"
+
+// NewGeminiVertexLLM creates a GeminiLLM that authenticates against Vertex
+// AI instead of the public Gemini API: requests carry an OAuth bearer
+// token obtained from tokenSource (e.g. a service account's token source)
+// in the Authorization header rather than an API key in the URL, and
+// target the regional Vertex endpoint for projectID/location.
+func NewGeminiVertexLLM(tokenSource core.TokenSource, projectID, location string, model core.ModelID) (*GeminiLLM, error) {
+	if tokenSource == nil {
+		return nil, errors.New(errors.InvalidInput, "token source is required for Vertex AI authentication")
+	}
+	if projectID == "" {
+		return nil, errors.New(errors.InvalidInput, "Vertex AI project ID is required")
+	}
+	if location == "" {
+		return nil, errors.New(errors.InvalidInput, "Vertex AI location is required")
+	}
+
+	if model == "" {
+		model = core.ModelGoogleGeminiFlash // Default model
+	}
+	capabilities := []core.Capability{
+		core.CapabilityCompletion,
+		core.CapabilityChat,
+		core.CapabilityJSON,
+		core.CapabilityEmbedding,
+		core.CapabilityMultimodal,
+		core.CapabilityVision,
+		core.CapabilityAudio,
+	}
+	switch model {
+	case core.ModelGoogleGeminiPro, core.ModelGoogleGeminiFlash, core.ModelGoogleGeminiFlashLite,
+		core.ModelGoogleGemini3ProPreview,
+		core.ModelGoogleGemini20Flash, core.ModelGoogleGemini20FlashLite:
+		break
+	default:
+		return nil, errors.WithFields(
+			errors.New(errors.InvalidInput, fmt.Sprintf("unsupported Gemini model: %s", model)),
+			errors.Fields{"model": model})
+	}
+
+	endpoint := &core.EndpointConfig{
+		BaseURL: fmt.Sprintf("https://%s-aiplatform.googleapis.com/%s", location, defaultVertexAPIVersion),
+		Path:    fmt.Sprintf("/projects/%s/locations/%s/publishers/google/models/%s:generateContent", projectID, location, model),
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		TimeoutSec: 10 * 60,
+	}
+
+	return &GeminiLLM{
+		tokenSource: tokenSource,
+		BaseLLM:     core.NewBaseLLM("google-vertex", model, capabilities, endpoint),
+	}, nil
+}
+
 // NewGeminiLLMFromConfig creates a new GeminiLLM instance from configuration.
 func NewGeminiLLMFromConfig(ctx context.Context, config core.ProviderConfig, modelID core.ModelID) (*GeminiLLM, error) {
 	// Get API key from config or environment
@@ -250,7 +620,19 @@ func NewGeminiLLMFromConfig(ctx context.Context, config core.ProviderConfig, mod
 	}
 
 	// Create endpoint configuration
-	baseURL := "https://generativelanguage.googleapis.com/v1beta"
+	apiVersion := defaultGeminiAPIVersion
+	if config.Params != nil {
+		if v, ok := config.Params["api_version"].(string); ok && v != "" {
+			if !isValidGeminiAPIVersion(v) {
+				return nil, errors.WithFields(
+					errors.New(errors.InvalidInput, "unsupported Gemini API version"),
+					errors.Fields{"api_version": v})
+			}
+			apiVersion = v
+		}
+	}
+
+	baseURL := fmt.Sprintf("https://generativelanguage.googleapis.com/%s", apiVersion)
 	if config.BaseURL != "" {
 		baseURL = config.BaseURL
 	}
@@ -331,6 +713,21 @@ func isValidGeminiModel(modelID core.ModelID) bool {
 	return false
 }
 
+// defaultGeminiAPIVersion is the Gemini API version used when a
+// NewGeminiLLMFromConfig caller doesn't set "api_version" in config.Params.
+const defaultGeminiAPIVersion = "v1beta"
+
+// isValidGeminiAPIVersion reports whether version is a Gemini API version
+// this client knows how to target.
+func isValidGeminiAPIVersion(version string) bool {
+	switch version {
+	case "v1", "v1beta":
+		return true
+	default:
+		return false
+	}
+}
+
 // supportsGeminiStreaming checks if the model supports streaming.
 func supportsGeminiStreaming(modelID core.ModelID) bool {
 	// Most Gemini models support streaming
@@ -345,10 +742,139 @@ func supportsGeminiFunctionCalling(modelID core.ModelID) bool {
 
 // Generate implements the core.LLM interface.
 func (g *GeminiLLM) Generate(ctx context.Context, prompt string, options ...core.GenerateOption) (*core.LLMResponse, error) {
+	start := time.Now()
+	result, err := g.dedupedGenerate(ctx, prompt, options...)
+	g.recordRequestMetrics("generate", start, err)
+	if err == nil {
+		g.recordTokenMetrics("generate", result.Usage)
+	}
+	return result, err
+}
+
+// dedupedGenerate calls generate directly, unless WithRequestDedup has
+// enabled g.dedup, in which case it collapses this call with any other
+// concurrent call with the same HashRequest key (prompt, response schema
+// and generationConfig) into a single
+// g.generate invocation via singleflight, sharing that call's result or
+// error with every waiter.
+func (g *GeminiLLM) dedupedGenerate(ctx context.Context, prompt string, options ...core.GenerateOption) (*core.LLMResponse, error) {
+	if g.dedup == nil {
+		return g.generate(ctx, prompt, options...)
+	}
+
+	opts := core.NewGenerateOptions()
+	for _, opt := range options {
+		opt(opts)
+	}
+	key := HashRequest(prompt, opts.ResponseSchema, g.geminiConfig(ctx, opts))
+
+	v, err, _ := g.dedup.Do(key, func() (interface{}, error) {
+		return g.generate(ctx, prompt, options...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*core.LLMResponse), nil
+}
+
+// generate performs the actual Gemini API call for Generate. It is split
+// out so Generate can wrap it with latency/token/error metrics emission.
+func (g *GeminiLLM) generate(ctx context.Context, prompt string, options ...core.GenerateOption) (*core.LLMResponse, error) {
 	opts := core.NewGenerateOptions()
 	for _, opt := range options {
 		opt(opts)
 	}
+	return g.generateWithConfig(ctx, prompt, g.geminiConfig(ctx, opts), opts)
+}
+
+// generateWithConfig is generate's body, taking an already-built
+// generationConfig rather than deriving one from opts itself. generate
+// builds genConfig fresh on every call; (*RequestTemplate).Generate instead
+// passes the genConfig captured once by NewRequestTemplate, skipping that
+// reconstruction for repeated calls that share the same options.
+func (g *GeminiLLM) generateWithConfig(ctx context.Context, prompt string, genConfig geminiGenerationConfig, opts *core.GenerateOptions) (*core.LLMResponse, error) {
+	start := time.Now()
+	if opts.SanitizePrompt {
+		prompt = utils.SanitizeText(prompt)
+	}
+
+	schema := opts.ResponseSchema
+	attemptPrompt := prompt
+	schemaDropped := false
+
+	geminiResp, statusCode, body, err := g.sendGenerateRequest(ctx, attemptPrompt, schema, genConfig, opts)
+	if err != nil && g.SchemaFallbackOnRejection && schema != nil && isSchemaRejectionError(statusCode, body) {
+		schemaDropped = true
+		attemptPrompt = embedSchemaInstructions(prompt, schema)
+		geminiResp, _, _, err = g.sendGenerateRequest(ctx, attemptPrompt, nil, genConfig, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(geminiResp.Candidates) == 0 {
+		return nil, noCandidatesError(g.ModelID(), geminiResp.PromptFeedback)
+	}
+
+	if len(geminiResp.Candidates[0].Content.Parts) == 0 {
+		return nil, errors.WithFields(
+			errors.New(errors.InvalidResponse, "InvalidResponse: no parts in response candidate"),
+			errors.Fields{
+				"model": g.ModelID(),
+			})
+	}
+
+	var content, thoughts strings.Builder
+	for _, part := range geminiResp.Candidates[0].Content.Parts {
+		if part.Thought {
+			thoughts.WriteString(part.Text)
+		} else {
+			content.WriteString(part.Text)
+		}
+	}
+	usage := &core.TokenInfo{
+		PromptTokens:     geminiResp.UsageMetadata.PromptTokenCount,
+		CompletionTokens: geminiResp.UsageMetadata.CandidatesTokenCount,
+		TotalTokens:      geminiResp.UsageMetadata.TotalTokenCount,
+	}
+	opts.UsageTracker.Record(usage)
+
+	metadata := map[string]interface{}{
+		core.MetadataKeyModel:       g.ModelID(),
+		core.MetadataKeyLatency:     time.Since(start),
+		core.MetadataKeyRequestHash: HashRequest(prompt, opts.ResponseSchema, genConfig),
+	}
+	if finishReason := geminiResp.Candidates[0].FinishReason; finishReason != "" {
+		metadata[core.MetadataKeyFinishReason] = finishReason
+	}
+	if schemaDropped {
+		metadata[core.MetadataKeySchemaDropped] = true
+		if verr := validateAgainstSchema(content.String(), schema); verr != nil {
+			metadata[core.MetadataKeySchemaValidationError] = verr.Error()
+		}
+	}
+	for k, v := range opts.ExtraMetadata {
+		metadata[k] = v
+	}
+
+	return &core.LLMResponse{
+		Content:  content.String(),
+		Thoughts: thoughts.String(),
+		Usage:    usage,
+		Metadata: metadata,
+	}, nil
+}
+
+// sendGenerateRequest issues a single Gemini generateContent call against
+// genConfig with schema substituted into its responseSchema (nil omits it
+// entirely), returning the raw status code and body alongside the decoded
+// response and/or error. generate's schema-rejection fallback inspects the
+// status code and body to decide whether to retry without schema, which a
+// plain error return couldn't carry.
+func (g *GeminiLLM) sendGenerateRequest(ctx context.Context, prompt string, schema *utils.TypeSchema, genConfig geminiGenerationConfig, opts *core.GenerateOptions) (*geminiResponse, int, []byte, error) {
+	if opts.ResponseJSONSchema == nil {
+		genConfig.ResponseSchema = schema
+	}
 
 	reqBody := geminiRequest{
 		Contents: []geminiContent{
@@ -358,12 +884,15 @@ func (g *GeminiLLM) Generate(ctx context.Context, prompt string, options ...core
 				},
 			},
 		},
-		GenerationConfig: geminiConfigFromCoreConfig(opts),
+		GenerationConfig: genConfig,
+	}
+	if cachedContent, ok := opts.Params["cached_content"].(string); ok && cachedContent != "" {
+		reqBody.CachedContent = cachedContent
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, errors.WithFields(
+		return nil, 0, nil, errors.WithFields(
 			errors.Wrap(err, errors.InvalidInput, "failed to marshal request body"),
 			errors.Fields{
 				"prompt": prompt,
@@ -374,12 +903,12 @@ func (g *GeminiLLM) Generate(ctx context.Context, prompt string, options ...core
 	req, err := http.NewRequestWithContext(
 		ctx,
 		"POST",
-		constructRequestURL(g.GetEndpointConfig(), g.apiKey),
+		g.requestURL(),
 		bytes.NewBuffer(jsonData),
 	)
 
 	if err != nil {
-		return nil, errors.WithFields(
+		return nil, 0, nil, errors.WithFields(
 			errors.Wrap(err, errors.InvalidInput, "failed to create request"),
 			errors.Fields{
 				"model": g.ModelID(),
@@ -389,10 +918,13 @@ func (g *GeminiLLM) Generate(ctx context.Context, prompt string, options ...core
 	for key, value := range g.GetEndpointConfig().Headers {
 		req.Header.Set(key, value)
 	}
+	if err := g.setAuthHeader(ctx, req); err != nil {
+		return nil, 0, nil, err
+	}
 
 	resp, err := g.GetHTTPClient().Do(req)
 	if err != nil {
-		return nil, errors.WithFields(
+		return nil, 0, nil, errors.WithFields(
 			errors.New(errors.LLMGenerationFailed, fmt.Sprintf("LLMGenerationFailed: failed to send request: %v", err)),
 			errors.Fields{
 				"model": g.ModelID(),
@@ -400,9 +932,9 @@ func (g *GeminiLLM) Generate(ctx context.Context, prompt string, options ...core
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, errors.WithFields(
+		return nil, resp.StatusCode, nil, errors.WithFields(
 			errors.New(errors.LLMGenerationFailed, fmt.Sprintf("LLMGenerationFailed: failed to read response body: %v", err)),
 			errors.Fields{
 				"model": g.ModelID(),
@@ -410,8 +942,8 @@ func (g *GeminiLLM) Generate(ctx context.Context, prompt string, options ...core
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.WithFields(
-			errors.New(errors.LLMGenerationFailed, fmt.Sprintf("LLMGenerationFailed: API request failed with status code %d: %s", resp.StatusCode, string(body))),
+		return nil, resp.StatusCode, respBody, errors.WithFields(
+			errors.New(errors.LLMGenerationFailed, fmt.Sprintf("LLMGenerationFailed: API request failed with status code %d: %s", resp.StatusCode, string(respBody))),
 			errors.Fields{
 				"model":      g.ModelID(),
 				"statusCode": resp.StatusCode,
@@ -419,41 +951,123 @@ func (g *GeminiLLM) Generate(ctx context.Context, prompt string, options ...core
 	}
 
 	var geminiResp geminiResponse
-	if err := json.Unmarshal(body, &geminiResp); err != nil {
-		return nil, errors.WithFields(
+	if err := json.Unmarshal(respBody, &geminiResp); err != nil {
+		return nil, resp.StatusCode, respBody, errors.WithFields(
 			errors.New(errors.InvalidResponse, fmt.Sprintf("InvalidResponse: failed to unmarshal response: %v", err)),
 			errors.Fields{
 				"model": g.ModelID(),
 			})
 	}
 
-	if len(geminiResp.Candidates) == 0 {
-		return nil, errors.WithFields(
-			errors.New(errors.InvalidResponse, "InvalidResponse: no candidates in response"),
-			errors.Fields{
-				"model": g.ModelID(),
-			})
+	return &geminiResp, resp.StatusCode, respBody, nil
+}
+
+// RequestTemplate is a reusable, immutable snapshot of a GeminiLLM's
+// generationConfig and options, captured once by NewRequestTemplate from an
+// LLM and a set of core.GenerateOption values. Calling Generate repeatedly
+// on the same template - the common shape for batch scenarios that vary
+// only the prompt - reuses that captured generationConfig and schema
+// instead of re-parsing options and rebuilding them on every call.
+//
+// A *RequestTemplate only reads g and its captured fields; Generate never
+// mutates them, so a template is safe to share across goroutines and reuse
+// for as many calls as needed.
+type RequestTemplate struct {
+	llm       *GeminiLLM
+	opts      *core.GenerateOptions
+	genConfig geminiGenerationConfig
+}
+
+// NewRequestTemplate captures options against g into a reusable
+// *RequestTemplate. ctx is only consulted while building the
+// generationConfig (e.g. to log a maxOutputTokens clamp warning) and isn't
+// retained on the returned template.
+func (g *GeminiLLM) NewRequestTemplate(ctx context.Context, options ...core.GenerateOption) *RequestTemplate {
+	opts := core.NewGenerateOptions()
+	for _, opt := range options {
+		opt(opts)
 	}
+	return &RequestTemplate{
+		llm:       g,
+		opts:      opts,
+		genConfig: g.geminiConfig(ctx, opts),
+	}
+}
 
-	if len(geminiResp.Candidates[0].Content.Parts) == 0 {
-		return nil, errors.WithFields(
-			errors.New(errors.InvalidResponse, "InvalidResponse: no parts in response candidate"),
-			errors.Fields{
-				"model": g.ModelID(),
-			})
+// Generate issues prompt through t's captured LLM and options, reusing the
+// generationConfig built once by NewRequestTemplate rather than rebuilding
+// it from options on every call.
+func (t *RequestTemplate) Generate(ctx context.Context, prompt string) (*core.LLMResponse, error) {
+	start := time.Now()
+	result, err := t.llm.generateWithConfig(ctx, prompt, t.genConfig, t.opts)
+	t.llm.recordRequestMetrics("generate", start, err)
+	if err == nil {
+		t.llm.recordTokenMetrics("generate", result.Usage)
+	}
+	return result, err
+}
+
+// isSchemaRejectionError reports whether a Gemini error response looks like
+// the API rejecting responseSchema itself - e.g. because it's too deeply
+// nested or uses an unsupported constraint - rather than some unrelated
+// failure (bad API key, rate limit, safety block). This is a best-effort
+// heuristic over the documented Gemini error shape
+// ({"error":{"code":400,"status":"INVALID_ARGUMENT","message":"..."}})
+// since the API has no dedicated error code for this case.
+func isSchemaRejectionError(statusCode int, body []byte) bool {
+	if statusCode != http.StatusBadRequest {
+		return false
+	}
+	lower := strings.ToLower(string(body))
+	if !strings.Contains(lower, "invalid_argument") {
+		return false
+	}
+	return strings.Contains(lower, "responseschema") ||
+		strings.Contains(lower, "response_schema") ||
+		strings.Contains(lower, "response schema")
+}
+
+// embedSchemaInstructions appends schema as JSON-formatted instructions to
+// prompt. It's the fallback generate uses once the API has rejected
+// responseSchema: the schema can no longer be enforced natively, so it's
+// spelled out in the prompt text instead.
+func embedSchemaInstructions(prompt string, schema *utils.TypeSchema) string {
+	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return prompt
 	}
+	return fmt.Sprintf("%s\n\nRespond with JSON matching this schema exactly:\n%s", prompt, schemaJSON)
+}
 
-	content := geminiResp.Candidates[0].Content.Parts[0].Text
-	usage := &core.TokenInfo{
-		PromptTokens:     geminiResp.UsageMetadata.PromptTokenCount,
-		CompletionTokens: geminiResp.UsageMetadata.CandidatesTokenCount,
-		TotalTokens:      geminiResp.UsageMetadata.TotalTokenCount,
+// validateAgainstSchema does a shallow, best-effort check that content
+// parses as JSON and, for an OBJECT schema, that its required properties
+// are present. It doesn't walk the schema as thoroughly as a native
+// responseSchema would have enforced server-side - it only runs after the
+// schema-rejection fallback, where there's no such enforcement left.
+func validateAgainstSchema(content string, schema *utils.TypeSchema) error {
+	if schema == nil {
+		return nil
 	}
 
-	return &core.LLMResponse{
-		Content: content,
-		Usage:   usage,
-	}, nil
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(content), &decoded); err != nil {
+		return fmt.Errorf("response is not valid JSON: %w", err)
+	}
+
+	if schema.Type != string(utils.TypeObject) || len(schema.Required) == 0 {
+		return nil
+	}
+
+	obj, ok := decoded.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("response is not a JSON object")
+	}
+	for _, name := range schema.Required {
+		if _, ok := obj[name]; !ok {
+			return fmt.Errorf("response is missing required field %q", name)
+		}
+	}
+	return nil
 }
 
 // GenerateWithJSON implements the core.LLM interface.
@@ -522,7 +1136,7 @@ func (g *GeminiLLM) GenerateWithFunctions(ctx context.Context, prompt string, fu
 				FunctionDeclarations: functionDeclarations,
 			},
 		},
-		GenerationConfig: geminiConfigFromCoreConfig(opts),
+		GenerationConfig: g.geminiConfig(ctx, opts),
 	}
 	requestJSON, _ := json.MarshalIndent(reqBody, "", "  ")
 	logger.Debug(ctx, "Function call request JSON: %s", string(requestJSON))
@@ -541,7 +1155,7 @@ func (g *GeminiLLM) GenerateWithFunctions(ctx context.Context, prompt string, fu
 	req, err := http.NewRequestWithContext(
 		ctx,
 		"POST",
-		constructRequestURL(g.GetEndpointConfig(), g.apiKey),
+		g.requestURL(),
 		bytes.NewBuffer(jsonData),
 	)
 
@@ -557,6 +1171,9 @@ func (g *GeminiLLM) GenerateWithFunctions(ctx context.Context, prompt string, fu
 	for key, value := range g.GetEndpointConfig().Headers {
 		req.Header.Set(key, value)
 	}
+	if err := g.setAuthHeader(ctx, req); err != nil {
+		return nil, err
+	}
 
 	// Send the request
 	resp, err := g.GetHTTPClient().Do(req)
@@ -602,11 +1219,7 @@ func (g *GeminiLLM) GenerateWithFunctions(ctx context.Context, prompt string, fu
 	}
 
 	if len(geminiResp.Candidates) == 0 {
-		return nil, errors.WithFields(
-			errors.New(errors.InvalidResponse, "no candidates in response"),
-			errors.Fields{
-				"model": g.ModelID(),
-			})
+		return nil, noCandidatesError(g.ModelID(), geminiResp.PromptFeedback)
 	}
 
 	// Extract usage information
@@ -615,6 +1228,7 @@ func (g *GeminiLLM) GenerateWithFunctions(ctx context.Context, prompt string, fu
 		CompletionTokens: geminiResp.UsageMetadata.CandidatesTokenCount,
 		TotalTokens:      geminiResp.UsageMetadata.TotalTokenCount,
 	}
+	opts.UsageTracker.Record(usage)
 
 	// Process the response to extract function call if present
 	result := make(map[string]interface{})
@@ -667,6 +1281,16 @@ func (g *GeminiLLM) GenerateWithFunctions(ctx context.Context, prompt string, fu
 
 // CreateEmbedding implements the embedding generation for a single input.
 func (g *GeminiLLM) CreateEmbedding(ctx context.Context, input string, options ...core.EmbeddingOption) (*core.EmbeddingResult, error) {
+	start := time.Now()
+	result, err := g.createEmbedding(ctx, input, options...)
+	g.recordRequestMetrics("embedding", start, err)
+	return result, err
+}
+
+// createEmbedding performs the actual embedding call for CreateEmbedding,
+// split out so CreateEmbedding can wrap it with latency/error metrics
+// emission.
+func (g *GeminiLLM) createEmbedding(ctx context.Context, input string, options ...core.EmbeddingOption) (*core.EmbeddingResult, error) {
 	// Apply options
 	opts := core.NewEmbeddingOptions()
 	for _, opt := range options {
@@ -706,10 +1330,10 @@ func (g *GeminiLLM) CreateEmbedding(ctx context.Context, input string, options .
 	}
 
 	// Create request
-	url := fmt.Sprintf("%s/models/%s:embedContent?key=%s",
+	url := fmt.Sprintf("%s/models/%s:embedContent%s",
 		g.GetEndpointConfig().BaseURL,
 		opts.Model,
-		g.apiKey)
+		g.authQuerySuffix())
 	req, err := http.NewRequestWithContext(
 		ctx,
 		"POST",
@@ -728,6 +1352,9 @@ func (g *GeminiLLM) CreateEmbedding(ctx context.Context, input string, options .
 	for key, value := range g.GetEndpointConfig().Headers {
 		req.Header.Set(key, value)
 	}
+	if err := g.setAuthHeader(ctx, req); err != nil {
+		return nil, err
+	}
 
 	// Execute request
 	resp, err := g.GetHTTPClient().Do(req)
@@ -799,6 +1426,16 @@ func (g *GeminiLLM) CreateEmbedding(ctx context.Context, input string, options .
 
 // CreateEmbeddings implements batch embedding generation.
 func (g *GeminiLLM) CreateEmbeddings(ctx context.Context, inputs []string, options ...core.EmbeddingOption) (*core.BatchEmbeddingResult, error) {
+	start := time.Now()
+	result, err := g.createEmbeddings(ctx, inputs, options...)
+	g.recordRequestMetrics("embedding_batch", start, err)
+	return result, err
+}
+
+// createEmbeddings performs the actual batched embedding calls for
+// CreateEmbeddings, split out so CreateEmbeddings can wrap it with
+// latency/error metrics emission.
+func (g *GeminiLLM) createEmbeddings(ctx context.Context, inputs []string, options ...core.EmbeddingOption) (*core.BatchEmbeddingResult, error) {
 	// Apply options
 	opts := core.NewEmbeddingOptions()
 	for _, opt := range options {
@@ -863,9 +1500,9 @@ func (g *GeminiLLM) CreateEmbeddings(ctx context.Context, inputs []string, optio
 			continue
 		}
 
-		url := fmt.Sprintf("%s/models/text-embedding-004:batchEmbedContents?key=%s",
+		url := fmt.Sprintf("%s/models/text-embedding-004:batchEmbedContents%s",
 			g.GetEndpointConfig().BaseURL,
-			g.apiKey)
+			g.authQuerySuffix())
 
 		// Create request
 		req, err := http.NewRequestWithContext(
@@ -890,6 +1527,13 @@ func (g *GeminiLLM) CreateEmbeddings(ctx context.Context, inputs []string, optio
 		for key, value := range g.GetEndpointConfig().Headers {
 			req.Header.Set(key, value)
 		}
+		if err := g.setAuthHeader(ctx, req); err != nil {
+			if firstError == nil {
+				firstError = err
+				errorIndex = i
+			}
+			continue
+		}
 
 		// Execute request
 		resp, err := g.GetHTTPClient().Do(req)
@@ -974,20 +1618,106 @@ func (g *GeminiLLM) CreateEmbeddings(ctx context.Context, inputs []string, optio
 	}, nil
 }
 
+// handleGeminiStreamResponse parses a single SSE "data:" payload and emits
+// the resulting chunk(s) onto chunkChan. Text parts are emitted immediately
+// as content deltas, or as thought deltas (StreamChunk.Thought instead of
+// Content) when the part is marked "thought": true. Function-call parts
+// have their argument JSON
+// accumulated in state across calls - once the accumulated JSON parses as a
+// complete object, a single StreamChunk carrying a ToolCall is emitted and
+// the accumulator is reset, so consumers can tell text deltas apart from
+// tool-call deltas via which field is populated.
+func (g *GeminiLLM) handleGeminiStreamResponse(data string, state *geminiStreamState, chunkChan chan core.StreamChunk, streamCtx context.Context) {
+	var chunk geminiStreamChunk
+	if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+		return
+	}
+
+	var usage *core.TokenInfo
+	if chunk.UsageMetadata != nil {
+		usage = &core.TokenInfo{
+			PromptTokens:     chunk.UsageMetadata.PromptTokenCount,
+			CompletionTokens: chunk.UsageMetadata.CandidatesTokenCount,
+			TotalTokens:      chunk.UsageMetadata.TotalTokenCount,
+		}
+	}
+
+	if len(chunk.Candidates) == 0 {
+		// The final chunk can carry usageMetadata with no candidate content
+		// of its own - surface it rather than dropping it on the floor.
+		if usage != nil {
+			select {
+			case chunkChan <- core.StreamChunk{Usage: usage}:
+			case <-streamCtx.Done():
+			}
+		}
+		return
+	}
+
+	for _, part := range chunk.Candidates[0].Content.Parts {
+		if part.FunctionCall != nil {
+			if part.FunctionCall.Name != "" {
+				state.toolCallName = part.FunctionCall.Name
+			}
+			if argsJSON, err := json.Marshal(part.FunctionCall.Arguments); err == nil {
+				state.toolCallArgs.Write(argsJSON)
+			}
+
+			accumulated := state.toolCallArgs.String()
+			var assembled map[string]interface{}
+			if accumulated != "" && json.Valid([]byte(accumulated)) {
+				if err := json.Unmarshal([]byte(accumulated), &assembled); err == nil {
+					select {
+					case chunkChan <- core.StreamChunk{
+						ToolCall: &core.ToolCallDelta{
+							Name:      state.toolCallName,
+							Arguments: assembled,
+						},
+						Usage: usage,
+					}:
+					case <-streamCtx.Done():
+						return
+					}
+					state.toolCallName = ""
+					state.toolCallArgs.Reset()
+				}
+			}
+		}
+
+		if part.Text != "" {
+			streamChunk := core.StreamChunk{Usage: usage}
+			if part.Thought {
+				streamChunk.Thought = part.Text
+			} else {
+				streamChunk.Content = part.Text
+			}
+			select {
+			case chunkChan <- streamChunk:
+			case <-streamCtx.Done():
+				return
+			}
+		}
+	}
+}
+
 // streamRequest handles the common streaming logic for both StreamGenerate and StreamGenerateWithContent.
 func (g *GeminiLLM) streamRequest(ctx context.Context, reqBody interface{}) (*core.StreamResponse, error) {
+	start := time.Now()
+
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
+		g.recordRequestMetrics("stream", start, err)
 		return nil, errors.WithFields(
 			errors.New(errors.InvalidInput, fmt.Sprintf("failed to marshal request body: %v", err)),
 			errors.Fields{"model": g.ModelID()})
 	}
 
 	// Add streaming parameter
-	streamURL := constructRequestURL(g.GetEndpointConfig(), g.apiKey) + "&alt=sse"
+	streamURL := g.requestURL() + g.streamQuerySep() + "alt=sse"
 
 	req, err := http.NewRequestWithContext(ctx, "POST", streamURL, bytes.NewBuffer(jsonData))
 	if err != nil {
+		g.recordRequestMetrics("stream", start, err)
 		return nil, errors.WithFields(
 			errors.New(errors.InvalidInput, fmt.Sprintf("failed to create request: %v", err)),
 			errors.Fields{"model": g.ModelID()})
@@ -996,6 +1726,10 @@ func (g *GeminiLLM) streamRequest(ctx context.Context, reqBody interface{}) (*co
 	for key, value := range g.GetEndpointConfig().Headers {
 		req.Header.Set(key, value)
 	}
+	if err := g.setAuthHeader(ctx, req); err != nil {
+		g.recordRequestMetrics("stream", start, err)
+		return nil, err
+	}
 	req.Header.Set("Accept", "text/event-stream")
 
 	// Create channels and response
@@ -1023,20 +1757,83 @@ func (g *GeminiLLM) streamRequest(ctx context.Context, reqBody interface{}) (*co
 	go func() {
 		defer safeCloseChannel()
 
+		var streamErr error
+		defer func() { g.recordRequestMetrics("stream", start, streamErr) }()
+
 		client := g.GetHTTPClient()
 		resp, err := client.Do(req)
 		if err != nil {
-			if streamCtx.Err() != nil {
-				return
-			}
-			chunkChan <- core.StreamChunk{
-				Error: errors.New(errors.LLMGenerationFailed, fmt.Sprintf("request failed: %v", err)),
+			streamErr = errors.New(errors.LLMGenerationFailed, fmt.Sprintf("request failed: %v", err))
+			select {
+			case chunkChan <- core.StreamChunk{Error: streamErr}:
+			case <-streamCtx.Done():
 			}
 			return
 		}
 		defer resp.Body.Close()
 
 		reader := bufio.NewReader(resp.Body)
+		state := &geminiStreamState{}
+
+		// dataBuf accumulates an SSE event's data lines across possibly
+		// several "data:" lines - per the SSE spec, multiple consecutive
+		// data lines within one event are joined with "\n" into a single
+		// field - until the blank line that terminates the event, at which
+		// point the accumulated field is parsed as one coherent JSON chunk.
+		// Parsing per-line instead would choke on any event whose JSON
+		// payload happens to be wrapped across multiple data: lines.
+		var dataBuf strings.Builder
+
+		flushDataBuf := func() bool {
+			if dataBuf.Len() == 0 {
+				return true
+			}
+			data := dataBuf.String()
+			dataBuf.Reset()
+			if data == "[DONE]" {
+				return false
+			}
+			g.handleGeminiStreamResponse(data, state, chunkChan, streamCtx)
+			return true
+		}
+
+		// processSSELine folds one raw line (as read by ReadString, so
+		// still carrying its trailing newline) into dataBuf per the SSE
+		// spec: a blank line ends the current event and flushes dataBuf
+		// unconditionally, a leading ":" marks a comment/keep-alive line to
+		// ignore outright, and anything else prefixed "data:" is appended
+		// (joined by "\n" to whatever's already buffered, again per spec)
+		// rather than parsed on its own. Gemini normally emits one complete
+		// JSON object per data: line with no blank line in between, so
+		// dataBuf is also flushed the moment it holds one complete JSON
+		// value - that keeps the common case dispatching a chunk per line
+		// as before, while a value actually split across several data:
+		// lines keeps accumulating until it parses as whole JSON (or the
+		// blank line forces a flush regardless). Returns true if the
+		// stream should stop (a flushed event's data was the literal
+		// "[DONE]" sentinel).
+		processSSELine := func(raw string) bool {
+			trimmed := strings.TrimRight(raw, "\r\n")
+			switch {
+			case trimmed == "":
+				return !flushDataBuf()
+			case strings.HasPrefix(trimmed, ":"):
+				// SSE comment/keep-alive line - ignore.
+			case strings.HasPrefix(trimmed, "data:"):
+				field := strings.TrimPrefix(strings.TrimPrefix(trimmed, "data:"), " ")
+				if field == "[DONE]" && dataBuf.Len() == 0 {
+					return true
+				}
+				if dataBuf.Len() > 0 {
+					dataBuf.WriteByte('\n')
+				}
+				dataBuf.WriteString(field)
+				if json.Valid([]byte(dataBuf.String())) {
+					return !flushDataBuf()
+				}
+			}
+			return false
+		}
 
 		for {
 			select {
@@ -1078,12 +1875,18 @@ func (g *GeminiLLM) streamRequest(ctx context.Context, reqBody interface{}) (*co
 
 			if readErr != nil {
 				if readErr == io.EOF || streamCtx.Err() != nil {
+					// ReadString returns whatever it read before hitting EOF
+					// alongside the error, so a final event with no
+					// trailing blank line still gets processed rather than
+					// silently dropped.
+					processSSELine(line)
+					flushDataBuf()
 					return
 				}
-				if streamCtx.Err() == nil {
-					chunkChan <- core.StreamChunk{
-						Error: errors.New(errors.LLMGenerationFailed, fmt.Sprintf("stream read error: %v", readErr)),
-					}
+				streamErr = errors.New(errors.LLMGenerationFailed, fmt.Sprintf("stream read error: %v", readErr))
+				select {
+				case chunkChan <- core.StreamChunk{Error: streamErr}:
+				case <-streamCtx.Done():
 				}
 				return
 			}
@@ -1092,25 +1895,8 @@ func (g *GeminiLLM) streamRequest(ctx context.Context, reqBody interface{}) (*co
 				return
 			}
 
-			line = strings.TrimSpace(line)
-			if strings.HasPrefix(line, "data: ") {
-				data := strings.TrimPrefix(line, "data: ")
-
-				if data == "[DONE]" {
-					return
-				}
-
-				var chunk geminiResponse
-				if err := json.Unmarshal([]byte(data), &chunk); err != nil {
-					continue
-				}
-
-				if len(chunk.Candidates) > 0 && len(chunk.Candidates[0].Content.Parts) > 0 {
-					content := chunk.Candidates[0].Content.Parts[0].Text
-					if streamCtx.Err() == nil {
-						chunkChan <- core.StreamChunk{Content: content}
-					}
-				}
+			if processSSELine(line) {
+				return
 			}
 		}
 	}()
@@ -1124,6 +1910,9 @@ func (g *GeminiLLM) StreamGenerate(ctx context.Context, prompt string, options .
 	for _, opt := range options {
 		opt(opts)
 	}
+	if opts.SanitizePrompt {
+		prompt = utils.SanitizeText(prompt)
+	}
 
 	reqBody := geminiRequest{
 		Contents: []geminiContent{
@@ -1133,7 +1922,7 @@ func (g *GeminiLLM) StreamGenerate(ctx context.Context, prompt string, options .
 				},
 			},
 		},
-		GenerationConfig: geminiConfigFromCoreConfig(opts),
+		GenerationConfig: g.geminiConfig(ctx, opts),
 	}
 
 	return g.streamRequest(ctx, reqBody)
@@ -1160,6 +1949,260 @@ func isValidGeminiEmbeddingModel(s string) bool {
 	return false
 }
 
+// GeminiCachedContent is a handle to server-side cached content created by
+// CreateCachedContent. Pass Name as the "cached_content" value in
+// core.WithGenerateParams for later Generate calls that should reuse it
+// instead of resending the same large prefix.
+type GeminiCachedContent struct {
+	Name       string
+	ExpireTime time.Time
+}
+
+type geminiCreateCachedContentRequest struct {
+	Model    string          `json:"model"`
+	Contents []geminiContent `json:"contents"`
+	TTL      string          `json:"ttl"`
+}
+
+type geminiCachedContentResponse struct {
+	Name       string `json:"name"`
+	ExpireTime string `json:"expireTime"`
+}
+
+// CreateCachedContent uploads prefix to Gemini as cached content with the
+// given ttl and returns a handle referencing it. This is meant for a large,
+// static prefix reused across many calls (e.g. a fixed RAG corpus or a long
+// system instruction) - caching it server-side once avoids re-sending and
+// re-billing it on every subsequent Generate call that references the
+// handle's Name via core.WithGenerateParams(map[string]interface{}{"cached_content": handle.Name}).
+func (g *GeminiLLM) CreateCachedContent(ctx context.Context, prefix string, ttl time.Duration) (*GeminiCachedContent, error) {
+	if ttl <= 0 {
+		return nil, errors.New(errors.InvalidInput, "ttl must be positive")
+	}
+
+	reqBody := geminiCreateCachedContentRequest{
+		Model: fmt.Sprintf("models/%s", g.ModelID()),
+		Contents: []geminiContent{
+			{Parts: []geminiPart{{Text: prefix}}},
+		},
+		TTL: fmt.Sprintf("%ds", int(ttl.Seconds())),
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, errors.WithFields(
+			errors.Wrap(err, errors.InvalidInput, "failed to marshal cached content request body"),
+			errors.Fields{"model": g.ModelID()})
+	}
+
+	url := fmt.Sprintf("%s/cachedContents%s",
+		strings.TrimRight(g.GetEndpointConfig().BaseURL, "/"), g.authQuerySuffix())
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, errors.WithFields(
+			errors.Wrap(err, errors.InvalidInput, "failed to create cached content request"),
+			errors.Fields{"model": g.ModelID()})
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := g.setAuthHeader(ctx, req); err != nil {
+		return nil, err
+	}
+
+	resp, err := g.GetHTTPClient().Do(req)
+	if err != nil {
+		return nil, errors.WithFields(
+			errors.New(errors.LLMGenerationFailed, fmt.Sprintf("LLMGenerationFailed: failed to send cached content request: %v", err)),
+			errors.Fields{"model": g.ModelID()})
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithFields(
+			errors.New(errors.LLMGenerationFailed, fmt.Sprintf("LLMGenerationFailed: failed to read cached content response: %v", err)),
+			errors.Fields{"model": g.ModelID()})
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.WithFields(
+			errors.New(errors.LLMGenerationFailed, fmt.Sprintf("LLMGenerationFailed: cached content creation failed with status code %d: %s", resp.StatusCode, string(body))),
+			errors.Fields{"model": g.ModelID(), "statusCode": resp.StatusCode})
+	}
+
+	var cachedResp geminiCachedContentResponse
+	if err := json.Unmarshal(body, &cachedResp); err != nil {
+		return nil, errors.WithFields(
+			errors.New(errors.InvalidResponse, fmt.Sprintf("InvalidResponse: failed to unmarshal cached content response: %v", err)),
+			errors.Fields{"model": g.ModelID()})
+	}
+
+	handle := &GeminiCachedContent{Name: cachedResp.Name}
+	if cachedResp.ExpireTime != "" {
+		if expireTime, err := time.Parse(time.RFC3339, cachedResp.ExpireTime); err == nil {
+			handle.ExpireTime = expireTime
+		}
+	}
+
+	return handle, nil
+}
+
+// DeleteCachedContent deletes a cached content handle previously created by
+// CreateCachedContent, by its Name.
+func (g *GeminiLLM) DeleteCachedContent(ctx context.Context, name string) error {
+	url := fmt.Sprintf("%s/%s%s",
+		strings.TrimRight(g.GetEndpointConfig().BaseURL, "/"), name, g.authQuerySuffix())
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return errors.WithFields(
+			errors.Wrap(err, errors.InvalidInput, "failed to create delete cached content request"),
+			errors.Fields{"name": name})
+	}
+	if err := g.setAuthHeader(ctx, req); err != nil {
+		return err
+	}
+
+	resp, err := g.GetHTTPClient().Do(req)
+	if err != nil {
+		return errors.WithFields(
+			errors.New(errors.LLMGenerationFailed, fmt.Sprintf("LLMGenerationFailed: failed to send delete cached content request: %v", err)),
+			errors.Fields{"name": name})
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return errors.WithFields(
+			errors.New(errors.LLMGenerationFailed, fmt.Sprintf("LLMGenerationFailed: cached content deletion failed with status code %d: %s", resp.StatusCode, string(body))),
+			errors.Fields{"name": name, "statusCode": resp.StatusCode})
+	}
+
+	return nil
+}
+
+type geminiCountTokensRequest struct {
+	Contents []geminiContent `json:"contents"`
+}
+
+type geminiCountTokensResponse struct {
+	TotalTokens int `json:"totalTokens"`
+}
+
+// CountTokensOptions configures a single CountTokens call.
+type CountTokensOptions struct {
+	BypassCache bool
+}
+
+// CountTokensOption allows for optional parameters to CountTokens.
+type CountTokensOption func(*CountTokensOptions)
+
+// WithBypassTokenCountCache forces this CountTokens call to reach the API
+// even when WithTokenCountCache has caching enabled, refreshing the cached
+// entry with the fresh result afterward so later calls can hit it again.
+func WithBypassTokenCountCache() CountTokensOption {
+	return func(o *CountTokensOptions) {
+		o.BypassCache = true
+	}
+}
+
+// CountTokens returns the number of tokens the Gemini API would assign to
+// content, via the model's countTokens endpoint. When WithTokenCountCache
+// has enabled caching, a result is cached by a hash of (model, content),
+// so re-counting the same static prefix - a system instruction, a fixed
+// RAG corpus - across many calls skips the API after the first. Pass
+// WithBypassTokenCountCache to force a fresh call regardless.
+//
+// ctx's deadline governs the whole call, including the underlying HTTP
+// request - there is no separate timeout to configure.
+func (g *GeminiLLM) CountTokens(ctx context.Context, content string, opts ...CountTokensOption) (int, error) {
+	options := &CountTokensOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var cacheKey string
+	if g.tokenCountCache != nil {
+		sum := sha256.Sum256([]byte(g.ModelID() + "\x00" + content))
+		cacheKey = "countTokens:" + hex.EncodeToString(sum[:])
+
+		if !options.BypassCache {
+			if cached, found, err := g.tokenCountCache.Get(ctx, cacheKey); err == nil && found {
+				if count, err := strconv.Atoi(string(cached)); err == nil {
+					return count, nil
+				}
+			}
+		}
+	}
+
+	count, err := g.countTokens(ctx, content)
+	if err != nil {
+		return 0, err
+	}
+
+	if g.tokenCountCache != nil {
+		_ = g.tokenCountCache.Set(ctx, cacheKey, []byte(strconv.Itoa(count)), 0)
+	}
+
+	return count, nil
+}
+
+func (g *GeminiLLM) countTokens(ctx context.Context, content string) (int, error) {
+	reqBody := geminiCountTokensRequest{
+		Contents: []geminiContent{
+			{Parts: []geminiPart{{Text: content}}},
+		},
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return 0, errors.WithFields(
+			errors.Wrap(err, errors.InvalidInput, "failed to marshal count tokens request body"),
+			errors.Fields{"model": g.ModelID()})
+	}
+
+	url := fmt.Sprintf("%s/models/%s:countTokens%s",
+		strings.TrimRight(g.GetEndpointConfig().BaseURL, "/"), g.ModelID(), g.authQuerySuffix())
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return 0, errors.WithFields(
+			errors.Wrap(err, errors.InvalidInput, "failed to create count tokens request"),
+			errors.Fields{"model": g.ModelID()})
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := g.setAuthHeader(ctx, req); err != nil {
+		return 0, err
+	}
+
+	resp, err := g.GetHTTPClient().Do(req)
+	if err != nil {
+		return 0, errors.WithFields(
+			errors.New(errors.LLMGenerationFailed, fmt.Sprintf("LLMGenerationFailed: failed to send count tokens request: %v", err)),
+			errors.Fields{"model": g.ModelID()})
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, errors.WithFields(
+			errors.New(errors.LLMGenerationFailed, fmt.Sprintf("LLMGenerationFailed: failed to read count tokens response: %v", err)),
+			errors.Fields{"model": g.ModelID()})
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, errors.WithFields(
+			errors.New(errors.LLMGenerationFailed, fmt.Sprintf("LLMGenerationFailed: count tokens failed with status code %d: %s", resp.StatusCode, string(body))),
+			errors.Fields{"model": g.ModelID(), "statusCode": resp.StatusCode})
+	}
+
+	var countResp geminiCountTokensResponse
+	if err := json.Unmarshal(body, &countResp); err != nil {
+		return 0, errors.WithFields(
+			errors.New(errors.InvalidResponse, fmt.Sprintf("InvalidResponse: failed to unmarshal count tokens response: %v", err)),
+			errors.Fields{"model": g.ModelID()})
+	}
+
+	return countResp.TotalTokens, nil
+}
+
 func constructRequestURL(endpoint *core.EndpointConfig, apiKey string) string {
 	// Remove any trailing slashes from base URL and leading slashes from path
 	baseURL := strings.TrimRight(endpoint.BaseURL, "/")
@@ -1172,6 +2215,56 @@ func constructRequestURL(endpoint *core.EndpointConfig, apiKey string) string {
 	return fmt.Sprintf("%s?key=%s", fullEndpoint, apiKey)
 }
 
+// requestURL builds the URL for g's configured endpoint: an API key query
+// parameter in the default mode (via constructRequestURL), or the bare
+// endpoint URL in Vertex AI mode, where auth travels via the Authorization
+// header set by setAuthHeader instead.
+func (g *GeminiLLM) requestURL() string {
+	if g.tokenSource == nil {
+		return constructRequestURL(g.GetEndpointConfig(), g.apiKey)
+	}
+	endpoint := g.GetEndpointConfig()
+	baseURL := strings.TrimRight(endpoint.BaseURL, "/")
+	path := strings.TrimLeft(endpoint.Path, "/")
+	return fmt.Sprintf("%s/%s", baseURL, path)
+}
+
+// authQuerySuffix returns "?key=<apiKey>" in the default mode, or "" in
+// Vertex AI mode, for the handful of request helpers (embeddings, cached
+// content) that build their URL directly instead of via requestURL.
+func (g *GeminiLLM) authQuerySuffix() string {
+	if g.tokenSource != nil {
+		return ""
+	}
+	return "?key=" + g.apiKey
+}
+
+// streamQuerySep returns the separator joining "alt=sse" onto requestURL's
+// result: "&" when the API key is already the URL's query parameter, "?"
+// when requestURL returned a bare URL (Vertex AI mode).
+func (g *GeminiLLM) streamQuerySep() string {
+	if g.tokenSource != nil {
+		return "?"
+	}
+	return "&"
+}
+
+// setAuthHeader adds the Authorization: Bearer header for Vertex AI's
+// OAuth mode, refreshing the token via tokenSource on every call since
+// service account tokens expire. It's a no-op in the default API-key
+// mode, where the key already travels as a URL query parameter.
+func (g *GeminiLLM) setAuthHeader(ctx context.Context, req *http.Request) error {
+	if g.tokenSource == nil {
+		return nil
+	}
+	token, err := g.tokenSource.Token(ctx)
+	if err != nil {
+		return errors.Wrap(err, errors.InvalidInput, "failed to obtain Vertex AI OAuth token")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
 // GenerateWithContent implements multimodal content generation for Gemini.
 func (g *GeminiLLM) GenerateWithContent(ctx context.Context, content []core.ContentBlock, options ...core.GenerateOption) (*core.LLMResponse, error) {
 	opts := core.NewGenerateOptions()
@@ -1188,7 +2281,7 @@ func (g *GeminiLLM) GenerateWithContent(ctx context.Context, content []core.Cont
 				Parts: geminiParts,
 			},
 		},
-		GenerationConfig: geminiConfigFromCoreConfig(opts),
+		GenerationConfig: g.geminiConfig(ctx, opts),
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -1204,7 +2297,7 @@ func (g *GeminiLLM) GenerateWithContent(ctx context.Context, content []core.Cont
 	req, err := http.NewRequestWithContext(
 		ctx,
 		"POST",
-		constructRequestURL(g.GetEndpointConfig(), g.apiKey),
+		g.requestURL(),
 		bytes.NewBuffer(jsonData),
 	)
 
@@ -1219,6 +2312,9 @@ func (g *GeminiLLM) GenerateWithContent(ctx context.Context, content []core.Cont
 	for key, value := range g.GetEndpointConfig().Headers {
 		req.Header.Set(key, value)
 	}
+	if err := g.setAuthHeader(ctx, req); err != nil {
+		return nil, err
+	}
 
 	resp, err := g.GetHTTPClient().Do(req)
 	if err != nil {
@@ -1258,11 +2354,7 @@ func (g *GeminiLLM) GenerateWithContent(ctx context.Context, content []core.Cont
 	}
 
 	if len(geminiResp.Candidates) == 0 {
-		return nil, errors.WithFields(
-			errors.New(errors.InvalidResponse, "no candidates in response"),
-			errors.Fields{
-				"model": g.ModelID(),
-			})
+		return nil, noCandidatesError(g.ModelID(), geminiResp.PromptFeedback)
 	}
 
 	if len(geminiResp.Candidates[0].Content.Parts) == 0 {
@@ -1273,19 +2365,38 @@ func (g *GeminiLLM) GenerateWithContent(ctx context.Context, content []core.Cont
 			})
 	}
 
-	content_text := geminiResp.Candidates[0].Content.Parts[0].Text
+	var contentText, thoughtsText strings.Builder
+	for _, part := range geminiResp.Candidates[0].Content.Parts {
+		if part.Thought {
+			thoughtsText.WriteString(part.Text)
+		} else {
+			contentText.WriteString(part.Text)
+		}
+	}
 	usage := &core.TokenInfo{
 		PromptTokens:     geminiResp.UsageMetadata.PromptTokenCount,
 		CompletionTokens: geminiResp.UsageMetadata.CandidatesTokenCount,
 		TotalTokens:      geminiResp.UsageMetadata.TotalTokenCount,
 	}
+	opts.UsageTracker.Record(usage)
 
 	return &core.LLMResponse{
-		Content: content_text,
-		Usage:   usage,
+		Content:  contentText.String(),
+		Thoughts: thoughtsText.String(),
+		Usage:    usage,
 	}, nil
 }
 
+// GenerateParts sends an ordered sequence of text, image, or audio content
+// blocks as a single prompt turn - e.g. "describe this image" text, then the
+// image, then a closing instruction. It's a named entry point for that
+// use case; the parts are mapped directly to geminiContent.Parts in order
+// by GenerateWithContent, which already carries the conversion and request
+// logic.
+func (g *GeminiLLM) GenerateParts(ctx context.Context, parts []core.ContentBlock, options ...core.GenerateOption) (*core.LLMResponse, error) {
+	return g.GenerateWithContent(ctx, parts, options...)
+}
+
 // StreamGenerateWithContent implements multimodal streaming for Gemini.
 func (g *GeminiLLM) StreamGenerateWithContent(ctx context.Context, content []core.ContentBlock, options ...core.GenerateOption) (*core.StreamResponse, error) {
 	opts := core.NewGenerateOptions()
@@ -1302,7 +2413,7 @@ func (g *GeminiLLM) StreamGenerateWithContent(ctx context.Context, content []cor
 				Parts: geminiParts,
 			},
 		},
-		GenerationConfig: geminiConfigFromCoreConfig(opts),
+		GenerationConfig: g.geminiConfig(ctx, opts),
 	}
 
 	return g.streamRequest(ctx, reqBody)
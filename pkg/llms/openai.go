@@ -252,6 +252,7 @@ func (o *OpenAILLM) Generate(ctx context.Context, prompt string, options ...core
 	if len(opts.Stop) > 0 {
 		request.Stop = opts.Stop
 	}
+	request.ResponseFormat = openAIResponseFormat(opts)
 
 	response, err := o.makeRequest(ctx, request)
 	if err != nil {
@@ -279,6 +280,29 @@ func (o *OpenAILLM) Generate(ctx context.Context, prompt string, options ...core
 	}, nil
 }
 
+// openAIResponseFormat translates the provider-neutral
+// ResponseMIMEType/ResponseSchema options (see core.WithJSONMode and
+// core.WithResponseSchema) into OpenAI's response_format: nil when JSON
+// mode wasn't requested, "json_object" when it was requested with no
+// schema, and "json_schema" - with the schema converted via
+// TypeSchema.ToJSONSchema - when one was given.
+func openAIResponseFormat(opts *core.GenerateOptions) *openai.ResponseFormat {
+	if opts.ResponseMIMEType != "application/json" {
+		return nil
+	}
+	if opts.ResponseSchema == nil {
+		return &openai.ResponseFormat{Type: "json_object"}
+	}
+	return &openai.ResponseFormat{
+		Type: "json_schema",
+		JSONSchema: &openai.ResponseFormatJSONSchema{
+			Name:   "response",
+			Schema: opts.ResponseSchema.ToJSONSchema(),
+			Strict: true,
+		},
+	}
+}
+
 // GenerateWithJSON implements the core.LLM interface.
 func (o *OpenAILLM) GenerateWithJSON(ctx context.Context, prompt string, options ...core.GenerateOption) (map[string]interface{}, error) {
 	opts := core.NewGenerateOptions()
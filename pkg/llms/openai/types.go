@@ -47,7 +47,20 @@ type CompletionUsage struct {
 
 // ResponseFormat specifies the format of the response.
 type ResponseFormat struct {
-	Type string `json:"type"` // "text" or "json_object"
+	Type string `json:"type"` // "text", "json_object", or "json_schema"
+
+	// JSONSchema carries the schema constraint for Type == "json_schema".
+	// Unused (and omitted) for "text"/"json_object".
+	JSONSchema *ResponseFormatJSONSchema `json:"json_schema,omitempty"`
+}
+
+// ResponseFormatJSONSchema is ResponseFormat's "json_schema" payload,
+// matching the OpenAI Chat Completions API's response_format.json_schema
+// shape.
+type ResponseFormatJSONSchema struct {
+	Name   string                 `json:"name"`
+	Schema map[string]interface{} `json:"schema"`
+	Strict bool                   `json:"strict,omitempty"`
 }
 
 // ChatCompletionStreamResponse represents a streaming response chunk.
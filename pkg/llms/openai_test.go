@@ -15,6 +15,7 @@ import (
 	"github.com/darwishdev/dspy-go/pkg/core"
 	"github.com/darwishdev/dspy-go/pkg/errors"
 	"github.com/darwishdev/dspy-go/pkg/llms/openai"
+	"github.com/darwishdev/dspy-go/pkg/utils"
 )
 
 func TestNewOpenAILLM(t *testing.T) {
@@ -904,6 +905,70 @@ func TestOpenAILLM_GenerateWithOptions(t *testing.T) {
 	}
 }
 
+func TestOpenAILLM_GenerateWithJSONMode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read request body: %v", err)
+		}
+
+		var req openai.ChatCompletionRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("failed to parse request body: %v", err)
+		}
+
+		if req.ResponseFormat == nil {
+			t.Fatal("expected response_format to be set")
+		}
+		if req.ResponseFormat.Type != "json_schema" {
+			t.Errorf("expected response_format type json_schema, got %s", req.ResponseFormat.Type)
+		}
+		if req.ResponseFormat.JSONSchema == nil {
+			t.Fatal("expected json_schema payload to be set")
+		}
+		if req.ResponseFormat.JSONSchema.Schema["type"] != "object" {
+			t.Errorf("expected schema type object, got %v", req.ResponseFormat.JSONSchema.Schema["type"])
+		}
+
+		response := openai.ChatCompletionResponse{
+			ID:    "test-id",
+			Model: "gpt-4",
+			Choices: []openai.ChatChoice{
+				{Message: openai.ChatCompletionMessage{Role: "assistant", Content: `{"answer":"42"}`}, FinishReason: "stop"},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	config := core.ProviderConfig{
+		Name:   "openai",
+		APIKey: "test-api-key",
+		Endpoint: &core.EndpointConfig{
+			BaseURL:    server.URL,
+			TimeoutSec: 30,
+		},
+	}
+
+	ctx := context.Background()
+	llm, err := NewOpenAILLMFromConfig(ctx, config, core.ModelOpenAIGPT4)
+	if err != nil {
+		t.Fatalf("failed to create LLM: %v", err)
+	}
+
+	schema := &utils.TypeSchema{Type: string(utils.TypeObject)}
+	response, err := llm.Generate(ctx, "Hello", core.WithJSONMode(schema))
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	if response.Content != `{"answer":"42"}` {
+		t.Errorf("expected JSON content, got %s", response.Content)
+	}
+}
+
 func TestOpenAILLM_GenerateWithFunctions(t *testing.T) {
 	llm, err := NewOpenAI(core.ModelOpenAIGPT4, "test-api-key")
 	if err != nil {
@@ -998,7 +1063,7 @@ func TestNewOpenAILLM_Options(t *testing.T) {
 			expectError: true,
 		},
 		{
-			name: "environment variable fallback",
+			name:    "environment variable fallback",
 			options: []OpenAIOption{
 				// No explicit API key, should fall back to environment
 			},
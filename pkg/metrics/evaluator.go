@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Evaluator scores a single prediction against its expected value, unlike
+// the expected/actual map metric functions in accuracy.go which score a whole
+// program output at once. prediction and expected may each be a
+// map[string]interface{}, a struct (typed output), or a bare scalar - they
+// are normalized internally via toFieldMap before scoring.
+type Evaluator interface {
+	Evaluate(prediction, expected any) (float64, error)
+}
+
+// ExactMatchEvaluator scores 1.0 when prediction and expected are deeply
+// equal field-for-field, 0.0 otherwise.
+type ExactMatchEvaluator struct{}
+
+// Evaluate implements Evaluator.
+func (ExactMatchEvaluator) Evaluate(prediction, expected any) (float64, error) {
+	predMap, expectedMap, err := normalizePair(prediction, expected)
+	if err != nil {
+		return 0, err
+	}
+	return ExactMatch(expectedMap, predMap), nil
+}
+
+// F1Evaluator scores the token-set F1 overlap between string-valued fields
+// of prediction and expected.
+type F1Evaluator struct{}
+
+// Evaluate implements Evaluator.
+func (F1Evaluator) Evaluate(prediction, expected any) (float64, error) {
+	predMap, expectedMap, err := normalizePair(prediction, expected)
+	if err != nil {
+		return 0, err
+	}
+	return F1Score(expectedMap, predMap), nil
+}
+
+// ToleranceEvaluator scores numeric fields as a match when they fall within
+// Tolerance of the expected value, and returns the fraction of expected
+// fields that matched (unlike ExactMatch, which returns 0 unless every
+// field matches).
+type ToleranceEvaluator struct {
+	Tolerance float64
+}
+
+// Evaluate implements Evaluator.
+func (e ToleranceEvaluator) Evaluate(prediction, expected any) (float64, error) {
+	predMap, expectedMap, err := normalizePair(prediction, expected)
+	if err != nil {
+		return 0, err
+	}
+	if len(expectedMap) == 0 {
+		return 0, fmt.Errorf("metrics: expected value has no fields to compare")
+	}
+
+	var matched, total int
+	for key, expectedValue := range expectedMap {
+		actualValue, ok := predMap[key]
+		if !ok {
+			total++
+			continue
+		}
+
+		expectedNum, ok := toFloat64(expectedValue)
+		if !ok {
+			total++
+			if reflect.DeepEqual(expectedValue, actualValue) {
+				matched++
+			}
+			continue
+		}
+		actualNum, ok := toFloat64(actualValue)
+		if !ok {
+			total++
+			continue
+		}
+
+		total++
+		if math.Abs(expectedNum-actualNum) <= e.Tolerance {
+			matched++
+		}
+	}
+
+	if total == 0 {
+		return 0, nil
+	}
+	return float64(matched) / float64(total), nil
+}
+
+// normalizePair converts prediction and expected into field maps suitable
+// for the expected/actual metric functions.
+func normalizePair(prediction, expected any) (predMap, expectedMap map[string]interface{}, err error) {
+	predMap, err = toFieldMap(prediction)
+	if err != nil {
+		return nil, nil, fmt.Errorf("metrics: normalizing prediction: %w", err)
+	}
+	expectedMap, err = toFieldMap(expected)
+	if err != nil {
+		return nil, nil, fmt.Errorf("metrics: normalizing expected value: %w", err)
+	}
+	return predMap, expectedMap, nil
+}
+
+// toFieldMap normalizes v into a map[string]interface{} so it can be
+// compared field-by-field: a map[string]interface{} is returned as-is, a
+// struct is flattened using its json tags (falling back to the field name),
+// and any other value - a bare string, number, etc. - is wrapped under a
+// single "value" key.
+func toFieldMap(v any) (map[string]interface{}, error) {
+	if v == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	if m, ok := v.(map[string]interface{}); ok {
+		return m, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return map[string]interface{}{}, nil
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return map[string]interface{}{"value": v}, nil
+	}
+
+	result := make(map[string]interface{}, rv.NumField())
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+
+		name := field.Name
+		if tag := field.Tag.Get("json"); tag != "" {
+			if tagName := strings.Split(tag, ",")[0]; tagName != "" {
+				name = tagName
+			}
+		}
+
+		result[name] = rv.Field(i).Interface()
+	}
+	return result, nil
+}
+
+// toFloat64 converts a numeric value of any of Go's built-in numeric kinds
+// to float64.
+func toFloat64(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
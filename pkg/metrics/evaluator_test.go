@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExactMatchEvaluator(t *testing.T) {
+	var e ExactMatchEvaluator
+
+	score, err := e.Evaluate("hello", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, score)
+
+	score, err = e.Evaluate("hello", "world")
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, score)
+
+	score, err = e.Evaluate(
+		map[string]interface{}{"answer": "hello"},
+		map[string]interface{}{"answer": "hello"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, score)
+}
+
+func TestExactMatchEvaluatorTypedOutput(t *testing.T) {
+	type Answer struct {
+		Text string `json:"text"`
+	}
+	var e ExactMatchEvaluator
+
+	score, err := e.Evaluate(Answer{Text: "hello"}, Answer{Text: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, score)
+
+	score, err = e.Evaluate(Answer{Text: "hello"}, Answer{Text: "goodbye"})
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, score)
+}
+
+func TestF1Evaluator(t *testing.T) {
+	var e F1Evaluator
+
+	score, err := e.Evaluate("the cat sat", "the cat sat on the mat")
+	require.NoError(t, err)
+	assert.Greater(t, score, 0.0)
+	assert.Less(t, score, 1.0)
+
+	score, err = e.Evaluate("the cat sat", "the cat sat")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, score)
+}
+
+func TestToleranceEvaluator(t *testing.T) {
+	e := ToleranceEvaluator{Tolerance: 0.5}
+
+	score, err := e.Evaluate(9.8, 10.0)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, score)
+
+	score, err = e.Evaluate(5.0, 10.0)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, score)
+}
+
+func TestToleranceEvaluatorMultipleFields(t *testing.T) {
+	e := ToleranceEvaluator{Tolerance: 1}
+
+	score, err := e.Evaluate(
+		map[string]interface{}{"a": 1.2, "b": 10.0},
+		map[string]interface{}{"a": 1.0, "b": 5.0},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, score)
+}
+
+func TestToleranceEvaluatorNoFields(t *testing.T) {
+	e := ToleranceEvaluator{Tolerance: 0.5}
+
+	_, err := e.Evaluate(map[string]interface{}{}, map[string]interface{}{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,183 @@
+// Package middleware provides composable cross-cutting behavior for
+// core.LLM implementations - logging, retry, and caching - without
+// subclassing any specific provider.
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/darwishdev/dspy-go/pkg/cache"
+	"github.com/darwishdev/dspy-go/pkg/config"
+	"github.com/darwishdev/dspy-go/pkg/core"
+	"github.com/darwishdev/dspy-go/pkg/logging"
+)
+
+// LLMMiddleware wraps an LLM with additional behavior, returning a new LLM
+// that still satisfies core.LLM. A middleware's returned LLM typically
+// embeds the one it wraps and overrides only the methods it cares about, so
+// ProviderName, ModelID, Capabilities, and any method it leaves untouched
+// fall through to the wrapped LLM for free.
+type LLMMiddleware func(core.LLM) core.LLM
+
+// Chain wraps llm with mw in order: mw[0] is outermost, so it's the first
+// to see a call and the last to see its result, and mw[len(mw)-1] sits
+// closest to llm itself. This matches the "first middleware runs first"
+// convention most Go HTTP middleware chains use.
+func Chain(llm core.LLM, mw ...LLMMiddleware) core.LLM {
+	for i := len(mw) - 1; i >= 0; i-- {
+		llm = mw[i](llm)
+	}
+	return llm
+}
+
+// LoggingMiddleware logs Generate, GenerateWithJSON, and StreamGenerate
+// calls through logging.GetLogger(), recording the wrapped LLM's provider
+// and model, the call's latency, and any error. It observes calls without
+// altering their behavior or results.
+func LoggingMiddleware() LLMMiddleware {
+	return func(llm core.LLM) core.LLM {
+		return &loggingLLM{LLM: llm}
+	}
+}
+
+type loggingLLM struct {
+	core.LLM
+}
+
+func (l *loggingLLM) Generate(ctx context.Context, prompt string, options ...core.GenerateOption) (*core.LLMResponse, error) {
+	start := time.Now()
+	resp, err := l.LLM.Generate(ctx, prompt, options...)
+	l.log(ctx, "Generate", start, err)
+	return resp, err
+}
+
+func (l *loggingLLM) GenerateWithJSON(ctx context.Context, prompt string, options ...core.GenerateOption) (map[string]interface{}, error) {
+	start := time.Now()
+	resp, err := l.LLM.GenerateWithJSON(ctx, prompt, options...)
+	l.log(ctx, "GenerateWithJSON", start, err)
+	return resp, err
+}
+
+func (l *loggingLLM) StreamGenerate(ctx context.Context, prompt string, options ...core.GenerateOption) (*core.StreamResponse, error) {
+	start := time.Now()
+	resp, err := l.LLM.StreamGenerate(ctx, prompt, options...)
+	l.log(ctx, "StreamGenerate", start, err)
+	return resp, err
+}
+
+func (l *loggingLLM) log(ctx context.Context, method string, start time.Time, err error) {
+	logger := logging.GetLogger()
+	latency := time.Since(start)
+	if err != nil {
+		logger.Warn(ctx, "%s/%s %s failed after %s: %v", l.ProviderName(), l.ModelID(), method, latency, err)
+		return
+	}
+	logger.Debug(ctx, "%s/%s %s succeeded in %s", l.ProviderName(), l.ModelID(), method, latency)
+}
+
+func (l *loggingLLM) Unwrap() core.LLM {
+	return l.LLM
+}
+
+// RetryConfig configures RetryMiddleware's backoff behavior, mirroring
+// interceptors.RetryConfig's shape so retry-with-backoff is configured the
+// same way across the codebase regardless of which layer it's applied at.
+type RetryConfig struct {
+	MaxAttempts int
+	Delay       time.Duration
+	MaxBackoff  time.Duration // Maximum delay between retries.
+	Backoff     float64       // Multiplier applied to Delay after each attempt.
+}
+
+// RetryMiddleware retries a failing Generate, GenerateWithJSON, or
+// StreamGenerate call, making at most config.MaxAttempts calls in total
+// (the first call included), waiting config.Delay
+// (scaled by config.Backoff after each attempt, capped at config.MaxBackoff)
+// between attempts.
+func RetryMiddleware(config RetryConfig) LLMMiddleware {
+	return func(llm core.LLM) core.LLM {
+		return &retryLLM{LLM: llm, config: config}
+	}
+}
+
+type retryLLM struct {
+	core.LLM
+	config RetryConfig
+}
+
+func (r *retryLLM) Generate(ctx context.Context, prompt string, options ...core.GenerateOption) (*core.LLMResponse, error) {
+	var resp *core.LLMResponse
+	err := r.retry(ctx, func() error {
+		var callErr error
+		resp, callErr = r.LLM.Generate(ctx, prompt, options...)
+		return callErr
+	})
+	return resp, err
+}
+
+func (r *retryLLM) GenerateWithJSON(ctx context.Context, prompt string, options ...core.GenerateOption) (map[string]interface{}, error) {
+	var resp map[string]interface{}
+	err := r.retry(ctx, func() error {
+		var callErr error
+		resp, callErr = r.LLM.GenerateWithJSON(ctx, prompt, options...)
+		return callErr
+	})
+	return resp, err
+}
+
+func (r *retryLLM) StreamGenerate(ctx context.Context, prompt string, options ...core.GenerateOption) (*core.StreamResponse, error) {
+	var resp *core.StreamResponse
+	err := r.retry(ctx, func() error {
+		var callErr error
+		resp, callErr = r.LLM.StreamGenerate(ctx, prompt, options...)
+		return callErr
+	})
+	return resp, err
+}
+
+func (r *retryLLM) retry(ctx context.Context, fn func() error) error {
+	var lastErr error
+	delay := r.config.Delay
+
+	for attempt := 1; attempt <= r.config.MaxAttempts; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if attempt == r.config.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay = time.Duration(float64(delay) * r.config.Backoff)
+		if r.config.MaxBackoff > 0 && delay > r.config.MaxBackoff {
+			delay = r.config.MaxBackoff
+		}
+	}
+
+	return fmt.Errorf("%s/%s: failed after %d attempts: %w", r.ProviderName(), r.ModelID(), r.config.MaxAttempts, lastErr)
+}
+
+func (r *retryLLM) Unwrap() core.LLM {
+	return r.LLM
+}
+
+// CacheMiddleware adapts cache.WrapWithCache to the LLMMiddleware shape, so
+// caching composes with LoggingMiddleware and RetryMiddleware via Chain
+// instead of needing its own call site. The caching behavior itself - key
+// generation, TTL, the global cache instance - still lives in pkg/cache;
+// this is a thin adapter, not a reimplementation.
+func CacheMiddleware(cfg *config.CachingConfig) LLMMiddleware {
+	return func(llm core.LLM) core.LLM {
+		return cache.WrapWithCache(llm, cfg)
+	}
+}
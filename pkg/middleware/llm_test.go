@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/darwishdev/dspy-go/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLLM is a minimal hand-rolled core.LLM test double: it returns
+// canned responses, and Generate fails generateFailures times before
+// succeeding, to exercise RetryMiddleware without a mocking framework.
+type fakeLLM struct {
+	provider          string
+	model             string
+	generateFailures  int
+	generateCallCount int
+}
+
+func (f *fakeLLM) Generate(ctx context.Context, prompt string, options ...core.GenerateOption) (*core.LLMResponse, error) {
+	f.generateCallCount++
+	if f.generateCallCount <= f.generateFailures {
+		return nil, errors.New("transient failure")
+	}
+	return &core.LLMResponse{Content: "ok"}, nil
+}
+
+func (f *fakeLLM) GenerateWithJSON(ctx context.Context, prompt string, options ...core.GenerateOption) (map[string]interface{}, error) {
+	return map[string]interface{}{"answer": "ok"}, nil
+}
+
+func (f *fakeLLM) GenerateWithFunctions(ctx context.Context, prompt string, functions []map[string]interface{}, options ...core.GenerateOption) (map[string]interface{}, error) {
+	return map[string]interface{}{}, nil
+}
+
+func (f *fakeLLM) CreateEmbedding(ctx context.Context, input string, options ...core.EmbeddingOption) (*core.EmbeddingResult, error) {
+	return &core.EmbeddingResult{}, nil
+}
+
+func (f *fakeLLM) CreateEmbeddings(ctx context.Context, inputs []string, options ...core.EmbeddingOption) (*core.BatchEmbeddingResult, error) {
+	return &core.BatchEmbeddingResult{}, nil
+}
+
+func (f *fakeLLM) StreamGenerate(ctx context.Context, prompt string, options ...core.GenerateOption) (*core.StreamResponse, error) {
+	return &core.StreamResponse{}, nil
+}
+
+func (f *fakeLLM) GenerateWithContent(ctx context.Context, content []core.ContentBlock, options ...core.GenerateOption) (*core.LLMResponse, error) {
+	return &core.LLMResponse{Content: "ok"}, nil
+}
+
+func (f *fakeLLM) StreamGenerateWithContent(ctx context.Context, content []core.ContentBlock, options ...core.GenerateOption) (*core.StreamResponse, error) {
+	return &core.StreamResponse{}, nil
+}
+
+func (f *fakeLLM) ProviderName() string { return f.provider }
+func (f *fakeLLM) ModelID() string      { return f.model }
+func (f *fakeLLM) Capabilities() []core.Capability {
+	return []core.Capability{core.CapabilityCompletion}
+}
+
+func TestChainAppliesMiddlewaresOutermostFirst(t *testing.T) {
+	var order []string
+	record := func(name string) LLMMiddleware {
+		return func(llm core.LLM) core.LLM {
+			order = append(order, name)
+			return llm
+		}
+	}
+
+	Chain(&fakeLLM{}, record("first"), record("second"))
+
+	assert.Equal(t, []string{"second", "first"}, order)
+}
+
+func TestChainWithNoMiddlewaresReturnsLLMUnchanged(t *testing.T) {
+	llm := &fakeLLM{provider: "fake", model: "fake-1"}
+
+	wrapped := Chain(llm)
+
+	assert.Same(t, llm, wrapped)
+}
+
+func TestLoggingMiddlewareForwardsGenerate(t *testing.T) {
+	llm := &fakeLLM{provider: "fake", model: "fake-1"}
+	wrapped := Chain(llm, LoggingMiddleware())
+
+	resp, err := wrapped.Generate(context.Background(), "hello")
+
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Content)
+	assert.Equal(t, 1, llm.generateCallCount)
+}
+
+func TestRetryMiddlewareRetriesUntilSuccess(t *testing.T) {
+	llm := &fakeLLM{provider: "fake", model: "fake-1", generateFailures: 2}
+	wrapped := Chain(llm, RetryMiddleware(RetryConfig{
+		MaxAttempts: 5,
+		Delay:       time.Millisecond,
+		Backoff:     1,
+	}))
+
+	resp, err := wrapped.Generate(context.Background(), "hello")
+
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Content)
+	assert.Equal(t, 3, llm.generateCallCount)
+}
+
+func TestRetryMiddlewareGivesUpAfterMaxAttempts(t *testing.T) {
+	llm := &fakeLLM{provider: "fake", model: "fake-1", generateFailures: 10}
+	wrapped := Chain(llm, RetryMiddleware(RetryConfig{
+		MaxAttempts: 3,
+		Delay:       time.Millisecond,
+		Backoff:     1,
+	}))
+
+	_, err := wrapped.Generate(context.Background(), "hello")
+
+	require.Error(t, err)
+	assert.Equal(t, 3, llm.generateCallCount)
+}
+
+// TestLoggingAndRetryMiddlewaresCompose confirms two middlewares chained
+// together both take effect on the same call: LoggingMiddleware observes
+// the call (it doesn't touch generateCallCount), and RetryMiddleware
+// beneath it keeps retrying until fakeLLM stops failing.
+func TestLoggingAndRetryMiddlewaresCompose(t *testing.T) {
+	llm := &fakeLLM{provider: "fake", model: "fake-1", generateFailures: 1}
+	wrapped := Chain(llm,
+		LoggingMiddleware(),
+		RetryMiddleware(RetryConfig{
+			MaxAttempts: 3,
+			Delay:       time.Millisecond,
+			Backoff:     1,
+		}),
+	)
+
+	resp, err := wrapped.Generate(context.Background(), "hello")
+
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Content)
+	assert.Equal(t, 2, llm.generateCallCount)
+
+	// ProviderName/ModelID fall through both middlewares to fakeLLM
+	// untouched, since neither overrides them.
+	assert.Equal(t, "fake", wrapped.ProviderName())
+	assert.Equal(t, "fake-1", wrapped.ModelID())
+}
@@ -0,0 +1,121 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/darwishdev/dspy-go/pkg/core"
+)
+
+// Constraint is a DSPy-style assertion over a module's typed output,
+// constructed via Assert or Suggest rather than directly.
+type Constraint[TOutput any] struct {
+	// Predicate inspects output and returns nil if it satisfies the
+	// constraint, or a descriptive error otherwise. That error's message is
+	// what gets appended to the prompt when the constraint is a Suggest.
+	Predicate func(output TOutput) error
+	hard      bool
+}
+
+// Assert registers a hard constraint: a Predicate violation fails
+// ProcessTypedWithConstraints immediately, without retrying.
+func Assert[TOutput any](predicate func(output TOutput) error) Constraint[TOutput] {
+	return Constraint[TOutput]{Predicate: predicate, hard: true}
+}
+
+// Suggest registers a soft constraint: a Predicate violation triggers a
+// re-generation with the violation message appended to the prompt, up to
+// ProcessTypedWithConstraints' maxRetries attempts, before it gives up and
+// returns the last violation as an error.
+func Suggest[TOutput any](predicate func(output TOutput) error) Constraint[TOutput] {
+	return Constraint[TOutput]{Predicate: predicate, hard: false}
+}
+
+// ConstraintResult wraps a constrained call's typed output together with how
+// many re-generation attempts it took to satisfy every Suggest constraint.
+type ConstraintResult[TOutput any] struct {
+	Output  TOutput
+	Retries int
+}
+
+// ProcessTypedWithConstraints runs predict via ProcessTyped and checks the
+// result against constraints, in the order given. If any Assert constraint
+// is violated, it fails immediately. Otherwise, if any Suggest constraint is
+// violated, its message is appended to the signature's instruction and the
+// call is retried, up to maxRetries additional attempts, before
+// ProcessTypedWithConstraints gives up and returns the last violation as an
+// error. predict's signature is restored to its original value before
+// returning, so a failed run doesn't leave a mutated instruction behind.
+func ProcessTypedWithConstraints[TInput, TOutput any](ctx context.Context, predict *Predict, inputs TInput, constraints []Constraint[TOutput], maxRetries int, opts ...core.Option) (ConstraintResult[TOutput], error) {
+	var zero ConstraintResult[TOutput]
+
+	originalSignature := predict.GetSignature()
+	baseInstruction := originalSignature.Instruction
+	defer predict.SetSignature(originalSignature)
+
+	var lastSuggestion error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		output, err := ProcessTyped[TInput, TOutput](ctx, predict, inputs, opts...)
+		if err != nil {
+			return zero, err
+		}
+
+		if violation := firstViolated(constraints, output, true); violation != nil {
+			return zero, fmt.Errorf("assertion failed: %w", violation)
+		}
+
+		violation := firstViolated(constraints, output, false)
+		if violation == nil {
+			return ConstraintResult[TOutput]{Output: output, Retries: attempt}, nil
+		}
+
+		lastSuggestion = violation
+		if attempt == maxRetries {
+			break
+		}
+
+		recordRetryAttempt(predict.LLM)
+		predict.SetSignature(originalSignature.WithInstruction(
+			appendSuggestion(baseInstruction, violation)))
+	}
+
+	return zero, fmt.Errorf("suggestion failed after %d retries: %w", maxRetries, lastSuggestion)
+}
+
+// firstViolated returns the error from the first constraint of the given
+// hardness whose Predicate rejects output, or nil if none do.
+func firstViolated[TOutput any](constraints []Constraint[TOutput], output TOutput, hard bool) error {
+	for _, c := range constraints {
+		if c.hard != hard {
+			continue
+		}
+		if err := c.Predicate(output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordRetryAttempt reports a suggestion-driven retry to llm's MetricsHook,
+// if llm exposes one via core.MetricsHookProvider. This lets dashboards that
+// already track an LLM's request metrics see its retry rate alongside them.
+func recordRetryAttempt(llm core.LLM) {
+	provider, ok := llm.(core.MetricsHookProvider)
+	if !ok {
+		return
+	}
+	provider.GetMetricsHook().IncrementCounter("llm_retry_attempts_total", map[string]string{
+		"provider": llm.ProviderName(),
+		"model":    llm.ModelID(),
+	})
+}
+
+// appendSuggestion appends a constraint violation to base as a correction
+// instruction for the next generation attempt.
+func appendSuggestion(base string, violation error) string {
+	note := fmt.Sprintf("Your previous answer violated a constraint: %s. Please correct this and try again.", violation)
+	if base == "" {
+		return note
+	}
+	return base + "\n\n" + note
+}
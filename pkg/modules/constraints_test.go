@@ -0,0 +1,177 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/darwishdev/dspy-go/internal/testutil"
+	"github.com/darwishdev/dspy-go/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func confidenceAbove(min int) Constraint[TestQAOutputs] {
+	return Suggest(func(out TestQAOutputs) error {
+		if out.Confidence < min {
+			return errors.New("confidence must be above 80")
+		}
+		return nil
+	})
+}
+
+func TestProcessTypedWithConstraints_SucceedsFirstTry(t *testing.T) {
+	mockLLM := new(testutil.MockLLM)
+	mockLLM.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(&core.LLMResponse{
+		Content: "Answer:\nyes\n\nConfidence:\n90",
+	}, nil).Once()
+
+	predict := NewTypedPredict[TestQAInputs, TestQAOutputs]()
+	predict.SetLLM(mockLLM)
+
+	ctx := core.WithExecutionState(context.Background())
+	inputs := TestQAInputs{Question: "ok?", Context: "ctx"}
+
+	result, err := ProcessTypedWithConstraints[TestQAInputs, TestQAOutputs](
+		ctx, predict, inputs, []Constraint[TestQAOutputs]{confidenceAbove(80)}, 3)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Retries)
+	assert.Equal(t, 90, result.Output.Confidence)
+	mockLLM.AssertExpectations(t)
+}
+
+func TestProcessTypedWithConstraints_RetriesUntilSuggestionSatisfied(t *testing.T) {
+	mockLLM := new(testutil.MockLLM)
+	mockLLM.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(&core.LLMResponse{
+		Content: "Answer:\nyes\n\nConfidence:\n50",
+	}, nil).Once()
+	mockLLM.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(&core.LLMResponse{
+		Content: "Answer:\nyes\n\nConfidence:\n95",
+	}, nil).Once()
+
+	predict := NewTypedPredict[TestQAInputs, TestQAOutputs]()
+	predict.SetLLM(mockLLM)
+	originalInstruction := predict.GetSignature().Instruction
+
+	ctx := core.WithExecutionState(context.Background())
+	inputs := TestQAInputs{Question: "ok?", Context: "ctx"}
+
+	result, err := ProcessTypedWithConstraints[TestQAInputs, TestQAOutputs](
+		ctx, predict, inputs, []Constraint[TestQAOutputs]{confidenceAbove(80)}, 3)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Retries)
+	assert.Equal(t, 95, result.Output.Confidence)
+	assert.Equal(t, originalInstruction, predict.GetSignature().Instruction, "signature should be restored after retrying")
+	mockLLM.AssertExpectations(t)
+}
+
+func TestProcessTypedWithConstraints_SuggestionFailsAfterMaxRetries(t *testing.T) {
+	mockLLM := new(testutil.MockLLM)
+	mockLLM.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(&core.LLMResponse{
+		Content: "Answer:\nyes\n\nConfidence:\n10",
+	}, nil)
+
+	predict := NewTypedPredict[TestQAInputs, TestQAOutputs]()
+	predict.SetLLM(mockLLM)
+
+	ctx := core.WithExecutionState(context.Background())
+	inputs := TestQAInputs{Question: "ok?", Context: "ctx"}
+
+	_, err := ProcessTypedWithConstraints[TestQAInputs, TestQAOutputs](
+		ctx, predict, inputs, []Constraint[TestQAOutputs]{confidenceAbove(80)}, 2)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "suggestion failed after 2 retries")
+	mockLLM.AssertNumberOfCalls(t, "Generate", 3)
+}
+
+func TestProcessTypedWithConstraints_AssertFailsImmediatelyWithoutRetry(t *testing.T) {
+	mockLLM := new(testutil.MockLLM)
+	mockLLM.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(&core.LLMResponse{
+		Content: "Answer:\nyes\n\nConfidence:\n10",
+	}, nil).Once()
+
+	predict := NewTypedPredict[TestQAInputs, TestQAOutputs]()
+	predict.SetLLM(mockLLM)
+
+	mustBeNonNegative := Assert(func(out TestQAOutputs) error {
+		if out.Confidence < 0 {
+			return errors.New("confidence must not be negative")
+		}
+		return nil
+	})
+	mustBeHigh := Assert(func(out TestQAOutputs) error {
+		if out.Confidence < 50 {
+			return errors.New("confidence must be at least 50")
+		}
+		return nil
+	})
+
+	ctx := core.WithExecutionState(context.Background())
+	inputs := TestQAInputs{Question: "ok?", Context: "ctx"}
+
+	_, err := ProcessTypedWithConstraints[TestQAInputs, TestQAOutputs](
+		ctx, predict, inputs, []Constraint[TestQAOutputs]{mustBeNonNegative, mustBeHigh}, 3)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "assertion failed")
+	assert.Contains(t, err.Error(), "confidence must be at least 50")
+	mockLLM.AssertExpectations(t)
+	mockLLM.AssertNumberOfCalls(t, "Generate", 1)
+}
+
+// metricsMockLLM wraps testutil.MockLLM with a MetricsHook so tests can
+// assert ProcessTypedWithConstraints reports retry attempts through
+// core.MetricsHookProvider.
+type metricsMockLLM struct {
+	*testutil.MockLLM
+	hook core.MetricsHook
+}
+
+func (m *metricsMockLLM) GetMetricsHook() core.MetricsHook {
+	return m.hook
+}
+
+type countingMetricsHook struct {
+	retryAttempts int
+}
+
+func (h *countingMetricsHook) IncrementCounter(name string, tags map[string]string) {
+	if name == "llm_retry_attempts_total" {
+		h.retryAttempts++
+	}
+}
+
+func (h *countingMetricsHook) ObserveHistogram(name string, value float64, tags map[string]string) {}
+
+func TestProcessTypedWithConstraints_RecordsRetryAttemptsToMetricsHook(t *testing.T) {
+	mockLLM := new(testutil.MockLLM)
+	mockLLM.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(&core.LLMResponse{
+		Content: "Answer:\nyes\n\nConfidence:\n10",
+	}, nil).Once()
+	mockLLM.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(&core.LLMResponse{
+		Content: "Answer:\nyes\n\nConfidence:\n90",
+	}, nil).Once()
+
+	mockLLM.On("ProviderName").Return("mock")
+	mockLLM.On("ModelID").Return("test-model")
+
+	hook := &countingMetricsHook{}
+	llm := &metricsMockLLM{MockLLM: mockLLM, hook: hook}
+
+	predict := NewTypedPredict[TestQAInputs, TestQAOutputs]()
+	predict.SetLLM(llm)
+
+	ctx := core.WithExecutionState(context.Background())
+	inputs := TestQAInputs{Question: "ok?", Context: "ctx"}
+
+	result, err := ProcessTypedWithConstraints[TestQAInputs, TestQAOutputs](
+		ctx, predict, inputs, []Constraint[TestQAOutputs]{confidenceAbove(80)}, 3)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Retries)
+	assert.Equal(t, 1, hook.retryAttempts)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/darwishdev/dspy-go/pkg/core"
@@ -23,6 +25,22 @@ type Predict struct {
 	// XML output configuration
 	xmlConfig     *interceptors.XMLConfig
 	enableXMLMode bool
+
+	// prefixStyle controls how field prefixes are rendered in text-based
+	// prompts. Nil means core.ColonPrefixStyle (the package default).
+	prefixStyle core.PrefixStyle
+
+	// typeHintStyle, when set, appends a compact type hint (e.g.
+	// "(integer)", "(array of string)") after each output field's name in
+	// text-based prompts, via WithTypeHints. Nil (the default) renders no
+	// hints, matching historical prompt output.
+	typeHintStyle core.TypeHintStyle
+
+	// strictUnknownFields, when set via WithStrictUnknownFields, makes
+	// ProcessTyped reject a response carrying fields TOutput doesn't
+	// declare instead of silently dropping them. False (the default) is
+	// lenient, matching historical behavior.
+	strictUnknownFields bool
 }
 
 // Ensure Predict implements core.Module.
@@ -162,6 +180,34 @@ func (p *Predict) WithTextOutput() *Predict {
 	return p
 }
 
+// WithPrefixStyle sets how field prefixes are rendered in text-based
+// prompts (see core.PrefixStyle). It only affects the traditional
+// text-rendering path formatPrompt uses; XML mode renders fields by tag
+// name regardless.
+func (p *Predict) WithPrefixStyle(style core.PrefixStyle) *Predict {
+	p.prefixStyle = style
+	return p
+}
+
+// WithTypeHints enables compact type hints (e.g. "count (integer)", "tags
+// (array of string)") in text-rendered prompts, using style to render each
+// hint. Pass core.CompactTypeHintStyle for the package's default rendering,
+// or nil to disable hints again - unlike WithPrefixStyle, nil here means
+// "render no hints" rather than "use the default style".
+func (p *Predict) WithTypeHints(style core.TypeHintStyle) *Predict {
+	p.typeHintStyle = style
+	return p
+}
+
+// WithStrictUnknownFields makes ProcessTyped reject a response carrying
+// fields the output type doesn't declare, instead of silently ignoring
+// them. Use this to catch prompt/schema drift - a model that starts
+// emitting fields you didn't ask for.
+func (p *Predict) WithStrictUnknownFields() *Predict {
+	p.strictUnknownFields = true
+	return p
+}
+
 func (p *Predict) Process(ctx context.Context, inputs map[string]interface{}, opts ...core.Option) (map[string]interface{}, error) {
 	// If XML mode is enabled, automatically use ProcessWithInterceptors for proper XML handling
 	if p.enableXMLMode {
@@ -191,7 +237,7 @@ func (p *Predict) Process(ctx context.Context, inputs map[string]interface{}, op
 	}
 	ctx, span := core.StartSpanWithContext(ctx, "Predict", displayName, metadata)
 	defer core.EndSpan(ctx)
-	span.WithAnnotation("inputs", inputs)
+	span.WithAnnotation("inputs", core.RedactSensitiveInputs(p.GetSignature(), inputs))
 
 	if err := p.ValidateInputs(inputs); err != nil {
 		span.WithError(err)
@@ -244,8 +290,8 @@ func (p *Predict) Process(ctx context.Context, inputs map[string]interface{}, op
 	}
 
 	// Fall back to traditional text-based approach
-	prompt := formatPrompt(signature, p.Demos, inputs)
-	logger.Debug(ctx, "Generated prompt with prompt: %v", prompt)
+	prompt := formatPrompt(signature, p.Demos, inputs, p.prefixStyle, p.typeHintStyle)
+	logger.Debug(ctx, "Generated prompt with prompt: %v", formatPrompt(signature, p.Demos, core.RedactSensitiveInputs(signature, inputs), p.prefixStyle, p.typeHintStyle))
 	logger.Debug(ctx, "Generation Options: %v", finalOptions.GenerateOptions)
 	logger.Debug(ctx, "Generation Options:")
 
@@ -322,7 +368,7 @@ func (p *Predict) processCore(ctx context.Context, inputs map[string]interface{}
 	}
 	ctx, span := core.StartSpanWithContext(ctx, "Predict", displayName, metadata)
 	defer core.EndSpan(ctx)
-	span.WithAnnotation("inputs", inputs)
+	span.WithAnnotation("inputs", core.RedactSensitiveInputs(p.GetSignature(), inputs))
 
 	if err := p.ValidateInputs(inputs); err != nil {
 		span.WithError(err)
@@ -384,8 +430,8 @@ func (p *Predict) processCore(ctx context.Context, inputs map[string]interface{}
 	}
 
 	// Traditional text-based approach
-	prompt := formatPrompt(signature, p.Demos, inputs)
-	logger.Debug(ctx, "Generated prompt with prompt: %v", prompt)
+	prompt := formatPrompt(signature, p.Demos, inputs, p.prefixStyle, p.typeHintStyle)
+	logger.Debug(ctx, "Generated prompt with prompt: %v", formatPrompt(signature, p.Demos, core.RedactSensitiveInputs(signature, inputs), p.prefixStyle, p.typeHintStyle))
 
 	resp, err := p.LLM.Generate(ctx, prompt, finalOptions.GenerateOptions...)
 	if err != nil {
@@ -497,7 +543,7 @@ func (p *Predict) processWithStreaming(ctx context.Context, inputs map[string]in
 	}
 
 	signature := p.GetSignature()
-	prompt := formatPrompt(signature, p.Demos, inputs)
+	prompt := formatPrompt(signature, p.Demos, inputs, p.prefixStyle, p.typeHintStyle)
 
 	// Use StreamGenerate instead of Generate
 	stream, err := p.LLM.StreamGenerate(ctx, prompt, opts.GenerateOptions...)
@@ -581,7 +627,16 @@ func (p *Predict) processWithStreaming(ctx context.Context, inputs map[string]in
 	return formattedOutputs, nil
 }
 
-func formatPrompt(signature core.Signature, demos []core.Example, inputs map[string]any) string {
+// formatPrompt renders signature into a text prompt. style controls how each
+// field's prefix is rendered (e.g. "name:", "### name", "<name>"); pass nil
+// to use core.ColonPrefixStyle, which renders Field.Prefix as stored.
+// hintStyle, when non-nil, appends a compact type hint (e.g. "(integer)")
+// after each output field's name; pass nil to render no hints.
+func formatPrompt(signature core.Signature, demos []core.Example, inputs map[string]any, style core.PrefixStyle, hintStyle core.TypeHintStyle) string {
+	if style == nil {
+		style = core.ColonPrefixStyle
+	}
+
 	var sb strings.Builder
 
 	// Write the instruction
@@ -591,9 +646,15 @@ func formatPrompt(signature core.Signature, demos []core.Example, inputs map[str
 	))
 
 	for _, field := range signature.Outputs {
-		if field.Prefix != "" {
+		if prefix := style(field.Field); prefix != "" {
+			name := field.Name
+			if hintStyle != nil {
+				if hint := hintStyle(field.Field); hint != "" {
+					name = name + " " + hint
+				}
+			}
 			sb.WriteString(fmt.Sprintf("The %s field should start with '%s' followed by the content on new lines.\n",
-				field.Name, field.Prefix))
+				name, prefix))
 		}
 		if field.Description != "" {
 			sb.WriteString(fmt.Sprintf(" %s", field.Description))
@@ -616,10 +677,10 @@ func formatPrompt(signature core.Signature, demos []core.Example, inputs map[str
 	for _, demo := range demos {
 		sb.WriteString("---\n\n")
 		for _, field := range signature.Inputs {
-			sb.WriteString(fmt.Sprintf("%s: %v\n", field.Name, demo.Inputs[field.Name]))
+			sb.WriteString(fmt.Sprintf("%s %v\n", style(field.Field), demo.Inputs[field.Name]))
 		}
 		for _, field := range signature.Outputs {
-			sb.WriteString(fmt.Sprintf("%s: %v\n", field.Name, demo.Outputs[field.Name]))
+			sb.WriteString(fmt.Sprintf("%s %v\n", style(field.Field), demo.Outputs[field.Name]))
 		}
 		sb.WriteString("\n")
 	}
@@ -635,7 +696,7 @@ func formatPrompt(signature core.Signature, demos []core.Example, inputs map[str
 			valueStr = block.String()
 		}
 
-		sb.WriteString(fmt.Sprintf("%s: %s\n", field.Name, valueStr))
+		sb.WriteString(fmt.Sprintf("%s %s\n", style(field.Field), valueStr))
 	}
 
 	return sb.String()
@@ -907,9 +968,17 @@ func outputFieldsToFields(outputs []core.OutputField) []core.Field {
 	return fields
 }
 
+// FormatOutputs projects the raw parsed completion down to the signature's
+// output fields, in signature order. Transient fields (see Field.Transient)
+// are still expected in the raw completion - e.g. streamed chain-of-thought
+// reasoning - but are dropped here rather than passed through, so they never
+// reach the caller's structured result.
 func (p *Predict) FormatOutputs(outputs map[string]interface{}) map[string]interface{} {
 	formattedOutputs := make(map[string]interface{})
 	for _, field := range p.GetSignature().Outputs {
+		if field.Transient {
+			continue
+		}
 		if value, ok := outputs[field.Name]; ok {
 			formattedOutputs[field.Name] = value
 		}
@@ -995,6 +1064,45 @@ func parseJSONResponse(content string, signature core.Signature) string {
 	return strings.TrimSpace(result.String())
 }
 
+// unexpectedJSONFields returns the keys in jsonBytes' top-level object that
+// have no matching exported field (by json tag name, falling back to the Go
+// field name) on structType, sorted for deterministic error messages. It
+// returns nil if structType isn't a struct, or jsonBytes isn't a JSON
+// object, since neither case is something WithStrictUnknownFields can
+// meaningfully police.
+func unexpectedJSONFields(jsonBytes []byte, structType reflect.Type) []string {
+	if structType == nil || structType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(jsonBytes, &raw); err != nil {
+		return nil
+	}
+
+	known := make(map[string]bool, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		f := structType.Field(i)
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		known[name] = true
+	}
+
+	var unexpected []string
+	for name := range raw {
+		if !known[name] {
+			unexpected = append(unexpected, name)
+		}
+	}
+	sort.Strings(unexpected)
+	return unexpected
+}
+
 func ProcessTypedDirect[TInput, TOutput any](ctx context.Context, predict *Predict, inputs TInput, opts ...core.Option) (TOutput, error) {
 	var zero TOutput
 
@@ -1057,20 +1165,39 @@ func ProcessTyped[TInput, TOutput any](ctx context.Context, predict *Predict, in
 		return zero, fmt.Errorf("failed to marshal legacy outputs: %w", err)
 	}
 
-	// Unmarshal JSON → typed struct
+	// Unmarshal JSON → typed struct. When TOutput is a pointer type, allocate
+	// the pointee explicitly so callers always get back a non-nil pointer
+	// rather than relying on json.Unmarshal's implicit allocation.
+	outputType := reflect.TypeOf(zero)
+
+	if predict.strictUnknownFields {
+		structType := outputType
+		if structType != nil && structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+		if unexpected := unexpectedJSONFields(jsonBytes, structType); len(unexpected) > 0 {
+			return zero, errors.WithFields(
+				errors.New(errors.InvalidResponse, "response contains fields not declared on the output type"),
+				errors.Fields{
+					"module":          "Predict",
+					"type":            fmt.Sprintf("%T", zero),
+					"unexpectedField": unexpected,
+				})
+		}
+	}
+
+	if outputType != nil && outputType.Kind() == reflect.Ptr {
+		instance := reflect.New(outputType.Elem())
+		if err := json.Unmarshal(jsonBytes, instance.Interface()); err != nil {
+			return zero, fmt.Errorf("failed to unmarshal into typed output: %w", err)
+		}
+		return instance.Interface().(TOutput), nil
+	}
+
 	var typed TOutput
 	if err := json.Unmarshal(jsonBytes, &typed); err != nil {
 		return zero, fmt.Errorf("failed to unmarshal into typed output: %w", err)
 	}
-	if err != nil {
-		return zero, errors.WithFields(
-			errors.Wrap(err, errors.InvalidResponse, "failed to convert legacy outputs"),
-			errors.Fields{
-				"module":  "Predict",
-				"type":    fmt.Sprintf("%T", zero),
-				"outputs": legacyOutputs,
-			})
-	}
 
 	return typed, nil
 }
@@ -1079,6 +1206,12 @@ func ProcessTyped[TInput, TOutput any](ctx context.Context, predict *Predict, in
 func ProcessTypedWithValidation[TInput, TOutput any](ctx context.Context, predict *Predict, inputs TInput, opts ...core.Option) (TOutput, error) {
 	var zero TOutput
 
+	// Run declarative input hygiene (transform:"..." tags) before anything
+	// else sees the inputs, so e.g. a whitespace-only required field trims
+	// down to empty and gets caught below rather than passing validation on
+	// its untransformed value.
+	inputs = utils.ApplyFieldTransforms(inputs)
+
 	// Create typed signature for validation (cached for performance)
 	typedSig := core.NewTypedSignatureCached[TInput, TOutput]()
 
@@ -2,6 +2,7 @@ package modules
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -371,6 +372,114 @@ func TestPredictTyped(t *testing.T) {
 	mockLLM.AssertExpectations(t)
 }
 
+func TestPredictTypedPointerOutput(t *testing.T) {
+	content := `Answer:
+		Machine learning is a subset of AI
+
+		Confidence:
+		85`
+
+	testCases := []struct {
+		name string
+		run  func(t *testing.T, ctx context.Context, predict *Predict, inputs TestQAInputs)
+	}{
+		{
+			name: "struct output",
+			run: func(t *testing.T, ctx context.Context, predict *Predict, inputs TestQAInputs) {
+				outputs, err := ProcessTyped[TestQAInputs, TestQAOutputs](ctx, predict, inputs)
+				require.NoError(t, err)
+				assert.Contains(t, outputs.Answer, "Machine learning")
+				assert.Equal(t, 85, outputs.Confidence)
+			},
+		},
+		{
+			name: "pointer output",
+			run: func(t *testing.T, ctx context.Context, predict *Predict, inputs TestQAInputs) {
+				outputs, err := ProcessTyped[TestQAInputs, *TestQAOutputs](ctx, predict, inputs)
+				require.NoError(t, err)
+				require.NotNil(t, outputs)
+				assert.Contains(t, outputs.Answer, "Machine learning")
+				assert.Equal(t, 85, outputs.Confidence)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockLLM := new(testutil.MockLLM)
+			mockLLM.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(&core.LLMResponse{
+				Content: content,
+			}, nil)
+
+			predict := NewTypedPredict[TestQAInputs, TestQAOutputs]()
+			predict.SetLLM(mockLLM)
+
+			ctx := core.WithExecutionState(context.Background())
+			inputs := TestQAInputs{
+				Question: "What is machine learning?",
+				Context:  "ML is a type of artificial intelligence",
+			}
+
+			tc.run(t, ctx, predict, inputs)
+			mockLLM.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProcessTyped_UnknownFields(t *testing.T) {
+	// Build a signature that declares an "extra" output field TestQAOutputs
+	// doesn't know about, so the response carries a field ProcessTyped's
+	// narrower TOutput will see as unexpected.
+	signature := core.NewSignature(
+		[]core.InputField{{Field: core.Field{Name: "question"}}},
+		[]core.OutputField{
+			{Field: core.NewField("answer", core.WithCustomPrefix("Answer:"))},
+			{Field: core.NewField("confidence", core.WithCustomPrefix("Confidence:"))},
+			{Field: core.NewField("extra", core.WithCustomPrefix("Extra:"))},
+		},
+	)
+
+	content := `Answer:
+Machine learning is a subset of AI
+
+Confidence:
+85
+
+Extra:
+unexpected`
+
+	newPredict := func() *Predict {
+		mockLLM := new(testutil.MockLLM)
+		mockLLM.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(&core.LLMResponse{
+			Content: content,
+		}, nil)
+		predict := NewPredict(signature)
+		predict.SetLLM(mockLLM)
+		return predict
+	}
+
+	ctx := core.WithExecutionState(context.Background())
+	inputs := map[string]any{"question": "What is machine learning?"}
+
+	t.Run("lenient by default", func(t *testing.T) {
+		predict := newPredict()
+		outputs, err := ProcessTyped[map[string]any, TestQAOutputs](ctx, predict, inputs)
+		require.NoError(t, err)
+		assert.Contains(t, outputs.Answer, "Machine learning")
+		assert.Equal(t, 85, outputs.Confidence)
+	})
+
+	t.Run("strict mode rejects the extra field", func(t *testing.T) {
+		predict := newPredict().WithStrictUnknownFields()
+		_, err := ProcessTyped[map[string]any, TestQAOutputs](ctx, predict, inputs)
+		require.Error(t, err)
+
+		var dspyErr *errors.Error
+		require.ErrorAs(t, err, &dspyErr)
+		assert.Equal(t, []string{"extra"}, dspyErr.Fields()["unexpectedField"])
+	})
+}
+
 func TestPredictTypedWithValidation(t *testing.T) {
 	// Create a mock LLM
 	mockLLM := new(testutil.MockLLM)
@@ -430,6 +539,58 @@ func TestPredictTypedWithValidation_InvalidInput(t *testing.T) {
 	assert.Equal(t, 0, outputs.Confidence)
 }
 
+type transformedQAInputs struct {
+	Question string `dspy:"question,required" transform:"trim"`
+	Context  string `dspy:"context,required"`
+}
+
+func TestPredictTypedWithValidation_TransformsRunBeforeValidation(t *testing.T) {
+	predict := NewTypedPredict[transformedQAInputs, TestQAOutputs]()
+
+	ctx := context.Background()
+
+	// Question is whitespace-only, so trimming it down to "" should be what
+	// required-field validation sees - not the untransformed value, which
+	// would look non-empty and pass.
+	inputs := transformedQAInputs{
+		Question: "   ",
+		Context:  "some context",
+	}
+
+	outputs, err := ProcessTypedWithValidation[transformedQAInputs, TestQAOutputs](ctx, predict, inputs)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "required input field 'question' cannot be empty")
+	assert.Empty(t, outputs.Answer)
+}
+
+func TestPredictTypedWithValidation_TransformedValueReachesPrompt(t *testing.T) {
+	mockLLM := new(testutil.MockLLM)
+	var capturedPrompt string
+	mockLLM.On("Generate", mock.Anything, mock.MatchedBy(func(p string) bool {
+		capturedPrompt = p
+		return true
+	}), mock.Anything).Return(&core.LLMResponse{
+		Content: "Answer:\nhi\n\nConfidence:\n90",
+	}, nil)
+
+	predict := NewTypedPredict[transformedQAInputs, TestQAOutputs]()
+	predict.SetLLM(mockLLM)
+
+	ctx := core.WithExecutionState(context.Background())
+	inputs := transformedQAInputs{
+		Question: "  trimmed?  ",
+		Context:  "ctx",
+	}
+
+	_, err := ProcessTypedWithValidation[transformedQAInputs, TestQAOutputs](ctx, predict, inputs)
+
+	require.NoError(t, err)
+	assert.Contains(t, capturedPrompt, "trimmed?")
+	assert.NotContains(t, capturedPrompt, "  trimmed?  ")
+	mockLLM.AssertExpectations(t)
+}
+
 func TestPredictTypedWithMapInputs(t *testing.T) {
 	// Create a mock LLM
 	mockLLM := new(testutil.MockLLM)
@@ -912,3 +1073,239 @@ func TestPredict_XMLMode_ToggleMode(t *testing.T) {
 	assert.False(t, predict.IsXMLModeEnabled())
 	assert.Nil(t, predict.GetXMLConfig())
 }
+
+// TestPredict_SensitiveFieldRedaction verifies that a field marked Sensitive
+// is masked in the rendered prompt used for logging/tracing, while the
+// actual prompt sent to the LLM still carries the real value.
+func TestPredict_SensitiveFieldRedaction(t *testing.T) {
+	mockLLM := new(testutil.MockLLM)
+
+	var capturedPrompt string
+	mockLLM.On("Generate", mock.Anything, mock.MatchedBy(func(prompt string) bool {
+		capturedPrompt = prompt
+		return true
+	}), mock.Anything).Return(&core.LLMResponse{
+		Content: "answer:\n42\n",
+	}, nil)
+
+	signature := core.NewSignature(
+		[]core.InputField{
+			{Field: core.Field{Name: "question"}},
+			{Field: core.Field{Name: "ssn", Sensitive: true}},
+		},
+		[]core.OutputField{{Field: core.NewField("answer")}},
+	)
+	predict := NewPredict(signature)
+	predict.SetLLM(mockLLM)
+
+	ctx := core.WithExecutionState(context.Background())
+	inputs := map[string]any{"question": "What is the meaning of life?", "ssn": "123-45-6789"}
+	_, err := predict.Process(ctx, inputs)
+	require.NoError(t, err)
+
+	// The real request sent to the LLM must contain the actual value.
+	assert.Contains(t, capturedPrompt, "123-45-6789")
+
+	// The rendered-for-logging version must mask it instead.
+	redactedPrompt := formatPrompt(signature, predict.Demos, core.RedactSensitiveInputs(signature, inputs), nil, nil)
+	assert.NotContains(t, redactedPrompt, "123-45-6789")
+	assert.Contains(t, redactedPrompt, "[REDACTED]")
+
+	// Trace annotations must also be redacted.
+	spans := core.CollectSpans(ctx)
+	require.Len(t, spans, 1)
+	inputsMap, _ := spans[0].Annotations["inputs"].(map[string]interface{})
+	assert.Equal(t, "[REDACTED]", inputsMap["ssn"])
+
+	mockLLM.AssertExpectations(t)
+}
+
+// TestPredict_TransientFieldParsedThenDropped verifies that a field marked
+// Transient is still parsed out of the raw completion (it's rendered there
+// for streaming UX, e.g. chain-of-thought reasoning) but excluded from the
+// structured result Process returns.
+func TestPredict_TransientFieldParsedThenDropped(t *testing.T) {
+	mockLLM := new(testutil.MockLLM)
+	mockLLM.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(&core.LLMResponse{
+		Content: "reasoning:\nfirst I considered X, then Y\n\nanswer:\n42\n",
+	}, nil)
+
+	signature := core.NewSignature(
+		[]core.InputField{{Field: core.Field{Name: "question"}}},
+		[]core.OutputField{
+			{Field: core.NewField("reasoning", core.WithTransient())},
+			{Field: core.NewField("answer")},
+		},
+	)
+	predict := NewPredict(signature).WithTextOutput()
+	predict.SetLLM(mockLLM)
+
+	ctx := core.WithExecutionState(context.Background())
+	outputs, err := predict.Process(ctx, map[string]any{"question": "what?"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "42", outputs["answer"])
+	_, hasReasoning := outputs["reasoning"]
+	assert.False(t, hasReasoning, "transient field should be dropped from the structured result")
+
+	mockLLM.AssertExpectations(t)
+}
+
+// TestPredict_WithPrefixStyle verifies that configuring a PrefixStyle
+// changes how field prefixes are rendered in the text-based prompt sent to
+// the LLM, without requiring any change to the signature's field definitions.
+func TestPredict_WithPrefixStyle(t *testing.T) {
+	signature := core.NewSignature(
+		[]core.InputField{{Field: core.Field{Name: "question"}}},
+		[]core.OutputField{{Field: core.NewField("answer")}},
+	)
+
+	t.Run("defaults to colon style", func(t *testing.T) {
+		mockLLM := new(testutil.MockLLM)
+		var capturedPrompt string
+		mockLLM.On("Generate", mock.Anything, mock.MatchedBy(func(prompt string) bool {
+			capturedPrompt = prompt
+			return true
+		}), mock.Anything).Return(&core.LLMResponse{Content: "answer:\n42\n"}, nil)
+
+		predict := NewPredict(signature)
+		predict.SetLLM(mockLLM)
+		_, err := predict.Process(context.Background(), map[string]any{"question": "What is the meaning of life?"})
+		require.NoError(t, err)
+
+		assert.Contains(t, capturedPrompt, "question: What is the meaning of life?")
+	})
+
+	t.Run("markdown header style", func(t *testing.T) {
+		mockLLM := new(testutil.MockLLM)
+		var capturedPrompt string
+		mockLLM.On("Generate", mock.Anything, mock.MatchedBy(func(prompt string) bool {
+			capturedPrompt = prompt
+			return true
+		}), mock.Anything).Return(&core.LLMResponse{Content: "answer:\n42\n"}, nil)
+
+		predict := NewPredict(signature).WithPrefixStyle(core.MarkdownHeaderPrefixStyle)
+		predict.SetLLM(mockLLM)
+		_, err := predict.Process(context.Background(), map[string]any{"question": "What is the meaning of life?"})
+		require.NoError(t, err)
+
+		assert.Contains(t, capturedPrompt, "### question What is the meaning of life?")
+	})
+
+	t.Run("xml tag style", func(t *testing.T) {
+		mockLLM := new(testutil.MockLLM)
+		var capturedPrompt string
+		mockLLM.On("Generate", mock.Anything, mock.MatchedBy(func(prompt string) bool {
+			capturedPrompt = prompt
+			return true
+		}), mock.Anything).Return(&core.LLMResponse{Content: "answer:\n42\n"}, nil)
+
+		predict := NewPredict(signature).WithPrefixStyle(core.XMLTagPrefixStyle)
+		predict.SetLLM(mockLLM)
+		_, err := predict.Process(context.Background(), map[string]any{"question": "What is the meaning of life?"})
+		require.NoError(t, err)
+
+		assert.Contains(t, capturedPrompt, "<question> What is the meaning of life?")
+	})
+
+	t.Run("custom func style", func(t *testing.T) {
+		mockLLM := new(testutil.MockLLM)
+		var capturedPrompt string
+		mockLLM.On("Generate", mock.Anything, mock.MatchedBy(func(prompt string) bool {
+			capturedPrompt = prompt
+			return true
+		}), mock.Anything).Return(&core.LLMResponse{Content: "answer:\n42\n"}, nil)
+
+		custom := core.PrefixStyle(func(field core.Field) string {
+			return "[[" + strings.ToUpper(field.Name) + "]]"
+		})
+		predict := NewPredict(signature).WithPrefixStyle(custom)
+		predict.SetLLM(mockLLM)
+		_, err := predict.Process(context.Background(), map[string]any{"question": "What is the meaning of life?"})
+		require.NoError(t, err)
+
+		assert.Contains(t, capturedPrompt, "[[QUESTION]] What is the meaning of life?")
+	})
+}
+
+func TestPredict_WithTypeHints(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		signature := core.NewSignature(
+			[]core.InputField{{Field: core.Field{Name: "question"}}},
+			[]core.OutputField{{Field: core.NewField("count", core.WithFieldType(core.FieldTypeInt))}},
+		)
+
+		mockLLM := new(testutil.MockLLM)
+		var capturedPrompt string
+		mockLLM.On("Generate", mock.Anything, mock.MatchedBy(func(prompt string) bool {
+			capturedPrompt = prompt
+			return true
+		}), mock.Anything).Return(&core.LLMResponse{Content: "count:\n42\n"}, nil)
+
+		predict := NewPredict(signature)
+		predict.SetLLM(mockLLM)
+		_, err := predict.Process(context.Background(), map[string]any{"question": "how many?"})
+		require.NoError(t, err)
+
+		assert.NotContains(t, capturedPrompt, "(integer)")
+	})
+
+	t.Run("compact style adds scalar and array hints", func(t *testing.T) {
+		signature := core.NewSignature(
+			[]core.InputField{{Field: core.Field{Name: "question"}}},
+			[]core.OutputField{
+				{Field: core.NewField("count", core.WithFieldType(core.FieldTypeInt))},
+				{Field: core.Field{
+					Name:  "tags",
+					Type:  core.FieldTypeArray,
+					Items: &core.Field{Type: core.FieldTypeString},
+				}},
+			},
+		)
+
+		mockLLM := new(testutil.MockLLM)
+		var capturedPrompt string
+		mockLLM.On("Generate", mock.Anything, mock.MatchedBy(func(prompt string) bool {
+			capturedPrompt = prompt
+			return true
+		}), mock.Anything).Return(&core.LLMResponse{Content: "count:\n42\ntags:\na, b\n"}, nil)
+
+		predict := NewPredict(signature).WithTypeHints(core.CompactTypeHintStyle)
+		predict.SetLLM(mockLLM)
+		_, err := predict.Process(context.Background(), map[string]any{"question": "how many?"})
+		require.NoError(t, err)
+
+		assert.Contains(t, capturedPrompt, "The count (integer) field should start with")
+		assert.Contains(t, capturedPrompt, "The tags (array of string) field should start with")
+	})
+
+	t.Run("nested object field gets a structured hint", func(t *testing.T) {
+		signature := core.NewSignature(
+			[]core.InputField{{Field: core.Field{Name: "question"}}},
+			[]core.OutputField{
+				{Field: core.Field{
+					Name: "address",
+					Type: core.FieldTypeObject,
+					Properties: map[string]*core.Field{
+						"city": {Type: core.FieldTypeString},
+						"zip":  {Type: core.FieldTypeString},
+					},
+				}},
+			},
+		)
+
+		mockLLM := new(testutil.MockLLM)
+		var capturedPrompt string
+		mockLLM.On("Generate", mock.Anything, mock.MatchedBy(func(prompt string) bool {
+			capturedPrompt = prompt
+			return true
+		}), mock.Anything).Return(&core.LLMResponse{Content: "address:\nNYC\n"}, nil)
+
+		predict := NewPredict(signature).WithTypeHints(core.CompactTypeHintStyle)
+		predict.SetLLM(mockLLM)
+		_, err := predict.Process(context.Background(), map[string]any{"question": "where?"})
+		require.NoError(t, err)
+
+		assert.Contains(t, capturedPrompt, "The address (object: city (string), zip (string)) field should start with")
+	})
+}
@@ -0,0 +1,175 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/darwishdev/dspy-go/pkg/core"
+	"github.com/darwishdev/dspy-go/pkg/errors"
+)
+
+// StructuredOutputStrategy identifies which technique GenerateStructured
+// used to produce a result.
+type StructuredOutputStrategy string
+
+const (
+	// StructuredOutputJSON asks the LLM for JSON directly via GenerateWithJSON.
+	StructuredOutputJSON StructuredOutputStrategy = "json"
+	// StructuredOutputPrefix parses prefix-delimited text output, the same
+	// scheme formatPrompt/parseCompletion use for ordinary Predict calls.
+	StructuredOutputPrefix StructuredOutputStrategy = "prefix"
+	// StructuredOutputXMLTag parses each field from its own XML-like tag
+	// (e.g. "<answer>...</answer>") via ParseXMLTags.
+	StructuredOutputXMLTag StructuredOutputStrategy = "xmltag"
+)
+
+// GenerateStructuredConfig controls which strategies GenerateStructured
+// tries, in what order, and whether it gives up after the first failure.
+type GenerateStructuredConfig struct {
+	// Order lists the strategies to attempt, in order. Defaults to
+	// [StructuredOutputJSON, StructuredOutputPrefix, StructuredOutputXMLTag]
+	// when empty.
+	Order []StructuredOutputStrategy
+
+	// DisableFallback stops after the first strategy in Order instead of
+	// trying the rest when it fails.
+	DisableFallback bool
+}
+
+// GenerateStructuredResult is the outcome of a successful GenerateStructured call.
+type GenerateStructuredResult struct {
+	Outputs  map[string]any
+	Strategy StructuredOutputStrategy
+}
+
+// GenerateStructured produces signature.Outputs from llm, trying each
+// strategy in config.Order in turn until one both parses and yields every
+// output field. This exists because some providers/endpoints silently
+// ignore the requested response schema: a model that ignores JSON mode
+// still has a chance to succeed via ordinary prefix-based text parsing.
+// The returned result reports which strategy actually succeeded.
+func GenerateStructured(ctx context.Context, llm core.LLM, signature core.Signature, inputs map[string]any, config GenerateStructuredConfig) (*GenerateStructuredResult, error) {
+	order := config.Order
+	if len(order) == 0 {
+		order = []StructuredOutputStrategy{StructuredOutputJSON, StructuredOutputPrefix, StructuredOutputXMLTag}
+	}
+
+	var lastErr error
+	for _, strategy := range order {
+		outputs, err := generateStructuredAttempt(ctx, llm, signature, inputs, strategy)
+		if err == nil {
+			return &GenerateStructuredResult{Outputs: outputs, Strategy: strategy}, nil
+		}
+		lastErr = err
+		if config.DisableFallback {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("generate structured: no strategy succeeded: %w", lastErr)
+}
+
+// generateStructuredAttempt runs a single strategy once, without falling back.
+func generateStructuredAttempt(ctx context.Context, llm core.LLM, signature core.Signature, inputs map[string]any, strategy StructuredOutputStrategy) (map[string]any, error) {
+	switch strategy {
+	case StructuredOutputJSON:
+		return generateStructuredJSON(ctx, llm, signature, inputs)
+	case StructuredOutputPrefix:
+		return generateStructuredPrefix(ctx, llm, signature, inputs)
+	case StructuredOutputXMLTag:
+		return generateStructuredXMLTag(ctx, llm, signature, inputs)
+	default:
+		return nil, fmt.Errorf("generate structured: unknown strategy %q", strategy)
+	}
+}
+
+// requireOutputFields checks that outputs contains a value for every
+// non-Transient field in signature.Outputs, the post-parse counterpart to
+// core.TypedSignature.ValidateOutput's struct-level check for the
+// map-based strategies in this file. When treatEmptyAsMissing is set, a
+// present-but-empty string also counts as missing - text-based strategies
+// (prefix, XML tag) can't otherwise tell "field omitted" from "field
+// recovered as an empty string", unlike JSON mode where an explicit ""
+// value is unambiguous. It collects every field the model omitted - not
+// just the first - into a single typed error so a model that drops
+// several fields fails with one clear report naming all of them, rather
+// than requiring a fix-and-retry cycle per field.
+func requireOutputFields(outputs map[string]any, signature core.Signature, strategy string, treatEmptyAsMissing bool) error {
+	var missing []string
+	for _, field := range signature.Outputs {
+		if field.Transient {
+			continue
+		}
+		value, ok := outputs[field.Name]
+		if !ok || (treatEmptyAsMissing && value == "") {
+			missing = append(missing, field.Name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return errors.WithFields(
+		errors.New(errors.ValidationFailed, fmt.Sprintf("%s: missing required output field(s): %v", strategy, missing)),
+		errors.Fields{
+			"strategy":       strategy,
+			"missing_fields": missing,
+		})
+}
+
+// generateStructuredJSON asks llm for JSON directly and requires every
+// output field to be present in the decoded response.
+func generateStructuredJSON(ctx context.Context, llm core.LLM, signature core.Signature, inputs map[string]any) (map[string]any, error) {
+	prompt := formatPrompt(signature, nil, inputs, nil, nil)
+	result, err := llm.GenerateWithJSON(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("json mode failed: %w", err)
+	}
+
+	outputs := make(map[string]any, len(signature.Outputs))
+	for _, field := range signature.Outputs {
+		if value, ok := result[field.Name]; ok {
+			outputs[field.Name] = value
+		}
+	}
+	if err := requireOutputFields(outputs, signature, "json mode", false); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// generateStructuredPrefix falls back to the same prefix-based text
+// parsing ordinary Predict calls use, and requires every output field to
+// have been recovered from the response.
+func generateStructuredPrefix(ctx context.Context, llm core.LLM, signature core.Signature, inputs map[string]any) (map[string]any, error) {
+	prompt := formatPrompt(signature, nil, inputs, nil, nil)
+	response, err := llm.Generate(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("prefix parsing failed: %w", err)
+	}
+
+	cleaned := stripMarkdown(response.Content, signature)
+	outputs := parseCompletion(cleaned, signature)
+
+	if err := requireOutputFields(outputs, signature, "prefix parsing", true); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// generateStructuredXMLTag asks llm for XML-tagged text (e.g.
+// "<answer>42</answer>") and requires every output field to have been
+// recovered via ParseXMLTags.
+func generateStructuredXMLTag(ctx context.Context, llm core.LLM, signature core.Signature, inputs map[string]any) (map[string]any, error) {
+	prompt := formatPrompt(signature, nil, inputs, nil, nil)
+	response, err := llm.Generate(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("xml tag parsing failed: %w", err)
+	}
+
+	outputs := ParseXMLTags(response.Content, signature, nil)
+	if err := requireOutputFields(outputs, signature, "xml tag parsing", true); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
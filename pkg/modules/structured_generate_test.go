@@ -0,0 +1,125 @@
+package modules
+
+import (
+	"context"
+	"testing"
+
+	"github.com/darwishdev/dspy-go/internal/testutil"
+	"github.com/darwishdev/dspy-go/pkg/core"
+	"github.com/darwishdev/dspy-go/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testStructuredSignature() core.Signature {
+	return core.NewSignature(
+		[]core.InputField{{Field: core.Field{Name: "question"}}},
+		[]core.OutputField{{Field: core.NewField("answer")}},
+	)
+}
+
+func TestGenerateStructuredJSONSucceeds(t *testing.T) {
+	mockLLM := new(testutil.MockLLM)
+	mockLLM.On("GenerateWithJSON", mock.Anything, mock.Anything, mock.Anything).Return(map[string]interface{}{
+		"answer": "42",
+	}, nil)
+
+	result, err := GenerateStructured(context.Background(), mockLLM, testStructuredSignature(), map[string]any{"question": "life?"}, GenerateStructuredConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, StructuredOutputJSON, result.Strategy)
+	assert.Equal(t, "42", result.Outputs["answer"])
+	mockLLM.AssertExpectations(t)
+}
+
+func TestGenerateStructuredFallsBackToPrefix(t *testing.T) {
+	mockLLM := new(testutil.MockLLM)
+	mockLLM.On("GenerateWithJSON", mock.Anything, mock.Anything, mock.Anything).Return(map[string]interface{}{}, nil)
+	mockLLM.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(&core.LLMResponse{
+		Content: "answer:\n42\n",
+	}, nil)
+
+	result, err := GenerateStructured(context.Background(), mockLLM, testStructuredSignature(), map[string]any{"question": "life?"}, GenerateStructuredConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, StructuredOutputPrefix, result.Strategy)
+	assert.Equal(t, "42", result.Outputs["answer"])
+	mockLLM.AssertExpectations(t)
+}
+
+func TestGenerateStructuredDisableFallback(t *testing.T) {
+	mockLLM := new(testutil.MockLLM)
+	mockLLM.On("GenerateWithJSON", mock.Anything, mock.Anything, mock.Anything).Return(map[string]interface{}{}, nil)
+
+	_, err := GenerateStructured(context.Background(), mockLLM, testStructuredSignature(), map[string]any{"question": "life?"}, GenerateStructuredConfig{DisableFallback: true})
+	require.Error(t, err)
+	mockLLM.AssertExpectations(t)
+	mockLLM.AssertNotCalled(t, "Generate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGenerateStructuredFallsBackToXMLTag(t *testing.T) {
+	mockLLM := new(testutil.MockLLM)
+	mockLLM.On("GenerateWithJSON", mock.Anything, mock.Anything, mock.Anything).Return(map[string]interface{}{}, nil)
+	mockLLM.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(&core.LLMResponse{
+		Content: "<answer>42</answer>",
+	}, nil)
+
+	result, err := GenerateStructured(context.Background(), mockLLM, testStructuredSignature(), map[string]any{"question": "life?"}, GenerateStructuredConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, StructuredOutputXMLTag, result.Strategy)
+	assert.Equal(t, "42", result.Outputs["answer"])
+	mockLLM.AssertExpectations(t)
+}
+
+func TestGenerateStructuredCustomOrder(t *testing.T) {
+	mockLLM := new(testutil.MockLLM)
+	mockLLM.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(&core.LLMResponse{
+		Content: "answer:\n42\n",
+	}, nil)
+
+	result, err := GenerateStructured(context.Background(), mockLLM, testStructuredSignature(), map[string]any{"question": "life?"}, GenerateStructuredConfig{
+		Order: []StructuredOutputStrategy{StructuredOutputPrefix},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, StructuredOutputPrefix, result.Strategy)
+	mockLLM.AssertExpectations(t)
+	mockLLM.AssertNotCalled(t, "GenerateWithJSON", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func twoFieldStructuredSignature() core.Signature {
+	return core.NewSignature(
+		[]core.InputField{{Field: core.Field{Name: "question"}}},
+		[]core.OutputField{
+			{Field: core.NewField("answer")},
+			{Field: core.NewField("confidence")},
+		},
+	)
+}
+
+func TestGenerateStructuredJSON_MissingFieldsNamedInTypedError(t *testing.T) {
+	mockLLM := new(testutil.MockLLM)
+	mockLLM.On("GenerateWithJSON", mock.Anything, mock.Anything, mock.Anything).Return(map[string]interface{}{
+		"answer": "42",
+	}, nil)
+
+	_, err := generateStructuredJSON(context.Background(), mockLLM, twoFieldStructuredSignature(), map[string]any{"question": "life?"})
+	require.Error(t, err)
+
+	dspyErr, ok := err.(*errors.Error)
+	require.True(t, ok, "expected a typed *errors.Error, got %T", err)
+	assert.Equal(t, []string{"confidence"}, dspyErr.Fields()["missing_fields"])
+	assert.Contains(t, err.Error(), "confidence")
+}
+
+func TestGenerateStructuredPrefix_MissingFieldsNamedInTypedError(t *testing.T) {
+	mockLLM := new(testutil.MockLLM)
+	mockLLM.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(&core.LLMResponse{
+		Content: "answer:\n42\n",
+	}, nil)
+
+	_, err := generateStructuredPrefix(context.Background(), mockLLM, twoFieldStructuredSignature(), map[string]any{"question": "life?"})
+	require.Error(t, err)
+
+	dspyErr, ok := err.(*errors.Error)
+	require.True(t, ok, "expected a typed *errors.Error, got %T", err)
+	assert.Equal(t, []string{"confidence"}, dspyErr.Fields()["missing_fields"])
+}
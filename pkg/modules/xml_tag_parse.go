@@ -0,0 +1,41 @@
+package modules
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/darwishdev/dspy-go/pkg/core"
+)
+
+// ParseXMLTags extracts each of signature's output fields from its own
+// XML-like tag in completion, e.g. "<answer>42</answer>" for a field named
+// "answer". It complements stripMarkdown/parseCompletion (prefix parsing)
+// and parseJSONResponse (JSON parsing) as a third completion-parsing style.
+//
+// Tag names default to the field name; pass a non-nil tagOverrides
+// (fieldName -> tagName) to use a different tag for specific fields. A
+// missing tag simply leaves that field out of the result instead of
+// erroring, so callers can decide for themselves whether a partial result
+// is acceptable. Content may span multiple lines and may itself contain
+// other tags (e.g. a nested "<reasoning>" block inside "<answer>") - it's
+// returned verbatim, trimmed of leading/trailing whitespace.
+func ParseXMLTags(completion string, signature core.Signature, tagOverrides map[string]string) map[string]any {
+	outputs := make(map[string]any, len(signature.Outputs))
+
+	for _, field := range signature.Outputs {
+		tag := field.Name
+		if override, ok := tagOverrides[field.Name]; ok && override != "" {
+			tag = override
+		}
+
+		pattern := regexp.MustCompile(`(?is)<` + regexp.QuoteMeta(tag) + `>(.*?)</` + regexp.QuoteMeta(tag) + `>`)
+		match := pattern.FindStringSubmatch(completion)
+		if match == nil {
+			continue
+		}
+
+		outputs[field.Name] = strings.TrimSpace(match[1])
+	}
+
+	return outputs
+}
@@ -0,0 +1,52 @@
+package modules
+
+import (
+	"testing"
+
+	"github.com/darwishdev/dspy-go/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseXMLTags(t *testing.T) {
+	signature := core.NewSignature(
+		[]core.InputField{{Field: core.Field{Name: "question"}}},
+		[]core.OutputField{
+			{Field: core.Field{Name: "answer"}},
+			{Field: core.Field{Name: "confidence"}},
+		},
+	)
+
+	t.Run("extracts multi-line content from matching tags", func(t *testing.T) {
+		completion := "<answer>\nline one\nline two\n</answer>\n<confidence>high</confidence>"
+		outputs := ParseXMLTags(completion, signature, nil)
+		assert.Equal(t, "line one\nline two", outputs["answer"])
+		assert.Equal(t, "high", outputs["confidence"])
+	})
+
+	t.Run("tolerates a missing tag without erroring", func(t *testing.T) {
+		completion := "<answer>42</answer>"
+		outputs := ParseXMLTags(completion, signature, nil)
+		assert.Equal(t, "42", outputs["answer"])
+		_, ok := outputs["confidence"]
+		assert.False(t, ok, "confidence should be absent, not an error or empty string")
+	})
+
+	t.Run("handles a tag nested inside another tag", func(t *testing.T) {
+		completion := "<response><answer>42</answer><confidence>high</confidence></response>"
+		outputs := ParseXMLTags(completion, signature, nil)
+		assert.Equal(t, "42", outputs["answer"])
+		assert.Equal(t, "high", outputs["confidence"])
+	})
+
+	t.Run("preserves an unrelated tag nested inside a field's own content", func(t *testing.T) {
+		completion := "<answer>the result is <b>42</b></answer>"
+		outputs := ParseXMLTags(completion, signature, nil)
+		assert.Equal(t, "the result is <b>42</b>", outputs["answer"])
+	})
+
+	t.Run("uses a custom tag override when provided", func(t *testing.T) {
+		completion := "<final_answer>42</final_answer>"
+		outputs := ParseXMLTags(completion, signature, map[string]string{"answer": "final_answer"})
+		assert.Equal(t, "42", outputs["answer"])
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/darwishdev/dspy-go/pkg/core"
 	"github.com/darwishdev/dspy-go/pkg/errors"
 	"github.com/darwishdev/dspy-go/pkg/logging"
+	"github.com/darwishdev/dspy-go/pkg/metrics"
 	"github.com/darwishdev/dspy-go/pkg/modules"
 	"github.com/sourcegraph/conc/pool"
 )
@@ -16,15 +17,45 @@ import (
 type BootstrapFewShot struct {
 	Metric          func(example map[string]interface{}, prediction map[string]interface{}, ctx context.Context) bool
 	MaxBootstrapped int
+	// MaxRounds caps how many rounds are run while gathering demos. Each
+	// round tries the next MaxBootstrapped trainset examples not yet
+	// attempted, and a further round runs only while some module holds
+	// fewer than MaxBootstrapped demos. Defaults to 1 (a single round over
+	// the first MaxBootstrapped examples) when left at zero.
+	MaxRounds int
 }
 
 func NewBootstrapFewShot(metric func(example map[string]interface{}, prediction map[string]interface{}, ctx context.Context) bool, maxBootstrapped int) *BootstrapFewShot {
 	return &BootstrapFewShot{
 		Metric:          metric,
 		MaxBootstrapped: maxBootstrapped,
+		MaxRounds:       1,
 	}
 }
 
+// NewBootstrapFewShotWithEvaluator builds a BootstrapFewShot whose Metric
+// accepts a demo when evaluator scores it at or above threshold, letting
+// callers reuse a metrics.Evaluator (exact-match, F1, tolerance, ...)
+// instead of hand-writing a bool-returning metric function. example is the
+// trainset entry's inputs and outputs merged into one map, so it's narrowed
+// down to the fields prediction actually produced before scoring.
+func NewBootstrapFewShotWithEvaluator(evaluator metrics.Evaluator, threshold float64, maxBootstrapped int) *BootstrapFewShot {
+	return NewBootstrapFewShot(func(example, prediction map[string]interface{}, _ context.Context) bool {
+		expected := make(map[string]interface{}, len(prediction))
+		for field := range prediction {
+			if value, ok := example[field]; ok {
+				expected[field] = value
+			}
+		}
+
+		score, err := evaluator.Evaluate(prediction, expected)
+		if err != nil {
+			return false
+		}
+		return score >= threshold
+	}, maxBootstrapped)
+}
+
 // Compile implements the core.Optimizer interface.
 func (b *BootstrapFewShot) Compile(ctx context.Context, program core.Program, dataset core.Dataset, metric core.Metric) (core.Program, error) {
 	// Convert core.Dataset to trainset format
@@ -74,6 +105,39 @@ func (b *BootstrapFewShot) compileInternal(ctx context.Context, student, teacher
 
 	defer core.EndSpan(ctx)
 
+	maxRounds := b.MaxRounds
+	if maxRounds <= 0 {
+		maxRounds = 1
+	}
+
+	offset := 0
+	for round := 0; round < maxRounds && offset < len(trainset); round++ {
+		if b.enoughBootstrappedDemos(compiledStudent) {
+			logging.GetLogger().Info(ctx, "Enough bootstrapped demos, stopping rounds")
+			break
+		}
+
+		batchSize := b.MaxBootstrapped
+		if remaining := len(trainset) - offset; batchSize > remaining {
+			batchSize = remaining
+		}
+		batch := trainset[offset : offset+batchSize]
+		offset += batchSize
+
+		if err := b.runRound(ctx, teacher, teacherLLM, compiledStudent, batch); err != nil {
+			span.WithError(err)
+			return compiledStudent, err
+		}
+	}
+
+	span.WithAnnotation("compiledStudent", compiledStudent)
+	return compiledStudent, nil
+}
+
+// runRound evaluates a single batch of trainset examples against the
+// teacher program and adds every demo that passes Metric to
+// compiledStudent, up to MaxBootstrapped per module.
+func (b *BootstrapFewShot) runRound(ctx context.Context, teacher core.Program, teacherLLM core.LLM, compiledStudent core.Program, batch []map[string]interface{}) error {
 	var (
 		resultsMu sync.Mutex
 		results   []struct {
@@ -83,21 +147,11 @@ func (b *BootstrapFewShot) compileInternal(ctx context.Context, student, teacher
 		processed int32
 		errCh     = make(chan error, 1)
 	)
-	examplesNeeded := b.MaxBootstrapped
-	if examplesNeeded > len(trainset) {
-		examplesNeeded = len(trainset)
-	}
 
 	p := pool.New().WithMaxGoroutines(core.GlobalConfig.ConcurrencyLevel)
 
-	for i := 0; i < examplesNeeded; i++ {
-		if b.enoughBootstrappedDemos(compiledStudent) {
-			logger := logging.GetLogger()
-			logger.Info(ctx, "Enough bootstrapped demos, breaking loop")
-			break
-		}
-
-		ex := trainset[i]
+	for _, ex := range batch {
+		ex := ex
 		p.Go(func() {
 			exampleCtx, exampleSpan := core.StartSpan(ctx, "Example")
 			defer core.EndSpan(exampleCtx)
@@ -137,23 +191,20 @@ func (b *BootstrapFewShot) compileInternal(ctx context.Context, student, teacher
 
 	select {
 	case err := <-errCh:
-		span.WithError(err)
-		return compiledStudent, fmt.Errorf("error during compilation: %w", err)
+		return fmt.Errorf("error during compilation: %w", err)
 	default:
 	}
 
 	for _, result := range results {
 		if err := b.addDemonstrations(compiledStudent, result.demo, result.ctx); err != nil {
-			span.WithError(err)
-			return compiledStudent, fmt.Errorf("error adding demonstrations: %w", err)
+			return fmt.Errorf("error adding demonstrations: %w", err)
 		}
 		if b.enoughBootstrappedDemos(compiledStudent) {
 			break
 		}
 	}
 
-	span.WithAnnotation("compiledStudent", compiledStudent)
-	return compiledStudent, nil
+	return nil
 }
 
 func (b *BootstrapFewShot) predictWithTeacher(ctx context.Context, teacher core.Program, teacherLLM core.LLM, example map[string]interface{}) (map[string]interface{}, error) {
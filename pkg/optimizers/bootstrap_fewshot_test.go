@@ -8,6 +8,7 @@ import (
 	"github.com/darwishdev/dspy-go/internal/testutil"
 	"github.com/darwishdev/dspy-go/pkg/core"
 	"github.com/darwishdev/dspy-go/pkg/datasets"
+	"github.com/darwishdev/dspy-go/pkg/metrics"
 	"github.com/darwishdev/dspy-go/pkg/modules"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -170,6 +171,65 @@ func TestBootstrapFewShotEdgeCases(t *testing.T) {
 	})
 }
 
+func TestBootstrapFewShotMaxRounds(t *testing.T) {
+	setupTestMockLLM(t)
+
+	// Only the second half of the trainset passes the metric, so a single
+	// round over the first batch would come back empty; a second round
+	// needs to reach the passing examples.
+	trainExamples := []core.Example{
+		{Inputs: map[string]interface{}{"question": "Q1"}, Outputs: map[string]interface{}{"answer": "A1"}},
+		{Inputs: map[string]interface{}{"question": "Q2"}, Outputs: map[string]interface{}{"answer": "A2"}},
+		{Inputs: map[string]interface{}{"question": "Q3"}, Outputs: map[string]interface{}{"answer": "Paris"}},
+		{Inputs: map[string]interface{}{"question": "Q4"}, Outputs: map[string]interface{}{"answer": "Paris"}},
+	}
+	trainDataset := datasets.NewSimpleDataset(trainExamples)
+
+	metric := func(example, prediction map[string]interface{}, _ context.Context) bool {
+		question, _ := example["question"].(string)
+		return question == "Q3" || question == "Q4"
+	}
+	dummyMetric := func(expected, actual map[string]interface{}) float64 { return 1.0 }
+
+	t.Run("single round misses later passing examples", func(t *testing.T) {
+		optimizer := NewBootstrapFewShot(metric, 2)
+		optimizer.MaxRounds = 1
+		ctx := context.Background()
+
+		optimized, err := optimizer.Compile(ctx, createProgram(), trainDataset, dummyMetric)
+		require.NoError(t, err)
+		assert.Equal(t, 0, len(optimized.Modules["predict"].(*modules.Predict).Demos))
+	})
+
+	t.Run("multiple rounds reach passing examples", func(t *testing.T) {
+		optimizer := NewBootstrapFewShot(metric, 2)
+		optimizer.MaxRounds = 2
+		ctx := context.Background()
+
+		optimized, err := optimizer.Compile(ctx, createProgram(), trainDataset, dummyMetric)
+		require.NoError(t, err)
+		assert.Equal(t, 2, len(optimized.Modules["predict"].(*modules.Predict).Demos))
+	})
+}
+
+func TestNewBootstrapFewShotWithEvaluator(t *testing.T) {
+	setupTestMockLLM(t)
+
+	trainExamples := []core.Example{
+		{Inputs: map[string]interface{}{"question": "Q1"}, Outputs: map[string]interface{}{"answer": "Paris"}},
+		{Inputs: map[string]interface{}{"question": "Q2"}, Outputs: map[string]interface{}{"answer": "Paris"}},
+	}
+	trainDataset := datasets.NewSimpleDataset(trainExamples)
+	dummyMetric := func(expected, actual map[string]interface{}) float64 { return 1.0 }
+
+	optimizer := NewBootstrapFewShotWithEvaluator(metrics.ExactMatchEvaluator{}, 1.0, 2)
+	ctx := context.Background()
+
+	optimized, err := optimizer.Compile(ctx, createProgram(), trainDataset, dummyMetric)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(optimized.Modules["predict"].(*modules.Predict).Demos))
+}
+
 // Benchmark tests for BootstrapFewShot optimizer using shared benchmark utilities
 
 // BenchmarkBootstrapFewShot runs comprehensive benchmarks for BootstrapFewShot optimizer.
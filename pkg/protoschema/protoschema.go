@@ -0,0 +1,155 @@
+// Package protoschema converts protobuf message definitions into this
+// repo's TypeSchema and Signature types, so LLM structured output can be
+// driven directly from existing proto service contracts instead of
+// hand-authoring a parallel schema. It's kept as its own package so the
+// protobuf dependency stays optional for callers who don't need it.
+package protoschema
+
+import (
+	"github.com/darwishdev/dspy-go/pkg/core"
+	"github.com/darwishdev/dspy-go/pkg/utils"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// TypeSchema converts a proto message descriptor into a utils.TypeSchema.
+// Repeated fields become ARRAY, nested messages become OBJECT (recursively),
+// and enums become a STRING whose Enum lists the enum's value names. A
+// field is added to Required unless it carries proto3/proto2 optional
+// presence (field.HasOptionalKeyword()).
+func TypeSchema(md protoreflect.MessageDescriptor) *utils.TypeSchema {
+	fields := md.Fields()
+	schema := &utils.TypeSchema{
+		Type:       string(utils.TypeObject),
+		Properties: make(map[string]*utils.TypeSchema, fields.Len()),
+	}
+
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		name := string(field.Name())
+
+		schema.Properties[name] = typeSchemaForField(field)
+		schema.PropertyOrdering = append(schema.PropertyOrdering, name)
+
+		if !field.HasOptionalKeyword() {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+// TypeSchemaFromMessage is a convenience wrapper around TypeSchema for
+// callers holding a concrete proto.Message instead of its descriptor.
+func TypeSchemaFromMessage(msg proto.Message) *utils.TypeSchema {
+	return TypeSchema(msg.ProtoReflect().Descriptor())
+}
+
+func typeSchemaForField(field protoreflect.FieldDescriptor) *utils.TypeSchema {
+	elem := scalarTypeSchema(field)
+	if field.IsList() {
+		return &utils.TypeSchema{
+			Type:  string(utils.TypeArray),
+			Items: elem,
+		}
+	}
+	return elem
+}
+
+func scalarTypeSchema(field protoreflect.FieldDescriptor) *utils.TypeSchema {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return TypeSchema(field.Message())
+	case protoreflect.EnumKind:
+		return &utils.TypeSchema{Type: string(utils.TypeString), Enum: enumValueNames(field.Enum())}
+	case protoreflect.BoolKind:
+		return &utils.TypeSchema{Type: string(utils.TypeBoolean)}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return &utils.TypeSchema{Type: string(utils.TypeNumber)}
+	case protoreflect.Int32Kind, protoreflect.Int64Kind, protoreflect.Uint32Kind, protoreflect.Uint64Kind,
+		protoreflect.Sint32Kind, protoreflect.Sint64Kind, protoreflect.Fixed32Kind, protoreflect.Fixed64Kind,
+		protoreflect.Sfixed32Kind, protoreflect.Sfixed64Kind:
+		return &utils.TypeSchema{Type: string(utils.TypeInteger)}
+	default:
+		return &utils.TypeSchema{Type: string(utils.TypeString)}
+	}
+}
+
+func enumValueNames(enum protoreflect.EnumDescriptor) []string {
+	values := enum.Values()
+	names := make([]string, values.Len())
+	for i := 0; i < values.Len(); i++ {
+		names[i] = string(values.Get(i).Name())
+	}
+	return names
+}
+
+// Signature builds a core.Signature whose outputs mirror md's fields, for
+// modules that want the LLM to produce output matching an existing proto
+// message shape. Inputs are left empty - callers add whatever inputs their
+// pipeline stage needs via Signature.AppendInput or Signature.Merge.
+func Signature(md protoreflect.MessageDescriptor) core.Signature {
+	fields := md.Fields()
+	outputs := make([]core.OutputField, 0, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		outputs = append(outputs, core.OutputField{Field: fieldFromDescriptor(fields.Get(i))})
+	}
+	return core.NewSignature(nil, outputs)
+}
+
+// SignatureFromMessage is a convenience wrapper around Signature for
+// callers holding a concrete proto.Message instead of its descriptor.
+func SignatureFromMessage(msg proto.Message) core.Signature {
+	return Signature(msg.ProtoReflect().Descriptor())
+}
+
+func fieldFromDescriptor(field protoreflect.FieldDescriptor) core.Field {
+	if field.IsList() {
+		name := string(field.Name())
+		item := scalarField(field)
+		return core.Field{Name: name, Prefix: name + ":", Type: core.FieldTypeArray, Items: &item}
+	}
+
+	f := scalarField(field)
+	f.Prefix = f.Name + ":"
+	return f
+}
+
+// scalarField builds the Field for a single (non-repeated) proto field. It's
+// also used to build the Items of a repeated field, ignoring the
+// field's own repeated-ness - a repeated field's elements are never
+// themselves repeated in proto.
+func scalarField(field protoreflect.FieldDescriptor) core.Field {
+	f := core.Field{Name: string(field.Name())}
+
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		f.Type = core.FieldTypeObject
+		f.Properties = objectProperties(field.Message())
+	case protoreflect.EnumKind:
+		f.Type = core.FieldTypeString
+	case protoreflect.BoolKind:
+		f.Type = core.FieldTypeBool
+	case protoreflect.Int32Kind, protoreflect.Int64Kind, protoreflect.Uint32Kind, protoreflect.Uint64Kind,
+		protoreflect.Sint32Kind, protoreflect.Sint64Kind, protoreflect.Fixed32Kind, protoreflect.Fixed64Kind,
+		protoreflect.Sfixed32Kind, protoreflect.Sfixed64Kind:
+		f.Type = core.FieldTypeInt
+	default:
+		// Includes FloatKind/DoubleKind: core.FieldType has no distinct
+		// floating-point type, so these fall back to text like string
+		// fields do.
+		f.Type = core.FieldTypeString
+	}
+
+	return f
+}
+
+func objectProperties(md protoreflect.MessageDescriptor) map[string]*core.Field {
+	fields := md.Fields()
+	props := make(map[string]*core.Field, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		f := fieldFromDescriptor(fields.Get(i))
+		props[f.Name] = &f
+	}
+	return props
+}
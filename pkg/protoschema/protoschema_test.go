@@ -0,0 +1,147 @@
+package protoschema
+
+import (
+	"testing"
+
+	"github.com/darwishdev/dspy-go/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// buildPersonDescriptor hand-builds a FileDescriptorProto describing a
+// "Person" message exercising every proto field shape this package cares
+// about - a plain scalar, an enum, a repeated scalar, a nested message, and
+// an explicit proto3 "optional" field - without depending on a .proto file
+// compiled into this module.
+func buildPersonDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+
+	fd := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("test.proto"),
+		Package: protoString("test"),
+		Syntax:  protoString("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{
+			{
+				Name: protoString("Status"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: protoString("ACTIVE"), Number: protoInt32(0)},
+					{Name: protoString("INACTIVE"), Number: protoInt32(1)},
+				},
+			},
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: protoString("Person"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:   protoString("name"),
+						Number: protoInt32(1),
+						Label:  &label,
+						Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					},
+					{
+						Name:     protoString("status"),
+						Number:   protoInt32(2),
+						Label:    &label,
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum(),
+						TypeName: protoString(".test.Status"),
+					},
+					{
+						Name:   protoString("tags"),
+						Number: protoInt32(3),
+						Label:  &repeated,
+						Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					},
+					{
+						Name:     protoString("address"),
+						Number:   protoInt32(4),
+						Label:    &label,
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+						TypeName: protoString(".test.Address"),
+					},
+					{
+						Name:           protoString("nickname"),
+						Number:         protoInt32(5),
+						Label:          &label,
+						Type:           descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+						Proto3Optional: protoBool(true),
+						OneofIndex:     protoInt32(0),
+					},
+					{
+						Name:   protoString("score"),
+						Number: protoInt32(6),
+						Label:  &label,
+						Type:   descriptorpb.FieldDescriptorProto_TYPE_FLOAT.Enum(),
+					},
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{
+					{Name: protoString("_nickname")},
+				},
+			},
+			{
+				Name: protoString("Address"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:   protoString("city"),
+						Number: protoInt32(1),
+						Label:  &label,
+						Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					},
+				},
+			},
+		},
+	}
+
+	file, err := protodesc.NewFile(fd, nil)
+	require.NoError(t, err)
+	return file.Messages().ByName("Person")
+}
+
+func protoString(s string) *string { return &s }
+func protoInt32(i int32) *int32    { return &i }
+func protoBool(b bool) *bool       { return &b }
+
+func TestTypeSchema(t *testing.T) {
+	md := buildPersonDescriptor(t)
+	schema := TypeSchema(md)
+
+	assert.Equal(t, "OBJECT", schema.Type)
+	assert.Equal(t, "STRING", schema.Properties["name"].Type)
+	assert.Equal(t, "STRING", schema.Properties["status"].Type)
+	assert.Equal(t, []string{"ACTIVE", "INACTIVE"}, schema.Properties["status"].Enum)
+	assert.Equal(t, "ARRAY", schema.Properties["tags"].Type)
+	assert.Equal(t, "STRING", schema.Properties["tags"].Items.Type)
+	assert.Equal(t, "OBJECT", schema.Properties["address"].Type)
+	assert.Equal(t, "STRING", schema.Properties["address"].Properties["city"].Type)
+	assert.Equal(t, "NUMBER", schema.Properties["score"].Type)
+
+	assert.Contains(t, schema.Required, "name")
+	assert.Contains(t, schema.Required, "status")
+	assert.Contains(t, schema.Required, "tags")
+	assert.NotContains(t, schema.Required, "nickname")
+}
+
+func TestSignature(t *testing.T) {
+	md := buildPersonDescriptor(t)
+	sig := Signature(md)
+
+	assert.Empty(t, sig.Inputs)
+
+	byName := make(map[string]core.Field, len(sig.Outputs))
+	for _, o := range sig.Outputs {
+		byName[o.Name] = o.Field
+	}
+
+	assert.Equal(t, core.FieldTypeString, byName["name"].Type)
+	assert.Equal(t, core.FieldTypeString, byName["status"].Type)
+	assert.Equal(t, core.FieldTypeArray, byName["tags"].Type)
+	assert.Equal(t, core.FieldTypeString, byName["tags"].Items.Type)
+	assert.Equal(t, core.FieldTypeObject, byName["address"].Type)
+	assert.Equal(t, core.FieldTypeString, byName["address"].Properties["city"].Type)
+}
@@ -0,0 +1,133 @@
+// Package structdoc extracts struct field doc comments from Go source via
+// go/ast, for merging into a generated schema's Description (see
+// utils.TypeSchema.MergeFieldDescriptions) without duplicating the same
+// text in a `description:"..."` tag. reflect.StructField carries no doc
+// comment at runtime, so this has to read the source file itself - it's
+// kept in its own package so that go/parser and go/ast stay an optional
+// dependency for callers who don't need source-derived descriptions.
+//
+// Typical usage is a go:generate directive next to the struct itself:
+//
+//	//go:generate go run ./cmd/gendocs -type=Person -out=person_docs.go
+//
+// where gendocs calls FieldDescriptions and writes the result as a Go map
+// literal, so the extraction happens once at build time rather than on
+// every call to BuildSchemaFromStruct.
+package structdoc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// FieldDescriptions parses the Go source file at path and returns a map
+// from structName's field name to that field's doc comment - the comment
+// block immediately above the field, or, if it has none, the same-line
+// trailing comment - with comment markers and surrounding whitespace
+// stripped. A field with neither is omitted from the result, not present
+// with an empty string. It returns an error if path can't be parsed, or if
+// it declares no struct type named structName.
+func FieldDescriptions(path, structName string) (map[string]string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("structdoc: failed to parse %s: %w", path, err)
+	}
+
+	structType := findStructType(file, structName)
+	if structType == nil {
+		return nil, fmt.Errorf("structdoc: %s declares no struct type named %q", path, structName)
+	}
+
+	descs := map[string]string{}
+	for _, field := range structType.Fields.List {
+		doc := commentText(field.Doc, field.Comment)
+		if doc == "" {
+			continue
+		}
+		for _, name := range fieldNames(field) {
+			descs[name] = doc
+		}
+	}
+	return descs, nil
+}
+
+// findStructType locates the *ast.StructType declared by
+// `type structName struct {...}` in file, or nil if there is none.
+func findStructType(file *ast.File, structName string) *ast.StructType {
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok || typeSpec.Name.Name != structName {
+				continue
+			}
+			if structType, ok := typeSpec.Type.(*ast.StructType); ok {
+				return structType
+			}
+		}
+	}
+	return nil
+}
+
+// fieldNames returns the Go field name(s) field declares - more than one
+// for a grouped declaration like `Width, Height int`, and the embedded
+// type's name (without any pointer, package qualifier or type arguments)
+// for an embedded field with no explicit name of its own.
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) == 0 {
+		typ := field.Type
+		if star, ok := typ.(*ast.StarExpr); ok {
+			typ = star.X
+		}
+		switch t := typ.(type) {
+		case *ast.IndexExpr:
+			typ = t.X
+		case *ast.IndexListExpr:
+			typ = t.X
+		}
+		switch t := typ.(type) {
+		case *ast.Ident:
+			return []string{t.Name}
+		case *ast.SelectorExpr:
+			return []string{t.Sel.Name}
+		}
+		return nil
+	}
+	names := make([]string, len(field.Names))
+	for i, n := range field.Names {
+		names[i] = n.Name
+	}
+	return names
+}
+
+// commentText prefers doc's text (the comment block immediately above the
+// field) and falls back to comment (a same-line trailing comment) when doc
+// is nil, trimming each line's "//" marker and surrounding whitespace.
+func commentText(doc, comment *ast.CommentGroup) string {
+	group := doc
+	if group == nil {
+		group = comment
+	}
+	if group == nil {
+		return ""
+	}
+
+	var lines []string
+	for _, c := range group.List {
+		line := strings.TrimPrefix(c.Text, "//")
+		line = strings.TrimPrefix(line, "/*")
+		line = strings.TrimSuffix(line, "*/")
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
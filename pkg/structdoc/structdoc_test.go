@@ -0,0 +1,41 @@
+package structdoc
+
+import (
+	"testing"
+)
+
+func TestFieldDescriptionsExtractsLeadingAndTrailingComments(t *testing.T) {
+	descs, err := FieldDescriptions("testdata/person.go", "Person")
+	if err != nil {
+		t.Fatalf("FieldDescriptions returned error: %v", err)
+	}
+
+	want := map[string]string{
+		"Name":  "Name is the person's full name.",
+		"Age":   "Age in whole years.",
+		"Email": "the person's preferred contact address",
+	}
+	for name, desc := range want {
+		if got := descs[name]; got != desc {
+			t.Errorf("descs[%q] = %q, want %q", name, got, desc)
+		}
+	}
+
+	if _, ok := descs["Undocumented"]; ok {
+		t.Errorf("expected Undocumented to be omitted, got %q", descs["Undocumented"])
+	}
+}
+
+func TestFieldDescriptionsUnknownStructReturnsError(t *testing.T) {
+	_, err := FieldDescriptions("testdata/person.go", "NoSuchStruct")
+	if err == nil {
+		t.Fatal("expected an error for an unknown struct name, got nil")
+	}
+}
+
+func TestFieldDescriptionsUnparsableFileReturnsError(t *testing.T) {
+	_, err := FieldDescriptions("testdata/does_not_exist.go", "Person")
+	if err == nil {
+		t.Fatal("expected an error for a missing file, got nil")
+	}
+}
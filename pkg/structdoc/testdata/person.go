@@ -0,0 +1,14 @@
+package testdata
+
+// Person is a sample struct used to exercise FieldDescriptions.
+type Person struct {
+	// Name is the person's full name.
+	Name string
+
+	// Age in whole years.
+	Age int
+
+	Email string // the person's preferred contact address
+
+	Undocumented bool
+}
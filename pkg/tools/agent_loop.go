@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/darwishdev/dspy-go/pkg/core"
+)
+
+// AgentLoopConfig configures RunAgentLoop.
+type AgentLoopConfig struct {
+	// MaxIterations bounds the number of tool-call/response round trips
+	// before the loop gives up. Defaults to 5 when <= 0.
+	MaxIterations int
+}
+
+// RunAgentLoop drives a minimal agent loop on top of native LLM function
+// calling: it asks llm for the next step, executes any tool call it returns
+// against registry, feeds the tool's result back into the next prompt, and
+// repeats until the LLM responds with plain text (no further tool call) or
+// MaxIterations is reached. It is a lighter-weight alternative to the full
+// ReAct module for callers that just want "call tools until done."
+func RunAgentLoop(ctx context.Context, llm core.LLM, registry core.ToolRegistry, prompt string, config AgentLoopConfig) (string, error) {
+	maxIterations := config.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = 5
+	}
+
+	functions := toolsToFunctionSchemas(registry.List())
+	conversation := prompt
+
+	for i := 0; i < maxIterations; i++ {
+		result, err := llm.GenerateWithFunctions(ctx, conversation, functions)
+		if err != nil {
+			return "", fmt.Errorf("agent loop: generation failed at iteration %d: %w", i, err)
+		}
+
+		functionCall, hasCall := result["function_call"].(map[string]interface{})
+		if !hasCall {
+			content, _ := result["content"].(string)
+			return content, nil
+		}
+
+		toolName, _ := functionCall["name"].(string)
+		arguments, _ := functionCall["arguments"].(map[string]interface{})
+
+		tool, err := registry.Get(toolName)
+		if err != nil {
+			return "", fmt.Errorf("agent loop: unknown tool %q: %w", toolName, err)
+		}
+
+		toolResult, err := tool.Execute(ctx, arguments)
+		if err != nil {
+			return "", fmt.Errorf("agent loop: tool %q failed: %w", toolName, err)
+		}
+
+		conversation += fmt.Sprintf("\n\nCalled tool %q with arguments %v.\nResult: %v\n", toolName, arguments, toolResult.Data)
+	}
+
+	return "", fmt.Errorf("agent loop: exceeded %d iterations without a final answer", maxIterations)
+}
+
+// toolsToFunctionSchemas converts registered tools into the function schema
+// format expected by core.LLM.GenerateWithFunctions.
+func toolsToFunctionSchemas(registeredTools []core.Tool) []map[string]interface{} {
+	functions := make([]map[string]interface{}, 0, len(registeredTools))
+	for _, tool := range registeredTools {
+		schema := tool.InputSchema()
+
+		var required []string
+		properties := make(map[string]interface{})
+		for name, paramSchema := range schema.Properties {
+			properties[name] = map[string]interface{}{
+				"type":        paramSchema.Type,
+				"description": paramSchema.Description,
+			}
+			if paramSchema.Required {
+				required = append(required, name)
+			}
+		}
+
+		functions = append(functions, map[string]interface{}{
+			"name":        tool.Name(),
+			"description": tool.Description(),
+			"parameters": map[string]interface{}{
+				"type":       schema.Type,
+				"properties": properties,
+				"required":   required,
+			},
+		})
+	}
+	return functions
+}
@@ -0,0 +1,171 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	models "github.com/XiaoConstantine/mcp-go/pkg/model"
+	"github.com/darwishdev/dspy-go/pkg/core"
+)
+
+// mockAgentLoopLLM implements core.LLM with a scripted sequence of
+// GenerateWithFunctions responses, one per call.
+type mockAgentLoopLLM struct {
+	core.BaseLLM
+	responses []map[string]interface{}
+	errs      []error
+	calls     int
+	prompts   []string
+}
+
+func newMockAgentLoopLLM(responses ...map[string]interface{}) *mockAgentLoopLLM {
+	return &mockAgentLoopLLM{
+		BaseLLM: *core.NewBaseLLM("mock", "mock-model", []core.Capability{
+			core.CapabilityToolCalling,
+		}, &core.EndpointConfig{}),
+		responses: responses,
+	}
+}
+
+func (m *mockAgentLoopLLM) GenerateWithFunctions(ctx context.Context, prompt string, functions []map[string]interface{}, options ...core.GenerateOption) (map[string]interface{}, error) {
+	m.prompts = append(m.prompts, prompt)
+	idx := m.calls
+	m.calls++
+	if idx < len(m.errs) && m.errs[idx] != nil {
+		return nil, m.errs[idx]
+	}
+	if idx >= len(m.responses) {
+		return map[string]interface{}{"content": "done"}, nil
+	}
+	return m.responses[idx], nil
+}
+
+func (m *mockAgentLoopLLM) Generate(ctx context.Context, prompt string, options ...core.GenerateOption) (*core.LLMResponse, error) {
+	return &core.LLMResponse{Content: "mock response"}, nil
+}
+
+func (m *mockAgentLoopLLM) GenerateWithJSON(ctx context.Context, prompt string, options ...core.GenerateOption) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *mockAgentLoopLLM) CreateEmbedding(ctx context.Context, input string, options ...core.EmbeddingOption) (*core.EmbeddingResult, error) {
+	return nil, nil
+}
+
+func (m *mockAgentLoopLLM) CreateEmbeddings(ctx context.Context, inputs []string, options ...core.EmbeddingOption) (*core.BatchEmbeddingResult, error) {
+	return nil, nil
+}
+
+func (m *mockAgentLoopLLM) StreamGenerate(ctx context.Context, prompt string, options ...core.GenerateOption) (*core.StreamResponse, error) {
+	return nil, nil
+}
+
+// echoTool is a minimal core.Tool that records the arguments it was called with.
+type echoTool struct {
+	name    string
+	lastArg map[string]interface{}
+}
+
+func (t *echoTool) Name() string        { return t.name }
+func (t *echoTool) Description() string { return "echoes its input" }
+func (t *echoTool) InputSchema() models.InputSchema {
+	return models.InputSchema{Type: "object"}
+}
+func (t *echoTool) Metadata() *core.ToolMetadata {
+	return &core.ToolMetadata{Name: t.name}
+}
+func (t *echoTool) CanHandle(ctx context.Context, intent string) bool { return true }
+func (t *echoTool) Execute(ctx context.Context, params map[string]interface{}) (core.ToolResult, error) {
+	t.lastArg = params
+	return core.ToolResult{Data: "sunny"}, nil
+}
+func (t *echoTool) Validate(params map[string]interface{}) error { return nil }
+
+func TestRunAgentLoopReturnsDirectContent(t *testing.T) {
+	llm := newMockAgentLoopLLM(map[string]interface{}{"content": "hello there"})
+	registry := NewInMemoryToolRegistry()
+
+	result, err := RunAgentLoop(context.Background(), llm, registry, "say hi", AgentLoopConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "hello there" {
+		t.Errorf("expected 'hello there', got %q", result)
+	}
+	if llm.calls != 1 {
+		t.Errorf("expected exactly 1 LLM call, got %d", llm.calls)
+	}
+}
+
+func TestRunAgentLoopExecutesToolAndFeedsResultBack(t *testing.T) {
+	tool := &echoTool{name: "get_weather"}
+	registry := NewInMemoryToolRegistry()
+	if err := registry.Register(tool); err != nil {
+		t.Fatalf("failed to register tool: %v", err)
+	}
+
+	llm := newMockAgentLoopLLM(
+		map[string]interface{}{
+			"function_call": map[string]interface{}{
+				"name":      "get_weather",
+				"arguments": map[string]interface{}{"city": "Paris"},
+			},
+		},
+		map[string]interface{}{"content": "it's sunny in Paris"},
+	)
+
+	result, err := RunAgentLoop(context.Background(), llm, registry, "what's the weather in Paris?", AgentLoopConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "it's sunny in Paris" {
+		t.Errorf("expected final answer, got %q", result)
+	}
+	if llm.calls != 2 {
+		t.Errorf("expected 2 LLM calls, got %d", llm.calls)
+	}
+	if tool.lastArg["city"] != "Paris" {
+		t.Errorf("expected tool to receive city=Paris, got %v", tool.lastArg)
+	}
+	if llm.prompts[1] == llm.prompts[0] {
+		t.Error("expected second prompt to include the tool result")
+	}
+}
+
+func TestRunAgentLoopUnknownTool(t *testing.T) {
+	registry := NewInMemoryToolRegistry()
+	llm := newMockAgentLoopLLM(map[string]interface{}{
+		"function_call": map[string]interface{}{
+			"name": "does_not_exist",
+		},
+	})
+
+	_, err := RunAgentLoop(context.Background(), llm, registry, "do something", AgentLoopConfig{})
+	if err == nil {
+		t.Fatal("expected error for unknown tool, got nil")
+	}
+}
+
+func TestRunAgentLoopExceedsMaxIterations(t *testing.T) {
+	tool := &echoTool{name: "loop_tool"}
+	registry := NewInMemoryToolRegistry()
+	if err := registry.Register(tool); err != nil {
+		t.Fatalf("failed to register tool: %v", err)
+	}
+
+	call := map[string]interface{}{
+		"function_call": map[string]interface{}{
+			"name":      "loop_tool",
+			"arguments": map[string]interface{}{},
+		},
+	}
+	llm := newMockAgentLoopLLM(call, call, call)
+
+	_, err := RunAgentLoop(context.Background(), llm, registry, "keep going", AgentLoopConfig{MaxIterations: 3})
+	if err == nil {
+		t.Fatal("expected error after exceeding max iterations, got nil")
+	}
+	if llm.calls != 3 {
+		t.Errorf("expected 3 LLM calls, got %d", llm.calls)
+	}
+}
@@ -4,6 +4,8 @@ package tools
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
 
 	"github.com/darwishdev/dspy-go/pkg/core"
 	models "github.com/XiaoConstantine/mcp-go/pkg/model"
@@ -115,3 +117,85 @@ func (t *FuncTool) Validate(params map[string]interface{}) error {
 func (t *FuncTool) Type() ToolType {
 	return ToolTypeFunc
 }
+
+// NewFuncToolFromStruct creates a function-based tool whose input schema is
+// derived automatically from paramsStruct via reflection, instead of
+// requiring callers to hand-build a models.InputSchema.
+func NewFuncToolFromStruct(name, description string, paramsStruct any, fn ToolFunc) *FuncTool {
+	return NewFuncTool(name, description, SchemaFromStruct(paramsStruct), fn)
+}
+
+// SchemaFromStruct derives an MCP models.InputSchema from a Go struct by
+// reflecting over its exported fields. Field names come from the `json` tag
+// (falling back to the Go field name), descriptions from the `description`
+// tag, and a field is marked required unless its json tag carries
+// `omitempty`. Non-struct inputs yield an empty object schema.
+func SchemaFromStruct(v any) models.InputSchema {
+	schema := models.InputSchema{
+		Type:       "object",
+		Properties: map[string]models.ParameterSchema{},
+	}
+
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return schema
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		jsonTag := field.Tag.Get("json")
+		parts := strings.Split(jsonTag, ",")
+		fieldName := parts[0]
+		if fieldName == "" {
+			fieldName = field.Name
+		}
+
+		isOmitempty := false
+		for _, opt := range parts[1:] {
+			if opt == "omitempty" {
+				isOmitempty = true
+				break
+			}
+		}
+
+		schema.Properties[fieldName] = models.ParameterSchema{
+			Type:        jsonSchemaTypeOf(field.Type),
+			Description: field.Tag.Get("description"),
+			Required:    !isOmitempty,
+		}
+	}
+
+	return schema
+}
+
+// jsonSchemaTypeOf maps a Go reflect.Type to its JSON Schema "type" string.
+func jsonSchemaTypeOf(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return "string"
+	}
+}
@@ -353,3 +353,56 @@ func TestFuncToolType(t *testing.T) {
 		t.Errorf("Expected type '%s', got '%s'", ToolTypeFunc, tool.Type())
 	}
 }
+
+type weatherParams struct {
+	City  string `json:"city" description:"City to look up"`
+	Units string `json:"units,omitempty" description:"Temperature units"`
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	schema := SchemaFromStruct(weatherParams{})
+
+	if schema.Type != "object" {
+		t.Errorf("Expected type 'object', got '%s'", schema.Type)
+	}
+
+	city, ok := schema.Properties["city"]
+	if !ok {
+		t.Fatal("Expected 'city' property to be present")
+	}
+	if city.Type != "string" || !city.Required || city.Description != "City to look up" {
+		t.Errorf("Unexpected schema for 'city': %+v", city)
+	}
+
+	units, ok := schema.Properties["units"]
+	if !ok {
+		t.Fatal("Expected 'units' property to be present")
+	}
+	if units.Required {
+		t.Error("Expected 'units' to be optional due to omitempty")
+	}
+}
+
+func TestNewFuncToolFromStruct(t *testing.T) {
+	called := false
+	tool := NewFuncToolFromStruct("get_weather", "Fetch current weather", weatherParams{},
+		func(ctx context.Context, args map[string]interface{}) (*models.CallToolResult, error) {
+			called = true
+			return &models.CallToolResult{}, nil
+		})
+
+	if tool.Name() != "get_weather" {
+		t.Errorf("Expected name 'get_weather', got '%s'", tool.Name())
+	}
+
+	if err := tool.Validate(map[string]interface{}{}); err == nil {
+		t.Error("Expected validation error for missing required 'city' parameter")
+	}
+
+	if _, err := tool.Call(context.Background(), map[string]interface{}{"city": "Paris"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !called {
+		t.Error("Expected wrapped function to be called")
+	}
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"reflect"
+	"sync"
+)
+
+// enumRegistry maps a named string type to its registered set of allowed
+// values, keyed by reflect.Type so registration works for any `type Status
+// string`-style declaration without requiring a struct tag at every use
+// site. See RegisterEnum.
+var enumRegistry sync.Map // reflect.Type -> []string
+
+// RegisterEnum records the full set of valid values for T, a named type
+// whose underlying kind is string (e.g. `type Status string`). Once
+// registered, BuildSchemaFromStruct automatically populates Enum for any
+// struct field of type T, and EnumValues/ValidateEnum let callers (notably
+// pkg/core's struct field parser and validator) look the same set up by
+// reflect.Type. Call it once, typically from an init() next to the type
+// declaration; registering the same T again replaces its value set.
+func RegisterEnum[T ~string](values ...T) {
+	var zero T
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = string(v)
+	}
+	enumRegistry.Store(reflect.TypeOf(zero), strs)
+}
+
+// EnumValues returns the values registered for t via RegisterEnum, and
+// whether t was registered at all. An unregistered named string type (or
+// any non-string type) reports ok=false, so callers fall back to treating
+// the field as a plain, unconstrained string.
+func EnumValues(t reflect.Type) (values []string, ok bool) {
+	v, found := enumRegistry.Load(t)
+	if !found {
+		return nil, false
+	}
+	return v.([]string), true
+}
+
+// ValidateEnum reports whether value is a member of t's registered enum
+// values. An unregistered type always passes, since there's nothing to
+// restrict it to.
+func ValidateEnum(t reflect.Type, value string) bool {
+	values, ok := EnumValues(t)
+	if !ok {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package utils
+
+import "encoding/json"
+
+// JSONStreamDecoder incrementally parses a single top-level JSON object from
+// bytes that arrive in arbitrary-sized chunks (as from a JSON-mode LLM
+// stream). After each Write, it returns the most complete object it can
+// safely parse: a field is only included once its value's closing token
+// has actually been seen, so a string or nested object that's still being
+// streamed is omitted entirely rather than returned truncated. This backs
+// UIs that want to render fields as they arrive without ever flashing a
+// half-written value.
+//
+// A JSONStreamDecoder is not safe for concurrent use; each stream should
+// use its own instance.
+type JSONStreamDecoder struct {
+	buf      []byte
+	stack    []byte // open '{'/'[' seen so far, in nesting order
+	inString bool
+	escape   bool
+
+	// afterColon is true once a top-level field's ':' has been seen and
+	// before that field completes. It disambiguates a depth-1 string's
+	// opening quote as a value (whose closing quote completes the field)
+	// from a key (whose closing quote doesn't) - see stringIsValue.
+	afterColon bool
+	// stringIsValue records, for the string currently open at depth 1,
+	// whether it's a value (afterColon was true when it opened) rather
+	// than a key, so scan knows what to do when its closing quote arrives.
+	stringIsValue bool
+
+	// lastSafeEnd is the length of the buf prefix that represents only
+	// complete top-level key/value pairs. It's always measured at a point
+	// where exactly the outermost '{' is still open (or the object has
+	// fully closed), so closing it only ever requires one trailing '}'.
+	lastSafeEnd int
+	complete    bool
+}
+
+// NewJSONStreamDecoder returns a decoder ready to receive the first chunk
+// of a streamed JSON object.
+func NewJSONStreamDecoder() *JSONStreamDecoder {
+	return &JSONStreamDecoder{}
+}
+
+// Write feeds the next chunk of raw bytes and returns the most-complete
+// object parseable so far. It can be called with chunks of any size, down
+// to a single byte, and correctly tracks strings and nested objects that
+// span chunk boundaries.
+func (d *JSONStreamDecoder) Write(chunk []byte) (map[string]interface{}, error) {
+	d.scan(chunk)
+	return d.snapshot()
+}
+
+// Complete reports whether the top-level object has been fully closed.
+func (d *JSONStreamDecoder) Complete() bool {
+	return d.complete
+}
+
+// scan appends chunk to the accumulated buffer and advances the decoder's
+// string/nesting state byte by byte, updating lastSafeEnd whenever it
+// observes the end of a complete top-level key/value pair.
+func (d *JSONStreamDecoder) scan(chunk []byte) {
+	for _, c := range chunk {
+		d.buf = append(d.buf, c)
+		i := len(d.buf) - 1
+
+		if d.inString {
+			switch {
+			case d.escape:
+				d.escape = false
+			case c == '\\':
+				d.escape = true
+			case c == '"':
+				d.inString = false
+				if len(d.stack) == 1 && d.stringIsValue {
+					// A top-level scalar string value just closed.
+					d.lastSafeEnd = i + 1
+					d.afterColon = false
+				}
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			d.inString = true
+			if len(d.stack) == 1 {
+				d.stringIsValue = d.afterColon
+			}
+		case ':':
+			if len(d.stack) == 1 {
+				d.afterColon = true
+			}
+		case '{', '[':
+			d.stack = append(d.stack, c)
+			if len(d.stack) == 1 && c == '{' {
+				// The outermost object just opened; {} is a valid (empty)
+				// snapshot until the first field completes.
+				d.lastSafeEnd = i + 1
+			}
+		case '}', ']':
+			if len(d.stack) > 0 {
+				d.stack = d.stack[:len(d.stack)-1]
+			}
+			switch len(d.stack) {
+			case 1:
+				// A nested value just closed; its field is now complete.
+				d.lastSafeEnd = i + 1
+				d.afterColon = false
+			case 0:
+				// The outermost object itself just closed.
+				d.lastSafeEnd = i + 1
+				d.complete = true
+			}
+		case ',':
+			if len(d.stack) == 1 {
+				// A top-level scalar field just ended; exclude the comma
+				// itself so the closing '}' we append still parses.
+				d.lastSafeEnd = i
+				d.afterColon = false
+			}
+		}
+	}
+}
+
+// snapshot builds the safe prefix into a standalone JSON document and
+// parses it. Closing the outermost object always takes exactly one '}',
+// since lastSafeEnd is only ever recorded while that object is the sole
+// open container.
+func (d *JSONStreamDecoder) snapshot() (map[string]interface{}, error) {
+	if d.lastSafeEnd <= 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	candidate := d.buf[:d.lastSafeEnd]
+	if !d.complete {
+		closed := make([]byte, d.lastSafeEnd+1)
+		copy(closed, candidate)
+		closed[d.lastSafeEnd] = '}'
+		candidate = closed
+	}
+
+	result := make(map[string]interface{})
+	if err := json.Unmarshal(candidate, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
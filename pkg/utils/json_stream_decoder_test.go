@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"testing"
+)
+
+// feedByByte writes input into d one byte at a time and returns the final
+// snapshot after every byte has been written.
+func feedByByte(t *testing.T, d *JSONStreamDecoder, input string) (map[string]interface{}, []map[string]interface{}) {
+	t.Helper()
+	var snapshots []map[string]interface{}
+	var last map[string]interface{}
+	for i := 0; i < len(input); i++ {
+		result, err := d.Write([]byte{input[i]})
+		if err != nil {
+			t.Fatalf("Write failed at byte %d (%q): %v", i, input[i], err)
+		}
+		snapshots = append(snapshots, result)
+		last = result
+	}
+	return last, snapshots
+}
+
+func TestJSONStreamDecoder_FlatObjectOneByteAtATime(t *testing.T) {
+	d := NewJSONStreamDecoder()
+	input := `{"answer":"yes","confidence":90}`
+
+	final, snapshots := feedByByte(t, d, input)
+
+	if !d.Complete() {
+		t.Fatal("expected decoder to report Complete after the closing brace")
+	}
+	if final["answer"] != "yes" || final["confidence"].(float64) != 90 {
+		t.Errorf("unexpected final snapshot: %+v", final)
+	}
+
+	// The snapshot taken right after '{' arrives must be an empty object.
+	for i, snap := range snapshots[:1] {
+		if len(snap) != 0 {
+			t.Errorf("snapshot %d should be empty right after '{' arrives, got %+v", i, snap)
+		}
+	}
+
+	// "answer" must never appear before its closing quote is received.
+	closingQuoteIdx := len(`{"answer":"yes`)
+	for i := 0; i < closingQuoteIdx; i++ {
+		if _, ok := snapshots[i]["answer"]; ok {
+			t.Fatalf("\"answer\" appeared before fully received, at byte %d: %+v", i, snapshots[i])
+		}
+	}
+	for i := closingQuoteIdx; i < len(snapshots); i++ {
+		if snapshots[i]["answer"] != "yes" {
+			t.Fatalf("\"answer\" missing once fully received, at byte %d: %+v", i, snapshots[i])
+		}
+	}
+}
+
+func TestJSONStreamDecoder_StringSpanningChunkBoundaries(t *testing.T) {
+	d := NewJSONStreamDecoder()
+
+	// Split the string value's content across multiple Write calls,
+	// including a chunk boundary that falls inside an escape sequence.
+	chunks := []string{`{"msg":"hello `, `wor`, `ld\`, `""}`}
+	var last map[string]interface{}
+	for _, c := range chunks {
+		result, err := d.Write([]byte(c))
+		if err != nil {
+			t.Fatalf("Write(%q) failed: %v", c, err)
+		}
+		last = result
+	}
+
+	if !d.Complete() {
+		t.Fatal("expected decoder to be complete")
+	}
+	if last["msg"] != `hello world"` {
+		t.Errorf("expected msg %q, got %q", `hello world"`, last["msg"])
+	}
+}
+
+func TestJSONStreamDecoder_NestedObjectExcludedUntilClosed(t *testing.T) {
+	d := NewJSONStreamDecoder()
+
+	partial := `{"meta":{"source":"web","score":0.`
+	result, err := d.Write([]byte(partial))
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, ok := result["meta"]; ok {
+		t.Errorf("expected \"meta\" to be withheld while its nested object is still open, got %+v", result)
+	}
+
+	rest := `5}}`
+	result, err = d.Write([]byte(rest))
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !d.Complete() {
+		t.Fatal("expected decoder to be complete")
+	}
+	meta, ok := result["meta"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected \"meta\" to be a nested object, got %+v", result["meta"])
+	}
+	if meta["source"] != "web" || meta["score"].(float64) != 0.5 {
+		t.Errorf("unexpected nested object contents: %+v", meta)
+	}
+}
+
+func TestJSONStreamDecoder_FieldWithoutTrailingCommaWaitsForClose(t *testing.T) {
+	d := NewJSONStreamDecoder()
+
+	result, err := d.Write([]byte(`{"a":1,"b":2`))
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, ok := result["b"]; ok {
+		t.Errorf("expected \"b\" to be withheld with no comma or closing brace yet, got %+v", result)
+	}
+	if result["a"].(float64) != 1 {
+		t.Errorf("expected \"a\" to already be visible, got %+v", result)
+	}
+
+	result, err = d.Write([]byte(`}`))
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if result["b"].(float64) != 2 {
+		t.Errorf("expected \"b\" to appear once the object closes, got %+v", result)
+	}
+}
+
+func TestJSONStreamDecoder_EmptyObjectBeforeFirstField(t *testing.T) {
+	d := NewJSONStreamDecoder()
+
+	result, err := d.Write([]byte(`{`))
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if len(result) != 0 {
+		t.Errorf("expected an empty object immediately after '{', got %+v", result)
+	}
+	if d.Complete() {
+		t.Error("expected decoder to not yet be complete")
+	}
+}
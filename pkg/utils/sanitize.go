@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SanitizeText strips non-printable control characters from s, keeping
+// newline and tab since those are common, intentional formatting in
+// prompts, and normalizes the result to Unicode NFC form. It's meant for
+// prompt text assembled from user-supplied data, where stray control
+// characters (including embedded null bytes) can break JSON encoding or
+// confuse the model.
+func SanitizeText(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for _, r := range s {
+		if r == '\n' || r == '\t' {
+			sb.WriteRune(r)
+			continue
+		}
+		if unicode.IsControl(r) {
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return norm.NFC.String(sb.String())
+}
@@ -0,0 +1,51 @@
+package utils
+
+import "testing"
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "strips null bytes",
+			input: "hello\x00world",
+			want:  "helloworld",
+		},
+		{
+			name:  "strips control sequences",
+			input: "hello\x01\x02\x1fworld",
+			want:  "helloworld",
+		},
+		{
+			name:  "keeps newline and tab",
+			input: "line one\nline\ttwo",
+			want:  "line one\nline\ttwo",
+		},
+		{
+			name:  "strips DEL character",
+			input: "hello\x7fworld",
+			want:  "helloworld",
+		},
+		{
+			name:  "leaves normal text untouched",
+			input: "hello world",
+			want:  "hello world",
+		},
+		{
+			name:  "normalizes decomposed unicode to NFC",
+			input: "é", // "e" + combining acute accent (NFD)
+			want:  "é",  // precomposed "é" (NFC)
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizeText(tt.input)
+			if got != tt.want {
+				t.Errorf("SanitizeText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
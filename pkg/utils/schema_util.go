@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 )
 
 type Type string
@@ -19,28 +20,29 @@ const (
 )
 
 type TypeSchema struct {
-	AnyOf            []*TypeSchema          `json:"anyOf,omitempty"`
-	Default          interface{}            `json:"default,omitempty"`
-	Description      string                 `json:"description,omitempty"`
-	Enum             []string               `json:"enum,omitempty"`
-	Example          interface{}            `json:"example,omitempty"`
-	Format           string                 `json:"format,omitempty"`
-	Items            *TypeSchema            `json:"items,omitempty"`
-	MaxItems         *int64                 `json:"maxItems,omitempty"`
-	MaxLength        *int64                 `json:"maxLength,omitempty"`
-	MaxProperties    *int64                 `json:"maxProperties,omitempty"`
-	Maximum          *float64               `json:"maximum,omitempty"`
-	MinItems         *int64                 `json:"minItems,omitempty"`
-	MinLength        *int64                 `json:"minLength,omitempty"`
-	MinProperties    *int64                 `json:"minProperties,omitempty"`
-	Minimum          *float64               `json:"minimum,omitempty"`
-	Nullable         *bool                  `json:"nullable,omitempty"`
-	Pattern          string                 `json:"pattern,omitempty"`
-	Properties       map[string]*TypeSchema `json:"properties,omitempty"`
-	PropertyOrdering []string               `json:"propertyOrdering,omitempty"`
-	Required         []string               `json:"required,omitempty"`
-	Title            string                 `json:"title,omitempty"`
-	Type             string                 `json:"type,omitempty"`
+	AdditionalProperties *AdditionalProperties  `json:"additionalProperties,omitempty"`
+	AnyOf                []*TypeSchema          `json:"anyOf,omitempty"`
+	Default              interface{}            `json:"default,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Example              interface{}            `json:"example,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Items                *TypeSchema            `json:"items,omitempty"`
+	MaxItems             *int64                 `json:"maxItems,omitempty"`
+	MaxLength            *int64                 `json:"maxLength,omitempty"`
+	MaxProperties        *int64                 `json:"maxProperties,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinItems             *int64                 `json:"minItems,omitempty"`
+	MinLength            *int64                 `json:"minLength,omitempty"`
+	MinProperties        *int64                 `json:"minProperties,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Nullable             *bool                  `json:"nullable,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Properties           map[string]*TypeSchema `json:"properties,omitempty"`
+	PropertyOrdering     []string               `json:"propertyOrdering,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
 }
 
 func BuildSchemaFromJson(v []byte) (*TypeSchema, error) {
@@ -52,8 +54,27 @@ func BuildSchemaFromJson(v []byte) (*TypeSchema, error) {
 	return &genSchema, nil
 }
 
+// schemaCache memoizes buildSchemaFromType by reflect.Type, mirroring
+// typedSignatureCache in pkg/core: reflecting over the same struct type on
+// every call is wasted work in hot paths like per-request schema
+// generation. Callers always get back a fresh clone (see TypeSchema.Clone),
+// never the cached instance itself, so mutating a returned schema can't
+// corrupt what other callers see.
+var schemaCache sync.Map
+
 func BuildSchemaFromStruct[T interface{}](t T) *TypeSchema {
-	return buildSchemaFromType(reflect.TypeOf(t))
+	reflectType := reflect.TypeOf(t)
+
+	if cached, ok := schemaCache.Load(reflectType); ok {
+		return cached.(*TypeSchema).Clone()
+	}
+
+	schema := buildSchemaFromType(reflectType)
+
+	// LoadOrStore in case another goroutine built and cached the same type
+	// first; either way, cache and caller operate on independent clones.
+	actual, _ := schemaCache.LoadOrStore(reflectType, schema)
+	return actual.(*TypeSchema).Clone()
 }
 
 func buildSchemaFromType(t reflect.Type) *TypeSchema {
@@ -64,6 +85,10 @@ func buildSchemaFromType(t reflect.Type) *TypeSchema {
 		s.Type = string(TypeObject)
 		s.Properties = map[string]*TypeSchema{}
 
+		if structClosed(t) {
+			s.AdditionalProperties = &AdditionalProperties{Bool: boolPtr(false)}
+		}
+
 		for i := 0; i < t.NumField(); i++ {
 			f := t.Field(i)
 			if f.PkgPath != "" { // skip unexportede
@@ -77,8 +102,17 @@ func buildSchemaFromType(t reflect.Type) *TypeSchema {
 				fieldName = f.Name
 			}
 
-			fieldSchema := buildSchemaFromType(baseType(f.Type))
+			ft := baseType(f.Type)
+			var fieldSchema *TypeSchema
+			if uv, ok := reflect.New(ft).Elem().Interface().(unionValue); ok {
+				fieldSchema = unionFieldSchema(uv.unionInterfaceType())
+			} else if ft.Kind() == reflect.Interface {
+				fieldSchema = buildAnyFieldSchema(f.Tag.Get("anyof"))
+			} else {
+				fieldSchema = buildSchemaFromType(ft)
+			}
 			s.Properties[fieldName] = fieldSchema
+			s.PropertyOrdering = append(s.PropertyOrdering, fieldName)
 			isOmitempty := false
 			for _, opt := range parts[1:] {
 				if opt == "omitempty" {
@@ -87,18 +121,50 @@ func buildSchemaFromType(t reflect.Type) *TypeSchema {
 				}
 			}
 
-			// Only append to s.Required if 'omitempty' is NOT found.
-			if !isOmitempty {
+			// A transient field (dspy:",transient" - see Field.Transient) is
+			// still described in Properties so the model knows to produce
+			// it, but it's scratch content dropped before the typed result
+			// is built, so it's excluded from Required regardless of the
+			// json tag's omitempty.
+			isTransient := strings.Contains(f.Tag.Get("dspy"), "transient")
+
+			// A pointer field is optional by default even without an
+			// explicit omitempty, since nil is itself a meaningful value for
+			// it - this mirrors FieldMetadata.Required's default-false
+			// semantics for pointer fields in typed_signature.go. A
+			// `dspy:",required"` tag overrides this when the field must
+			// always be present.
+			isPointer := f.Type.Kind() == reflect.Ptr
+			isRequiredOverride := strings.Contains(f.Tag.Get("dspy"), "required")
+
+			switch {
+			case isTransient:
+				// never required, regardless of the other checks below
+			case isRequiredOverride:
+				s.Required = append(s.Required, fieldName)
+			case !isOmitempty && !isPointer:
 				s.Required = append(s.Required, fieldName)
 			}
 		}
 
 	case reflect.Slice, reflect.Array:
-		s.Type = string(TypeArray)
-		s.Items = buildSchemaFromType(baseType(t.Elem()))
+		// []byte is a slice of uint8, but treating it as an array-of-integer
+		// schema would be nonsensical for what's almost always image/binary
+		// data - mirror inferFieldType's FieldTypeImage special case and emit
+		// a base64 string schema instead.
+		if t.Elem().Kind() == reflect.Uint8 {
+			s.Type = string(TypeString)
+			s.Format = "byte"
+		} else {
+			s.Type = string(TypeArray)
+			s.Items = buildSchemaFromType(baseType(t.Elem()))
+		}
 
 	case reflect.String:
 		s.Type = string(TypeString)
+		if enum, ok := EnumValues(t); ok {
+			s.Enum = enum
+		}
 
 	case reflect.Bool:
 		s.Type = string(TypeBoolean)
@@ -106,6 +172,10 @@ func buildSchemaFromType(t reflect.Type) *TypeSchema {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		s.Type = string(TypeInteger)
 
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s.Type = string(TypeInteger)
+		s.Minimum = float64Ptr(0)
+
 	case reflect.Float32, reflect.Float64:
 		s.Type = string(TypeNumber)
 
@@ -115,6 +185,56 @@ func buildSchemaFromType(t reflect.Type) *TypeSchema {
 
 	return s
 }
+
+// buildAnyFieldSchema returns the schema for an interface{}/any struct
+// field. There's no static Go type to reflect over, so with no anyof tag the
+// schema is left permissive (no Type constraint, so it accepts any JSON
+// value). An `anyof:"string,integer,boolean"` tag instead restricts it to an
+// AnyOf of the named TypeSchema primitive types.
+func buildAnyFieldSchema(anyOfTag string) *TypeSchema {
+	if anyOfTag == "" {
+		return &TypeSchema{}
+	}
+
+	var anyOf []*TypeSchema
+	for _, name := range strings.Split(anyOfTag, ",") {
+		name = strings.TrimSpace(name)
+		if typ, ok := anyOfTypeNames[name]; ok {
+			anyOf = append(anyOf, &TypeSchema{Type: string(typ)})
+		}
+	}
+	if len(anyOf) == 0 {
+		return &TypeSchema{}
+	}
+	return &TypeSchema{AnyOf: anyOf}
+}
+
+// structClosed reports whether t forbids extra properties in its own object
+// schema - i.e. whether its generated schema should carry
+// `additionalProperties: false` - via a field (commonly an unexported
+// marker field, mirroring the instruction marker idiom in pkg/core's
+// structInstruction) tagged `dspy:",closed"`. Because buildSchemaFromType
+// recurses into nested struct types, tagging a marker field inside a nested
+// struct closes that nested object too, with no special-casing needed for
+// "root vs. nested".
+func structClosed(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if strings.Contains(t.Field(i).Tag.Get("dspy"), "closed") {
+			return true
+		}
+	}
+	return false
+}
+
+var anyOfTypeNames = map[string]Type{
+	"string":  TypeString,
+	"integer": TypeInteger,
+	"number":  TypeNumber,
+	"boolean": TypeBoolean,
+	"object":  TypeObject,
+	"array":   TypeArray,
+}
+
 func baseType(t reflect.Type) reflect.Type {
 	for t.Kind() == reflect.Pointer {
 		t = t.Elem()
@@ -122,3 +242,406 @@ func baseType(t reflect.Type) reflect.Type {
 	return t
 }
 func float32Ptr(v float32) *float32 { return &v }
+func float64Ptr(v float64) *float64 { return &v }
+func boolPtr(v bool) *bool          { return &v }
+
+// AdditionalProperties represents a TypeSchema's additionalProperties
+// constraint, which per JSON Schema is either a plain bool (allow or forbid
+// any extra key outright) or a schema every extra key's value must satisfy.
+// At most one of Bool or Schema should be set; MarshalJSON/UnmarshalJSON
+// encode and decode whichever form is present, defaulting to the
+// permissive `true` if neither is set.
+type AdditionalProperties struct {
+	Bool   *bool
+	Schema *TypeSchema
+}
+
+// AdditionalPropertiesBool returns an AdditionalProperties that encodes as
+// the plain bool allow (e.g. AdditionalPropertiesBool(false) for the
+// `dspy:",closed"` behavior of forbidding any extra key).
+func AdditionalPropertiesBool(allow bool) *AdditionalProperties {
+	return &AdditionalProperties{Bool: &allow}
+}
+
+// AdditionalPropertiesSchema returns an AdditionalProperties that encodes
+// as schema, constraining the shape of any extra key's value rather than
+// forbidding extra keys outright.
+func AdditionalPropertiesSchema(schema *TypeSchema) *AdditionalProperties {
+	return &AdditionalProperties{Schema: schema}
+}
+
+func (a *AdditionalProperties) MarshalJSON() ([]byte, error) {
+	if a.Schema != nil {
+		return json.Marshal(a.Schema)
+	}
+	if a.Bool != nil {
+		return json.Marshal(*a.Bool)
+	}
+	return json.Marshal(true)
+}
+
+func (a *AdditionalProperties) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		a.Bool = &b
+		a.Schema = nil
+		return nil
+	}
+
+	var schema TypeSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return fmt.Errorf("additionalProperties must be a bool or a schema object: %w", err)
+	}
+	a.Schema = &schema
+	a.Bool = nil
+	return nil
+}
+
+// Clone returns a deep copy of a.
+func (a *AdditionalProperties) Clone() *AdditionalProperties {
+	if a == nil {
+		return nil
+	}
+	out := &AdditionalProperties{Schema: a.Schema.Clone()}
+	if a.Bool != nil {
+		v := *a.Bool
+		out.Bool = &v
+	}
+	return out
+}
+
+// Clone returns a deep copy of s, recursing into Properties, Items, and
+// AnyOf and copying pointer fields like Maximum by value. Schemas returned
+// from a cache or shared by multiple callers (e.g. BuildSchemaFromStruct,
+// or a sub-schema reused across signatures) should be cloned before any
+// in-place mutation such as setting Description - otherwise the mutation
+// is visible to every other holder of that schema.
+func (s *TypeSchema) Clone() *TypeSchema {
+	if s == nil {
+		return nil
+	}
+
+	out := *s
+
+	out.Enum = append([]string(nil), s.Enum...)
+	out.PropertyOrdering = append([]string(nil), s.PropertyOrdering...)
+	out.Required = append([]string(nil), s.Required...)
+
+	if s.Items != nil {
+		out.Items = s.Items.Clone()
+	}
+
+	out.AdditionalProperties = s.AdditionalProperties.Clone()
+
+	if s.Properties != nil {
+		out.Properties = make(map[string]*TypeSchema, len(s.Properties))
+		for name, prop := range s.Properties {
+			out.Properties[name] = prop.Clone()
+		}
+	}
+
+	if s.AnyOf != nil {
+		out.AnyOf = make([]*TypeSchema, len(s.AnyOf))
+		for i, alt := range s.AnyOf {
+			out.AnyOf[i] = alt.Clone()
+		}
+	}
+
+	if s.MaxItems != nil {
+		v := *s.MaxItems
+		out.MaxItems = &v
+	}
+	if s.MaxLength != nil {
+		v := *s.MaxLength
+		out.MaxLength = &v
+	}
+	if s.MaxProperties != nil {
+		v := *s.MaxProperties
+		out.MaxProperties = &v
+	}
+	if s.Maximum != nil {
+		v := *s.Maximum
+		out.Maximum = &v
+	}
+	if s.MinItems != nil {
+		v := *s.MinItems
+		out.MinItems = &v
+	}
+	if s.MinLength != nil {
+		v := *s.MinLength
+		out.MinLength = &v
+	}
+	if s.MinProperties != nil {
+		v := *s.MinProperties
+		out.MinProperties = &v
+	}
+	if s.Minimum != nil {
+		v := *s.Minimum
+		out.Minimum = &v
+	}
+	if s.Nullable != nil {
+		v := *s.Nullable
+		out.Nullable = &v
+	}
+
+	return &out
+}
+
+// MergeFieldDescriptions fills in Description on s's top-level Properties
+// from descs - typically the output of structdoc.FieldDescriptions, letting
+// a struct's doc comments double as its schema descriptions instead of
+// duplicating the same text in a `description:"..."` tag. Each key of descs
+// is looked up as a property key as-is: FieldDescriptions keys by Go field
+// name, while BuildSchemaFromStruct keys a property by its json tag name
+// when the field has one, so a field renamed by its json tag is not
+// matched. It never overwrites a Description already set
+// (a tag still takes precedence over a doc comment), doesn't recurse into
+// nested object properties (call it again with that nested struct's own
+// descs for those), and returns s for chaining.
+func (s *TypeSchema) MergeFieldDescriptions(descs map[string]string) *TypeSchema {
+	for name, desc := range descs {
+		if prop, ok := s.Properties[name]; ok && prop.Description == "" {
+			prop.Description = desc
+		}
+	}
+	return s
+}
+
+// MinifyOptions controls how aggressively MinifySchema shrinks a schema.
+type MinifyOptions struct {
+	// DropDescriptions removes every Description in the schema, not just
+	// redundant ones. Off by default since a description is usually there
+	// to steer the model's output, not just for human documentation - set
+	// this for a cost-sensitive call where shaving request size matters
+	// more than that guidance.
+	DropDescriptions bool
+}
+
+// MinifySchema returns a clone of s with information that's redundant or
+// optional shrunk away, to reduce the size of a schema sent to the model:
+// a Title equal to the property key it's already nested under (redundant,
+// since the model sees that key regardless), and, with
+// MinifyOptions.DropDescriptions, every Description. It never mutates s
+// itself, same as Clone. Semantics that affect what the model is allowed to
+// produce - Type, Required, Enum, Items, numeric/length bounds - are left
+// untouched.
+func MinifySchema(s *TypeSchema, opts MinifyOptions) *TypeSchema {
+	return minifySchema(s, "", opts)
+}
+
+// minifySchema is MinifySchema's recursive worker. propertyName is the key
+// s is nested under in its parent's Properties map ("" for the root, an
+// array's Items, or an AnyOf/additionalProperties alternative, none of
+// which have a property key to be redundant with).
+func minifySchema(s *TypeSchema, propertyName string, opts MinifyOptions) *TypeSchema {
+	if s == nil {
+		return nil
+	}
+
+	out := s.Clone()
+
+	if opts.DropDescriptions {
+		out.Description = ""
+	}
+	if out.Title != "" && out.Title == propertyName {
+		out.Title = ""
+	}
+
+	if out.Items != nil {
+		out.Items = minifySchema(out.Items, "", opts)
+	}
+	for name, prop := range out.Properties {
+		out.Properties[name] = minifySchema(prop, name, opts)
+	}
+	if out.AdditionalProperties != nil && out.AdditionalProperties.Schema != nil {
+		out.AdditionalProperties.Schema = minifySchema(out.AdditionalProperties.Schema, "", opts)
+	}
+	for i, alt := range out.AnyOf {
+		out.AnyOf[i] = minifySchema(alt, "", opts)
+	}
+
+	return out
+}
+
+// Validate checks that s is internally consistent - the kind of mistake a
+// hand-authored schema can make that the Gemini API would otherwise only
+// catch after a network round-trip: an ARRAY with no Items, a Required
+// name with no matching entry in Properties, or numeric bounds where the
+// minimum exceeds the maximum. It recurses into Properties, Items, and
+// AnyOf, and returns an error naming the path to the first inconsistency
+// found, or nil if s is well-formed.
+func (s *TypeSchema) Validate() error {
+	return s.validateAt("(root)")
+}
+
+func (s *TypeSchema) validateAt(path string) error {
+	if s == nil {
+		return nil
+	}
+
+	if s.Type == string(TypeArray) && s.Items == nil {
+		return fmt.Errorf("%s: type ARRAY has no items", path)
+	}
+
+	if s.Type == string(TypeObject) {
+		for _, name := range s.Required {
+			if _, ok := s.Properties[name]; !ok {
+				return fmt.Errorf("%s: required field %q has no matching entry in properties", path, name)
+			}
+		}
+	}
+
+	if s.Minimum != nil && s.Maximum != nil && *s.Minimum > *s.Maximum {
+		return fmt.Errorf("%s: minimum (%v) is greater than maximum (%v)", path, *s.Minimum, *s.Maximum)
+	}
+	if s.MinLength != nil && s.MaxLength != nil && *s.MinLength > *s.MaxLength {
+		return fmt.Errorf("%s: minLength (%d) is greater than maxLength (%d)", path, *s.MinLength, *s.MaxLength)
+	}
+	if s.MinItems != nil && s.MaxItems != nil && *s.MinItems > *s.MaxItems {
+		return fmt.Errorf("%s: minItems (%d) is greater than maxItems (%d)", path, *s.MinItems, *s.MaxItems)
+	}
+	if s.MinProperties != nil && s.MaxProperties != nil && *s.MinProperties > *s.MaxProperties {
+		return fmt.Errorf("%s: minProperties (%d) is greater than maxProperties (%d)", path, *s.MinProperties, *s.MaxProperties)
+	}
+
+	if err := s.Items.validateAt(path + ".items"); err != nil {
+		return err
+	}
+
+	if s.AdditionalProperties != nil {
+		if err := s.AdditionalProperties.Schema.validateAt(path + ".additionalProperties"); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range s.orderedPropertyNames() {
+		if err := s.Properties[name].validateAt(fmt.Sprintf("%s.properties.%s", path, name)); err != nil {
+			return err
+		}
+	}
+
+	for i, alt := range s.AnyOf {
+		if err := alt.validateAt(fmt.Sprintf("%s.anyOf[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// orderedPropertyNames returns s.Properties' keys in PropertyOrdering's
+// order so Validate reports the same "first inconsistency" across runs
+// instead of depending on Go's randomized map iteration. Keys missing from
+// PropertyOrdering (e.g. a hand-authored schema that never set it) are
+// appended afterward in map order.
+func (s *TypeSchema) orderedPropertyNames() []string {
+	seen := make(map[string]bool, len(s.Properties))
+	names := make([]string, 0, len(s.Properties))
+	for _, name := range s.PropertyOrdering {
+		if _, ok := s.Properties[name]; ok && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+	for name := range s.Properties {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ToJSONSchema converts s into a standard JSON Schema document: lowercase
+// "type" values instead of Gemini's proprietary "STRING"/"OBJECT"/etc, and
+// plain map/slice shapes instead of *TypeSchema pointers. Use it to pass a
+// schema built with BuildSchemaFromStruct to an API that expects raw JSON
+// Schema (e.g. Gemini's responseJsonSchema) rather than the proprietary
+// responseSchema format that TypeSchema otherwise serializes to.
+func (s *TypeSchema) ToJSONSchema() map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+
+	out := map[string]interface{}{}
+
+	if s.Type != "" {
+		out["type"] = strings.ToLower(s.Type)
+	}
+	if s.Description != "" {
+		out["description"] = s.Description
+	}
+	if s.Title != "" {
+		out["title"] = s.Title
+	}
+	if s.Format != "" {
+		out["format"] = s.Format
+	}
+	if s.Pattern != "" {
+		out["pattern"] = s.Pattern
+	}
+	if s.Default != nil {
+		out["default"] = s.Default
+	}
+	if s.Example != nil {
+		out["examples"] = []interface{}{s.Example}
+	}
+	if len(s.Enum) > 0 {
+		out["enum"] = s.Enum
+	}
+	if s.Nullable != nil {
+		out["nullable"] = *s.Nullable
+	}
+	if s.Minimum != nil {
+		out["minimum"] = *s.Minimum
+	}
+	if s.Maximum != nil {
+		out["maximum"] = *s.Maximum
+	}
+	if s.MinLength != nil {
+		out["minLength"] = *s.MinLength
+	}
+	if s.MaxLength != nil {
+		out["maxLength"] = *s.MaxLength
+	}
+	if s.MinItems != nil {
+		out["minItems"] = *s.MinItems
+	}
+	if s.MaxItems != nil {
+		out["maxItems"] = *s.MaxItems
+	}
+	if s.MinProperties != nil {
+		out["minProperties"] = *s.MinProperties
+	}
+	if s.MaxProperties != nil {
+		out["maxProperties"] = *s.MaxProperties
+	}
+	if s.Items != nil {
+		out["items"] = s.Items.ToJSONSchema()
+	}
+	if s.AdditionalProperties != nil {
+		if s.AdditionalProperties.Schema != nil {
+			out["additionalProperties"] = s.AdditionalProperties.Schema.ToJSONSchema()
+		} else if s.AdditionalProperties.Bool != nil {
+			out["additionalProperties"] = *s.AdditionalProperties.Bool
+		}
+	}
+	if len(s.Required) > 0 {
+		out["required"] = s.Required
+	}
+	if len(s.Properties) > 0 {
+		properties := make(map[string]interface{}, len(s.Properties))
+		for _, name := range s.orderedPropertyNames() {
+			properties[name] = s.Properties[name].ToJSONSchema()
+		}
+		out["properties"] = properties
+	}
+	if len(s.AnyOf) > 0 {
+		anyOf := make([]interface{}, len(s.AnyOf))
+		for i, alt := range s.AnyOf {
+			anyOf[i] = alt.ToJSONSchema()
+		}
+		out["anyOf"] = anyOf
+	}
+
+	return out
+}
@@ -0,0 +1,744 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBuildSchemaFromStructNestedSliceOfSlice(t *testing.T) {
+	type Matrix struct {
+		Rows [][]string `json:"rows"`
+	}
+
+	schema := BuildSchemaFromStruct(Matrix{})
+
+	rows, ok := schema.Properties["rows"]
+	if !ok {
+		t.Fatal("expected 'rows' property to be present")
+	}
+	if rows.Type != string(TypeArray) {
+		t.Fatalf("expected outer type ARRAY, got %s", rows.Type)
+	}
+	if rows.Items == nil {
+		t.Fatal("expected outer Items to be set")
+	}
+	if rows.Items.Type != string(TypeArray) {
+		t.Fatalf("expected inner type ARRAY, got %s", rows.Items.Type)
+	}
+	if rows.Items.Items == nil {
+		t.Fatal("expected inner Items to be set")
+	}
+	if rows.Items.Items.Type != string(TypeString) {
+		t.Fatalf("expected innermost type STRING, got %s", rows.Items.Items.Type)
+	}
+}
+
+func TestBuildSchemaFromStructByteSliceFieldIsBase64String(t *testing.T) {
+	type Photo struct {
+		Data []byte `json:"data"`
+	}
+
+	schema := BuildSchemaFromStruct(Photo{})
+
+	data, ok := schema.Properties["data"]
+	if !ok {
+		t.Fatal("expected 'data' property to be present")
+	}
+	if data.Type != string(TypeString) {
+		t.Fatalf("expected []byte field to be schema type STRING, got %s", data.Type)
+	}
+	if data.Format != "byte" {
+		t.Fatalf("expected format \"byte\", got %q", data.Format)
+	}
+	if data.Items != nil {
+		t.Fatalf("expected no Items on a []byte field, got %+v", data.Items)
+	}
+}
+
+func TestBuildSchemaFromStructNestedSliceOfStructSlice(t *testing.T) {
+	type Cell struct {
+		Value string `json:"value"`
+	}
+	type Grid struct {
+		Cells [][]Cell `json:"cells"`
+	}
+
+	schema := BuildSchemaFromStruct(Grid{})
+
+	cells, ok := schema.Properties["cells"]
+	if !ok {
+		t.Fatal("expected 'cells' property to be present")
+	}
+	if cells.Type != string(TypeArray) {
+		t.Fatalf("expected outer type ARRAY, got %s", cells.Type)
+	}
+	if cells.Items == nil {
+		t.Fatal("expected outer Items to be set")
+	}
+	if cells.Items.Type != string(TypeArray) {
+		t.Fatalf("expected inner type ARRAY, got %s", cells.Items.Type)
+	}
+	if cells.Items.Items == nil {
+		t.Fatal("expected inner Items to be set")
+	}
+	if cells.Items.Items.Type != string(TypeObject) {
+		t.Fatalf("expected innermost type OBJECT, got %s", cells.Items.Items.Type)
+	}
+	if _, ok := cells.Items.Items.Properties["value"]; !ok {
+		t.Fatal("expected innermost struct to have 'value' property populated")
+	}
+}
+
+func TestBuildSchemaFromStructPropertyOrdering(t *testing.T) {
+	type Person struct {
+		Zip   string `json:"zip"`
+		Name  string `json:"name"`
+		Age   int    `json:"age"`
+		Email string `json:"email"`
+	}
+
+	expected := []string{"zip", "name", "age", "email"}
+
+	for i := 0; i < 10; i++ {
+		schema := BuildSchemaFromStruct(Person{})
+		if len(schema.PropertyOrdering) != len(expected) {
+			t.Fatalf("expected %d ordered keys, got %d", len(expected), len(schema.PropertyOrdering))
+		}
+		for j, name := range expected {
+			if schema.PropertyOrdering[j] != name {
+				t.Fatalf("expected PropertyOrdering[%d] = %q, got %q", j, name, schema.PropertyOrdering[j])
+			}
+		}
+	}
+}
+
+// TestBuildSchemaFromStructIgnoresPrefixTag confirms that a "prefix" tag
+// (the text-parsing anchor core.FieldMetadata.Prefix is built from) has no
+// bearing on the generated schema's property key, which always comes from
+// the "json" tag (or the Go field name) rather than the prefix.
+func TestBuildSchemaFromStructIgnoresPrefixTag(t *testing.T) {
+	type Answer struct {
+		Answer string `json:"answer" prefix:"Final Answer:"`
+	}
+
+	schema := BuildSchemaFromStruct(Answer{})
+
+	if _, ok := schema.Properties["answer"]; !ok {
+		t.Fatalf("expected property key to be the plain field name")
+	}
+	if _, ok := schema.Properties["Final Answer:"]; ok {
+		t.Fatalf("prefix must not leak into the JSON schema's property key")
+	}
+}
+
+func TestTypeSchemaValidateValid(t *testing.T) {
+	schema := BuildSchemaFromStruct(struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}{})
+	if err := schema.Validate(); err != nil {
+		t.Fatalf("expected valid schema, got error: %v", err)
+	}
+}
+
+func TestTypeSchemaValidateArrayMissingItems(t *testing.T) {
+	schema := &TypeSchema{Type: string(TypeArray)}
+	err := schema.Validate()
+	if err == nil {
+		t.Fatal("expected error for array with no items")
+	}
+	if !strings.Contains(err.Error(), "no items") {
+		t.Errorf("expected error to mention missing items, got: %v", err)
+	}
+}
+
+func TestTypeSchemaValidateRequiredFieldMissingFromProperties(t *testing.T) {
+	schema := &TypeSchema{
+		Type:       string(TypeObject),
+		Properties: map[string]*TypeSchema{"name": {Type: string(TypeString)}},
+		Required:   []string{"name", "age"},
+	}
+	err := schema.Validate()
+	if err == nil {
+		t.Fatal("expected error for required field missing from properties")
+	}
+	if !strings.Contains(err.Error(), `"age"`) {
+		t.Errorf("expected error to name the missing field 'age', got: %v", err)
+	}
+}
+
+func TestTypeSchemaValidateMinGreaterThanMax(t *testing.T) {
+	schema := &TypeSchema{
+		Type:    string(TypeNumber),
+		Minimum: float64Ptr(10),
+		Maximum: float64Ptr(1),
+	}
+	err := schema.Validate()
+	if err == nil {
+		t.Fatal("expected error for minimum greater than maximum")
+	}
+}
+
+func TestTypeSchemaValidateRecursesIntoPropertiesAndItems(t *testing.T) {
+	schema := &TypeSchema{
+		Type: string(TypeObject),
+		Properties: map[string]*TypeSchema{
+			"tags": {Type: string(TypeArray)}, // missing Items, should be caught
+		},
+		PropertyOrdering: []string{"tags"},
+	}
+	err := schema.Validate()
+	if err == nil {
+		t.Fatal("expected error for nested array with no items")
+	}
+	if !strings.Contains(err.Error(), "properties.tags") {
+		t.Errorf("expected error path to mention properties.tags, got: %v", err)
+	}
+}
+
+func TestTypeSchemaValidateNilSchema(t *testing.T) {
+	var schema *TypeSchema
+	if err := schema.Validate(); err != nil {
+		t.Errorf("expected nil schema to be valid (nothing to check), got: %v", err)
+	}
+}
+
+func TestTypeSchemaToJSONSchemaLowercasesTypes(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Person struct {
+		Name      string    `json:"name"`
+		Age       int       `json:"age"`
+		Addresses []Address `json:"addresses"`
+	}
+
+	schema := BuildSchemaFromStruct(Person{})
+	jsonSchema := schema.ToJSONSchema()
+
+	if jsonSchema["type"] != "object" {
+		t.Fatalf("expected type 'object', got %v", jsonSchema["type"])
+	}
+
+	properties, ok := jsonSchema["properties"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected properties to be a map")
+	}
+
+	name, ok := properties["name"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected 'name' property to be a map")
+	}
+	if name["type"] != "string" {
+		t.Fatalf("expected name type 'string', got %v", name["type"])
+	}
+
+	addresses, ok := properties["addresses"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected 'addresses' property to be a map")
+	}
+	if addresses["type"] != "array" {
+		t.Fatalf("expected addresses type 'array', got %v", addresses["type"])
+	}
+	items, ok := addresses["items"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected addresses.items to be a map")
+	}
+	if items["type"] != "object" {
+		t.Fatalf("expected items type 'object', got %v", items["type"])
+	}
+}
+
+func TestTypeSchemaToJSONSchemaNilSchema(t *testing.T) {
+	var schema *TypeSchema
+	if got := schema.ToJSONSchema(); got != nil {
+		t.Errorf("expected nil schema to produce nil output, got %v", got)
+	}
+}
+
+func TestTypeSchemaToJSONSchemaOmitsEmptyFields(t *testing.T) {
+	schema := &TypeSchema{Type: string(TypeString)}
+	jsonSchema := schema.ToJSONSchema()
+
+	if len(jsonSchema) != 1 {
+		t.Fatalf("expected only 'type' to be set, got %v", jsonSchema)
+	}
+	if jsonSchema["type"] != "string" {
+		t.Fatalf("expected type 'string', got %v", jsonSchema["type"])
+	}
+}
+
+func TestBuildSchemaFromStructCachesByType(t *testing.T) {
+	type Cached struct {
+		Name string `json:"name"`
+	}
+
+	first := BuildSchemaFromStruct(Cached{})
+	second := BuildSchemaFromStruct(Cached{})
+
+	if first == second {
+		t.Fatal("expected two independent clones, got the same pointer")
+	}
+	if second.Properties["name"] == first.Properties["name"] {
+		t.Fatal("expected cloned nested schemas to be independent pointers too")
+	}
+}
+
+func TestBuildSchemaFromStructCacheIsolatesMutation(t *testing.T) {
+	type Mutable struct {
+		Name string `json:"name"`
+	}
+
+	first := BuildSchemaFromStruct(Mutable{})
+	first.Properties["name"].Description = "mutated by caller"
+	first.Required[0] = "tampered"
+
+	second := BuildSchemaFromStruct(Mutable{})
+	if second.Properties["name"].Description != "" {
+		t.Fatalf("mutation on a returned schema leaked into the next call: %q", second.Properties["name"].Description)
+	}
+	if second.Required[0] != "name" {
+		t.Fatalf("mutation on a returned schema's Required slice leaked into the next call: %v", second.Required)
+	}
+}
+
+type benchSchemaTarget struct {
+	Name    string            `json:"name"`
+	Age     int               `json:"age"`
+	Emails  []string          `json:"emails"`
+	Address benchSchemaNested `json:"address"`
+}
+
+type benchSchemaNested struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+	Zip    string `json:"zip"`
+}
+
+// BenchmarkBuildSchemaFromStructUncached reflects over benchSchemaTarget on
+// every call by clearing the cache first, showing the cost BuildSchemaFromStruct
+// used to pay on every call before caching was added.
+func BenchmarkBuildSchemaFromStructUncached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		schemaCache = sync.Map{}
+		BuildSchemaFromStruct(benchSchemaTarget{})
+	}
+}
+
+// BenchmarkBuildSchemaFromStructCached reflects once, then serves every
+// subsequent call from schemaCache.
+func BenchmarkBuildSchemaFromStructCached(b *testing.B) {
+	BuildSchemaFromStruct(benchSchemaTarget{}) // warm the cache
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		BuildSchemaFromStruct(benchSchemaTarget{})
+	}
+}
+
+func TestTypeSchemaClone_MutationDoesNotAffectOriginal(t *testing.T) {
+	minimum := 1.0
+	original := &TypeSchema{
+		Type:        string(TypeObject),
+		Description: "original description",
+		Required:    []string{"name"},
+		Properties: map[string]*TypeSchema{
+			"name": {Type: string(TypeString), Minimum: &minimum},
+		},
+		Items: &TypeSchema{Type: string(TypeString)},
+		AnyOf: []*TypeSchema{{Type: string(TypeInteger)}},
+	}
+
+	clone := original.Clone()
+	clone.Description = "mutated"
+	clone.Required[0] = "tampered"
+	clone.Properties["name"].Description = "mutated nested"
+	*clone.Properties["name"].Minimum = 99
+	clone.Items.Type = string(TypeInteger)
+	clone.AnyOf[0].Type = string(TypeBoolean)
+	clone.Properties["extra"] = &TypeSchema{Type: string(TypeBoolean)}
+
+	if original.Description != "original description" {
+		t.Errorf("expected original.Description untouched, got %q", original.Description)
+	}
+	if original.Required[0] != "name" {
+		t.Errorf("expected original.Required untouched, got %v", original.Required)
+	}
+	if original.Properties["name"].Description != "" {
+		t.Errorf("expected original nested property untouched, got %q", original.Properties["name"].Description)
+	}
+	if *original.Properties["name"].Minimum != 1.0 {
+		t.Errorf("expected original nested Minimum untouched, got %v", *original.Properties["name"].Minimum)
+	}
+	if original.Items.Type != string(TypeString) {
+		t.Errorf("expected original.Items untouched, got %v", original.Items.Type)
+	}
+	if original.AnyOf[0].Type != string(TypeInteger) {
+		t.Errorf("expected original.AnyOf untouched, got %v", original.AnyOf[0].Type)
+	}
+	if _, ok := original.Properties["extra"]; ok {
+		t.Error("expected adding a property to the clone not to appear on the original")
+	}
+}
+
+func TestBuildSchemaFromStructAnyFieldIsPermissiveByDefault(t *testing.T) {
+	type Event struct {
+		Payload any `json:"payload"`
+	}
+
+	schema := BuildSchemaFromStruct(Event{})
+
+	payload, ok := schema.Properties["payload"]
+	if !ok {
+		t.Fatal("expected 'payload' property to be present")
+	}
+	if payload.Type != "" {
+		t.Fatalf("expected an any field to have no type constraint, got %q", payload.Type)
+	}
+	if payload.AnyOf != nil {
+		t.Fatalf("expected no AnyOf without an anyof tag, got %v", payload.AnyOf)
+	}
+	if !contains(schema.Required, "payload") {
+		t.Fatalf("expected required 'payload' field to still be listed as required, got %v", schema.Required)
+	}
+}
+
+func TestBuildSchemaFromStructAnyFieldWithAnyOfTag(t *testing.T) {
+	type Event struct {
+		Payload any `json:"payload" anyof:"string,integer,boolean"`
+	}
+
+	schema := BuildSchemaFromStruct(Event{})
+
+	payload := schema.Properties["payload"]
+	if len(payload.AnyOf) != 3 {
+		t.Fatalf("expected 3 AnyOf alternatives, got %d", len(payload.AnyOf))
+	}
+	got := []string{payload.AnyOf[0].Type, payload.AnyOf[1].Type, payload.AnyOf[2].Type}
+	want := []string{string(TypeString), string(TypeInteger), string(TypeBoolean)}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("AnyOf[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func contains(haystack []string, needle string) bool {
+	for _, v := range haystack {
+		if v == needle {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBuildSchemaFromStructPointerFieldExcludedFromRequiredByDefault(t *testing.T) {
+	type Profile struct {
+		Name     string  `json:"name"`
+		Nickname *string `json:"nickname"`
+	}
+
+	schema := BuildSchemaFromStruct(Profile{})
+
+	if !contains(schema.Required, "name") {
+		t.Errorf("expected non-pointer field to remain required, got %v", schema.Required)
+	}
+	if contains(schema.Required, "nickname") {
+		t.Errorf("expected pointer field excluded from Required by default, got %v", schema.Required)
+	}
+}
+
+func TestBuildSchemaFromStructPointerFieldRequiredOverrideTag(t *testing.T) {
+	type Profile struct {
+		Nickname *string `json:"nickname" dspy:",required"`
+	}
+
+	schema := BuildSchemaFromStruct(Profile{})
+
+	if !contains(schema.Required, "nickname") {
+		t.Errorf("expected dspy:\",required\" to override the pointer default, got %v", schema.Required)
+	}
+}
+
+func TestBuildSchemaFromStructTransientFieldExcludedFromRequired(t *testing.T) {
+	type Completion struct {
+		Reasoning string `json:"reasoning" dspy:",transient"`
+		Answer    string `json:"answer"`
+	}
+
+	schema := BuildSchemaFromStruct(Completion{})
+
+	if _, ok := schema.Properties["reasoning"]; !ok {
+		t.Fatal("expected transient field to still be described in Properties")
+	}
+	if contains(schema.Required, "reasoning") {
+		t.Errorf("expected transient field excluded from Required, got %v", schema.Required)
+	}
+	if !contains(schema.Required, "answer") {
+		t.Errorf("expected non-transient field still required, got %v", schema.Required)
+	}
+}
+
+func TestBuildSchemaFromStructClosedMarkerFieldSetsAdditionalPropertiesFalse(t *testing.T) {
+	type Address struct {
+		Zip string   `json:"zip"`
+		_   struct{} `dspy:",closed"`
+	}
+
+	type Profile struct {
+		Name    string  `json:"name"`
+		Address Address `json:"address"`
+	}
+
+	schema := BuildSchemaFromStruct(Profile{})
+
+	if schema.AdditionalProperties != nil {
+		t.Errorf("expected root schema to allow additional properties, got %+v", schema.AdditionalProperties)
+	}
+
+	address, ok := schema.Properties["address"]
+	if !ok {
+		t.Fatal("expected address property in schema")
+	}
+	if address.AdditionalProperties == nil || address.AdditionalProperties.Bool == nil || *address.AdditionalProperties.Bool != false {
+		t.Errorf("expected address.AdditionalProperties to be the bool false, got %+v", address.AdditionalProperties)
+	}
+
+	b, err := json.Marshal(address)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(b), `"additionalProperties":false`) {
+		t.Errorf("expected additionalProperties:false in generated schema JSON, got %s", string(b))
+	}
+}
+
+func TestAdditionalPropertiesSchemaFormRoundTrips(t *testing.T) {
+	schema := &TypeSchema{
+		Type:                 string(TypeObject),
+		AdditionalProperties: AdditionalPropertiesSchema(&TypeSchema{Type: string(TypeString)}),
+	}
+
+	b, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded TypeSchema
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.AdditionalProperties == nil || decoded.AdditionalProperties.Schema == nil {
+		t.Fatalf("expected decoded AdditionalProperties to carry a schema, got %+v", decoded.AdditionalProperties)
+	}
+	if decoded.AdditionalProperties.Schema.Type != string(TypeString) {
+		t.Errorf("expected decoded additionalProperties schema type STRING, got %s", decoded.AdditionalProperties.Schema.Type)
+	}
+}
+
+// schemaTestStatus is a named string type registered with RegisterEnum so
+// BuildSchemaFromStruct can populate its schema's Enum automatically.
+type schemaTestStatus string
+
+const (
+	schemaTestStatusOpen   schemaTestStatus = "open"
+	schemaTestStatusClosed schemaTestStatus = "closed"
+)
+
+// schemaTestUnregisteredKind is never passed to RegisterEnum, to confirm
+// the fallback to a plain, unconstrained string.
+type schemaTestUnregisteredKind string
+
+func TestBuildSchemaFromStructRegisteredEnumPopulatesEnum(t *testing.T) {
+	RegisterEnum(schemaTestStatusOpen, schemaTestStatusClosed)
+
+	type Task struct {
+		Status schemaTestStatus           `json:"status"`
+		Kind   schemaTestUnregisteredKind `json:"kind"`
+	}
+
+	schema := BuildSchemaFromStruct(Task{})
+
+	status, ok := schema.Properties["status"]
+	if !ok {
+		t.Fatal("expected status property in schema")
+	}
+	if !stringSlicesEqual(status.Enum, []string{"open", "closed"}) {
+		t.Errorf("expected status.Enum = [open closed], got %v", status.Enum)
+	}
+
+	kind, ok := schema.Properties["kind"]
+	if !ok {
+		t.Fatal("expected kind property in schema")
+	}
+	if len(kind.Enum) != 0 {
+		t.Errorf("expected unregistered type to have no Enum, got %v", kind.Enum)
+	}
+}
+
+func TestValidateEnum(t *testing.T) {
+	RegisterEnum(schemaTestStatusOpen, schemaTestStatusClosed)
+
+	statusType := reflect.TypeOf(schemaTestStatusOpen)
+	if !ValidateEnum(statusType, "open") {
+		t.Error("expected 'open' to be a valid enum value")
+	}
+	if ValidateEnum(statusType, "archived") {
+		t.Error("expected 'archived' to be rejected as an invalid enum value")
+	}
+
+	kindType := reflect.TypeOf(schemaTestUnregisteredKind(""))
+	if !ValidateEnum(kindType, "anything") {
+		t.Error("expected an unregistered type to accept any value")
+	}
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTypeSchemaClone_Nil(t *testing.T) {
+	var schema *TypeSchema
+	if clone := schema.Clone(); clone != nil {
+		t.Errorf("expected Clone of a nil schema to be nil, got %v", clone)
+	}
+}
+
+func TestTypeSchemaMergeFieldDescriptionsFillsEmptyOnly(t *testing.T) {
+	schema := &TypeSchema{
+		Type: string(TypeObject),
+		Properties: map[string]*TypeSchema{
+			"Name": {Type: string(TypeString)},
+			"Age":  {Type: string(TypeInteger), Description: "already tagged"},
+		},
+	}
+
+	schema.MergeFieldDescriptions(map[string]string{
+		"Name":    "the person's full name",
+		"Age":     "from a doc comment, should be ignored",
+		"Missing": "no matching property",
+	})
+
+	if got := schema.Properties["Name"].Description; got != "the person's full name" {
+		t.Errorf("Properties[Name].Description = %q, want %q", got, "the person's full name")
+	}
+	if got := schema.Properties["Age"].Description; got != "already tagged" {
+		t.Errorf("expected tag-provided description to take precedence, got %q", got)
+	}
+}
+
+func TestMinifySchemaDropsTitleRedundantWithPropertyKey(t *testing.T) {
+	schema := &TypeSchema{
+		Type: string(TypeObject),
+		Properties: map[string]*TypeSchema{
+			"name": {Type: string(TypeString), Title: "name", Description: "the user's name"},
+		},
+		PropertyOrdering: []string{"name"},
+	}
+
+	minified := MinifySchema(schema, MinifyOptions{})
+
+	if got := minified.Properties["name"].Title; got != "" {
+		t.Errorf("expected redundant title to be dropped, got %q", got)
+	}
+	if got := minified.Properties["name"].Description; got != "the user's name" {
+		t.Errorf("expected description to survive without DropDescriptions, got %q", got)
+	}
+}
+
+func TestMinifySchemaKeepsTitleThatDiffersFromPropertyKey(t *testing.T) {
+	schema := &TypeSchema{
+		Type: string(TypeObject),
+		Properties: map[string]*TypeSchema{
+			"name": {Type: string(TypeString), Title: "Full Name"},
+		},
+	}
+
+	minified := MinifySchema(schema, MinifyOptions{})
+
+	if got := minified.Properties["name"].Title; got != "Full Name" {
+		t.Errorf("expected non-redundant title to survive, got %q", got)
+	}
+}
+
+func TestMinifySchemaDropDescriptionsOption(t *testing.T) {
+	schema := &TypeSchema{
+		Type:        string(TypeObject),
+		Description: "a user",
+		Properties: map[string]*TypeSchema{
+			"name": {Type: string(TypeString), Description: "the user's name"},
+		},
+	}
+
+	minified := MinifySchema(schema, MinifyOptions{DropDescriptions: true})
+
+	if minified.Description != "" {
+		t.Errorf("expected root description to be dropped, got %q", minified.Description)
+	}
+	if got := minified.Properties["name"].Description; got != "" {
+		t.Errorf("expected nested description to be dropped, got %q", got)
+	}
+}
+
+func TestMinifySchemaRecursesIntoItemsAndAnyOf(t *testing.T) {
+	schema := &TypeSchema{
+		Type: string(TypeArray),
+		Items: &TypeSchema{
+			Type: string(TypeObject),
+			AnyOf: []*TypeSchema{
+				{Type: string(TypeString), Description: "alt"},
+			},
+		},
+	}
+
+	minified := MinifySchema(schema, MinifyOptions{DropDescriptions: true})
+
+	if got := minified.Items.AnyOf[0].Description; got != "" {
+		t.Errorf("expected anyOf alternative's description to be dropped, got %q", got)
+	}
+}
+
+func TestMinifySchemaPreservesSemantics(t *testing.T) {
+	schema := BuildSchemaFromStruct(struct {
+		Name string `json:"name"`
+		Age  int    `json:"age,omitempty"`
+	}{})
+	schema.Title = "Person"
+	schema.Description = strings.Repeat("x", 200)
+	schema.Properties["name"].Title = "name"
+	schema.Properties["name"].Description = strings.Repeat("y", 200)
+
+	before, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("failed to marshal original schema: %v", err)
+	}
+
+	minified := MinifySchema(schema, MinifyOptions{DropDescriptions: true})
+	after, err := json.Marshal(minified)
+	if err != nil {
+		t.Fatalf("failed to marshal minified schema: %v", err)
+	}
+
+	if len(after) >= len(before) {
+		t.Errorf("expected minified schema to be smaller, got %d bytes vs original %d bytes", len(after), len(before))
+	}
+	if minified.Type != schema.Type {
+		t.Errorf("expected Type to be preserved, got %q want %q", minified.Type, schema.Type)
+	}
+	if !reflect.DeepEqual(minified.Required, schema.Required) {
+		t.Errorf("expected Required to be preserved, got %v want %v", minified.Required, schema.Required)
+	}
+	if minified.Properties["name"].Type != schema.Properties["name"].Type {
+		t.Errorf("expected nested property Type to be preserved")
+	}
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TransformFunc normalizes a single string field value, e.g. trimming
+// whitespace or lowercasing.
+type TransformFunc func(string) string
+
+var (
+	transformMu sync.RWMutex
+	// transformRegistry holds the built-in transforms plus anything added via
+	// RegisterTransform. Pre-seeded with trim/lowercase/collapse_whitespace so
+	// the common cases need no setup.
+	transformRegistry = map[string]TransformFunc{
+		"trim":                strings.TrimSpace,
+		"lowercase":           strings.ToLower,
+		"collapse_whitespace": collapseWhitespace,
+	}
+)
+
+// RegisterTransform adds fn to the registry under name, for use in a
+// `transform:"..."` struct tag. Registering the same name twice is not an
+// error - the later call wins.
+func RegisterTransform(name string, fn TransformFunc) {
+	transformMu.Lock()
+	defer transformMu.Unlock()
+	transformRegistry[name] = fn
+}
+
+func getTransform(name string) (TransformFunc, bool) {
+	transformMu.RLock()
+	defer transformMu.RUnlock()
+	fn, ok := transformRegistry[name]
+	return fn, ok
+}
+
+func collapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// ApplyFieldTransforms returns a copy of input with every string field that
+// carries a `transform:"name1,name2"` tag normalized by running it through
+// each named transform in tag order. Unknown transform names are skipped.
+// input may be a struct or a pointer to one; anything else is returned
+// unchanged. Callers that need transformed values to participate in
+// required-field validation (e.g. a whitespace-only value that trims down to
+// empty) should call this before validating, since validating first would
+// see the untransformed value.
+func ApplyFieldTransforms[T any](input T) T {
+	v := reflect.ValueOf(input)
+
+	isPtr := v.Kind() == reflect.Ptr
+	if isPtr {
+		if v.IsNil() {
+			return input
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return input
+	}
+
+	out := reflect.New(v.Type()).Elem()
+	out.Set(v)
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Type.Kind() != reflect.String {
+			continue
+		}
+
+		tag := field.Tag.Get("transform")
+		if tag == "" {
+			continue
+		}
+
+		value := out.Field(i).String()
+		for _, name := range strings.Split(tag, ",") {
+			if fn, ok := getTransform(strings.TrimSpace(name)); ok {
+				value = fn(value)
+			}
+		}
+		out.Field(i).SetString(value)
+	}
+
+	if isPtr {
+		return out.Addr().Interface().(T)
+	}
+	return out.Interface().(T)
+}
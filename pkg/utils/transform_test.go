@@ -0,0 +1,76 @@
+package utils
+
+import "testing"
+
+type transformTestInputs struct {
+	Name  string `transform:"trim"`
+	Email string `transform:"trim,lowercase"`
+	Notes string `transform:"collapse_whitespace"`
+	Plain string
+}
+
+func TestApplyFieldTransformsBuiltins(t *testing.T) {
+	in := transformTestInputs{
+		Name:  "  Ada  ",
+		Email: "  ADA@Example.com ",
+		Notes: "too   much\n\twhitespace",
+		Plain: "  untouched  ",
+	}
+
+	out := ApplyFieldTransforms(in)
+
+	if out.Name != "Ada" {
+		t.Errorf("Name = %q, want %q", out.Name, "Ada")
+	}
+	if out.Email != "ada@example.com" {
+		t.Errorf("Email = %q, want %q", out.Email, "ada@example.com")
+	}
+	if out.Notes != "too much whitespace" {
+		t.Errorf("Notes = %q, want %q", out.Notes, "too much whitespace")
+	}
+	if out.Plain != "  untouched  " {
+		t.Errorf("Plain (no tag) = %q, want it left alone", out.Plain)
+	}
+}
+
+func TestApplyFieldTransformsAppliesTagOrder(t *testing.T) {
+	in := transformTestInputs{Email: "  ADA@Example.com "}
+	out := ApplyFieldTransforms(in)
+	if out.Email != "ada@example.com" {
+		t.Errorf("expected trim then lowercase in tag order, got %q", out.Email)
+	}
+}
+
+func TestApplyFieldTransformsPointerInput(t *testing.T) {
+	in := &transformTestInputs{Name: "  Ada  "}
+	out := ApplyFieldTransforms(in)
+	if out.Name != "Ada" {
+		t.Errorf("Name = %q, want %q", out.Name, "Ada")
+	}
+}
+
+func TestApplyFieldTransformsNonStructPassthrough(t *testing.T) {
+	if got := ApplyFieldTransforms("hello"); got != "hello" {
+		t.Errorf("expected non-struct input to pass through unchanged, got %q", got)
+	}
+}
+
+func TestApplyFieldTransformsNilPointerPassthrough(t *testing.T) {
+	var in *transformTestInputs
+	if got := ApplyFieldTransforms(in); got != nil {
+		t.Errorf("expected nil pointer to pass through unchanged, got %v", got)
+	}
+}
+
+func TestRegisterTransformCustom(t *testing.T) {
+	RegisterTransform("redact_test", func(string) string { return "[REDACTED]" })
+
+	type withCustom struct {
+		Secret string `transform:"redact_test"`
+	}
+
+	out := ApplyFieldTransforms(withCustom{Secret: "super-secret"})
+	if out.Secret != "[REDACTED]" {
+		t.Errorf("Secret = %q, want %q", out.Secret, "[REDACTED]")
+	}
+}
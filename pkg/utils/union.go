@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// discriminatorFieldName is the JSON field DiscriminatedUnion and
+// buildSchemaFromType's union branch use to pick a member type - a plain
+// "type" string, matching the common discriminated-union convention.
+const discriminatorFieldName = "type"
+
+// UnionRegistration is one member of a discriminated union, as registered
+// via RegisterUnion.
+type UnionRegistration struct {
+	Discriminator string
+	Type          reflect.Type
+}
+
+// unionRegistry maps the union's interface type to its registered member
+// types, mirroring enumRegistry's reflect.Type-keyed design. A plain mutex
+// guards it instead of sync.Map since registering a member appends to the
+// existing slice rather than replacing it wholesale.
+var (
+	unionMu       sync.Mutex
+	unionRegistry = map[reflect.Type][]UnionRegistration{}
+)
+
+// RegisterUnion adds member as a branch of the discriminated union T,
+// selected when the JSON "type" field equals discriminator. Call it once per
+// member, typically from an init() function, before any schema is built or
+// response is parsed for a DiscriminatedUnion[T] field.
+func RegisterUnion[T any](discriminator string, member any) {
+	var zero T
+	ifaceType := reflect.TypeOf(&zero).Elem()
+	memberType := reflect.TypeOf(member)
+
+	unionMu.Lock()
+	defer unionMu.Unlock()
+	unionRegistry[ifaceType] = append(unionRegistry[ifaceType], UnionRegistration{
+		Discriminator: discriminator,
+		Type:          memberType,
+	})
+}
+
+// UnionMembers returns the members registered against ifaceType via
+// RegisterUnion, or ok=false if none have been registered.
+func UnionMembers(ifaceType reflect.Type) (members []UnionRegistration, ok bool) {
+	unionMu.Lock()
+	defer unionMu.Unlock()
+	regs, found := unionRegistry[ifaceType]
+	if !found {
+		return nil, false
+	}
+	out := make([]UnionRegistration, len(regs))
+	copy(out, regs)
+	return out, true
+}
+
+// DiscriminatedUnion wraps an interface-typed value whose concrete type is
+// chosen at parse time by a JSON "type" discriminator, e.g. a response that
+// is either a Success or an Error. Declare a struct field as
+// DiscriminatedUnion[T] instead of T directly; its UnmarshalJSON reads the
+// discriminator and unmarshals into whichever member was registered for it
+// via RegisterUnion, and buildSchemaFromType emits an AnyOf schema with each
+// member's discriminator field constrained to its own value.
+type DiscriminatedUnion[T any] struct {
+	Value T
+}
+
+// unionInterfaceType lets buildSchemaFromType recognize a DiscriminatedUnion[T]
+// field and look up its members without needing to know T itself - see the
+// unionValue check in buildSchemaFromType.
+func (DiscriminatedUnion[T]) unionInterfaceType() reflect.Type {
+	var zero T
+	return reflect.TypeOf(&zero).Elem()
+}
+
+// unionValue is implemented by DiscriminatedUnion[T] for any T.
+type unionValue interface {
+	unionInterfaceType() reflect.Type
+}
+
+// UnmarshalJSON reads data's "type" field and unmarshals data into whichever
+// member RegisterUnion registered under that discriminator. It returns an
+// error naming the discriminator if no member matches, so a response with
+// an unexpected or missing type is a parse error rather than a silently
+// empty union.
+func (u *DiscriminatedUnion[T]) UnmarshalJSON(data []byte) error {
+	var tag struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &tag); err != nil {
+		return fmt.Errorf("discriminated union: reading discriminator: %w", err)
+	}
+
+	ifaceType := u.unionInterfaceType()
+	members, ok := UnionMembers(ifaceType)
+	if !ok {
+		return fmt.Errorf("discriminated union: no members registered for %s", ifaceType)
+	}
+
+	for _, member := range members {
+		if member.Discriminator != tag.Type {
+			continue
+		}
+
+		instance := reflect.New(member.Type)
+		if err := json.Unmarshal(data, instance.Interface()); err != nil {
+			return fmt.Errorf("discriminated union: unmarshaling %q branch: %w", tag.Type, err)
+		}
+
+		value, ok := instance.Elem().Interface().(T)
+		if !ok {
+			return fmt.Errorf("discriminated union: registered type %s does not implement %s", member.Type, ifaceType)
+		}
+		u.Value = value
+		return nil
+	}
+
+	return fmt.Errorf("discriminated union: unknown discriminator %q", tag.Type)
+}
+
+// MarshalJSON marshals the union's current concrete value directly, with no
+// wrapper - round-tripping through DiscriminatedUnion is transparent.
+func (u DiscriminatedUnion[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.Value)
+}
+
+// unionFieldSchema builds the AnyOf schema for a discriminated-union field:
+// one branch per registered member, each with its discriminator field
+// constrained to that member's own value and marked required.
+func unionFieldSchema(ifaceType reflect.Type) *TypeSchema {
+	members, ok := UnionMembers(ifaceType)
+	if !ok || len(members) == 0 {
+		return &TypeSchema{}
+	}
+
+	anyOf := make([]*TypeSchema, 0, len(members))
+	for _, member := range members {
+		memberSchema := buildSchemaFromType(member.Type)
+		if discSchema, ok := memberSchema.Properties[discriminatorFieldName]; ok {
+			discSchema.Enum = []string{member.Discriminator}
+		}
+		if !sliceContainsString(memberSchema.Required, discriminatorFieldName) {
+			memberSchema.Required = append(memberSchema.Required, discriminatorFieldName)
+		}
+		anyOf = append(anyOf, memberSchema)
+	}
+
+	return &TypeSchema{AnyOf: anyOf}
+}
+
+func sliceContainsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type unionTestResult interface {
+	unionTestResultMarker()
+}
+
+type unionTestSuccess struct {
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+func (unionTestSuccess) unionTestResultMarker() {}
+
+type unionTestError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func (unionTestError) unionTestResultMarker() {}
+
+func init() {
+	RegisterUnion[unionTestResult]("success", unionTestSuccess{})
+	RegisterUnion[unionTestResult]("error", unionTestError{})
+}
+
+func TestDiscriminatedUnionUnmarshalsSuccessBranch(t *testing.T) {
+	var u DiscriminatedUnion[unionTestResult]
+	if err := json.Unmarshal([]byte(`{"type":"success","data":"ok"}`), &u); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, ok := u.Value.(unionTestSuccess)
+	if !ok {
+		t.Fatalf("expected unionTestSuccess, got %T", u.Value)
+	}
+	if got.Data != "ok" {
+		t.Fatalf("Data = %q, want %q", got.Data, "ok")
+	}
+}
+
+func TestDiscriminatedUnionUnmarshalsErrorBranch(t *testing.T) {
+	var u DiscriminatedUnion[unionTestResult]
+	if err := json.Unmarshal([]byte(`{"type":"error","message":"boom"}`), &u); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, ok := u.Value.(unionTestError)
+	if !ok {
+		t.Fatalf("expected unionTestError, got %T", u.Value)
+	}
+	if got.Message != "boom" {
+		t.Fatalf("Message = %q, want %q", got.Message, "boom")
+	}
+}
+
+func TestDiscriminatedUnionUnmarshalUnknownDiscriminator(t *testing.T) {
+	var u DiscriminatedUnion[unionTestResult]
+	err := json.Unmarshal([]byte(`{"type":"timeout"}`), &u)
+	if err == nil {
+		t.Fatal("expected an error for an unknown discriminator, got nil")
+	}
+}
+
+func TestDiscriminatedUnionMarshalRoundTrips(t *testing.T) {
+	u := DiscriminatedUnion[unionTestResult]{Value: unionTestSuccess{Type: "success", Data: "ok"}}
+
+	data, err := json.Marshal(u)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var roundTripped DiscriminatedUnion[unionTestResult]
+	if err := json.Unmarshal(data, &roundTripped); err != nil {
+		t.Fatalf("unexpected error round-tripping: %v", err)
+	}
+	if roundTripped.Value != u.Value {
+		t.Fatalf("round-tripped value = %v, want %v", roundTripped.Value, u.Value)
+	}
+}
+
+func TestBuildSchemaFromStructUnionFieldEmitsAnyOf(t *testing.T) {
+	type Response struct {
+		Result DiscriminatedUnion[unionTestResult] `json:"result"`
+	}
+
+	schema := BuildSchemaFromStruct(Response{})
+
+	result, ok := schema.Properties["result"]
+	if !ok {
+		t.Fatal("expected 'result' property to be present")
+	}
+	if len(result.AnyOf) != 2 {
+		t.Fatalf("expected 2 AnyOf branches, got %d", len(result.AnyOf))
+	}
+
+	for _, branch := range result.AnyOf {
+		typeSchema, ok := branch.Properties["type"]
+		if !ok {
+			t.Fatalf("expected branch %+v to have a 'type' property", branch)
+		}
+		if len(typeSchema.Enum) != 1 {
+			t.Fatalf("expected 'type' to be constrained to a single enum value, got %v", typeSchema.Enum)
+		}
+		if !contains(branch.Required, "type") {
+			t.Fatalf("expected 'type' to be required on branch %+v", branch)
+		}
+	}
+}
+
+func TestUnionMembersUnregisteredInterfaceReturnsFalse(t *testing.T) {
+	type unregistered interface{ unregisteredMarker() }
+
+	var zero unregistered
+	if _, ok := UnionMembers(reflect.TypeOf(&zero).Elem()); ok {
+		t.Fatal("expected no members for an interface nothing registered against")
+	}
+}
@@ -15,12 +15,39 @@ import (
 // It handles common LLM response formats including:
 // - Raw JSON.
 // - JSON wrapped in markdown code blocks (```json ... ```).
+//
+// Numbers decode as float64, which loses precision for large integers.
+// Use ParseJSONResponseWithNumber when that matters (e.g. IDs, money
+// amounts) and coerce the resulting json.Number values yourself.
 func ParseJSONResponse(response string) (map[string]interface{}, error) {
+	return parseJSONResponse(response, false)
+}
+
+// ParseJSONResponseWithNumber behaves like ParseJSONResponse, except
+// numbers decode as json.Number instead of float64, preserving precision
+// for large integers and exact decimal values. Combine with
+// CoerceNumericField to convert specific fields into int64 or float64.
+func ParseJSONResponseWithNumber(response string) (map[string]interface{}, error) {
+	return parseJSONResponse(response, true)
+}
+
+// parseJSONResponse parses response as JSON. On failure it returns an
+// *errors.Error whose fields carry "raw_response" (the full, untruncated
+// response text) and "data_preview" (a short truncated preview for log
+// lines), so callers can recover the exact text that failed to parse via
+// err.(*errors.Error).Fields()["raw_response"] without it bloating
+// err.Error()'s one-line summary.
+func parseJSONResponse(response string, useNumber bool) (map[string]interface{}, error) {
 	// Strip markdown code blocks if present
 	cleanedResponse := stripMarkdownCodeBlock(response)
 
+	decoder := json.NewDecoder(strings.NewReader(cleanedResponse))
+	if useNumber {
+		decoder.UseNumber()
+	}
+
 	var result map[string]interface{}
-	err := json.Unmarshal([]byte(cleanedResponse), &result)
+	err := decoder.Decode(&result)
 	if err != nil {
 		return nil, errors.WithFields(
 			errors.Wrap(err, errors.InvalidResponse, "failed to parse JSON"),
@@ -28,11 +55,154 @@ func ParseJSONResponse(response string) (map[string]interface{}, error) {
 				"error_type":   "json_parse_error",
 				"data_preview": truncateString(response, 100),
 				"data_length":  len(response),
+				"raw_response": response,
 			})
 	}
 	return result, nil
 }
 
+// CoerceNumericField converts a decoded JSON value - typically a
+// json.Number from ParseJSONResponseWithNumber, but also a float64, a
+// plain numeric string, or an already-int/float value - into an int64 or
+// a float64 depending on asInt. It returns false if v isn't a recognizable
+// number.
+func CoerceNumericField(v interface{}, asInt bool) (interface{}, bool) {
+	var f float64
+
+	switch n := v.(type) {
+	case json.Number:
+		if asInt {
+			i, err := n.Int64()
+			if err == nil {
+				return i, true
+			}
+		}
+		parsed, err := n.Float64()
+		if err != nil {
+			return nil, false
+		}
+		f = parsed
+	case float64:
+		f = n
+	case int:
+		f = float64(n)
+	case int64:
+		f = float64(n)
+	case string:
+		parsed, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return nil, false
+		}
+		f = parsed
+	default:
+		return nil, false
+	}
+
+	if asInt {
+		return int64(f), true
+	}
+	return f, true
+}
+
+// numericSeparatorStripper removes punctuation a model's numeric output
+// sometimes carries that strconv can't parse through - thousands
+// separators and underscore digit grouping - while leaving the sign,
+// decimal point, and exponent marker a number actually needs intact.
+var numericSeparatorStripper = strings.NewReplacer(",", "", "_", "", " ", "")
+
+// CoerceNumericFieldTolerant behaves like CoerceNumericField, but when v is
+// a string that doesn't parse directly, it retries after stripping
+// thousands separators ("1,234") and underscore digit grouping ("1_234").
+// With allowWrittenNumbers, it also recognizes simple English written
+// numbers like "forty-two" (see parseWrittenNumber) - that's opt-in since
+// an ordinary text value made only of number words, such as "one", would
+// otherwise be read as a number. Unlike CoerceNumericField's ok bool, a
+// string that still can't be parsed after all of that returns a descriptive
+// error instead of silently falling through.
+func CoerceNumericFieldTolerant(v interface{}, asInt bool, allowWrittenNumbers bool) (interface{}, error) {
+	if converted, ok := CoerceNumericField(v, asInt); ok {
+		return converted, nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("coerce numeric field: unsupported value type %T", v)
+	}
+
+	if converted, ok := CoerceNumericField(numericSeparatorStripper.Replace(s), asInt); ok {
+		return converted, nil
+	}
+
+	if allowWrittenNumbers {
+		if n, ok := parseWrittenNumber(s); ok {
+			if asInt {
+				return int64(n), nil
+			}
+			return n, nil
+		}
+	}
+
+	return nil, fmt.Errorf("coerce numeric field: cannot parse %q as a number", s)
+}
+
+// writtenNumberWords maps the English number words parseWrittenNumber
+// recognizes to their value. Scale words ("hundred", "thousand") multiply
+// the value accumulated so far rather than adding to it.
+var writtenNumberWords = map[string]float64{
+	"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+	"eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14, "fifteen": 15,
+	"sixteen": 16, "seventeen": 17, "eighteen": 18, "nineteen": 19,
+	"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50,
+	"sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+}
+
+var writtenNumberScaleWords = map[string]float64{
+	"hundred": 100, "thousand": 1000,
+}
+
+// parseWrittenNumber parses a simple English number phrase like "forty-two"
+// or "one hundred and twenty" into its numeric value. It only recognizes
+// the vocabulary in writtenNumberWords/writtenNumberScaleWords and returns
+// ok=false on any unrecognized word, rather than guessing.
+func parseWrittenNumber(s string) (float64, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.ReplaceAll(s, "-", " ")
+	s = strings.ReplaceAll(s, " and ", " ")
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return 0, false
+	}
+
+	var total, current float64
+	matched := false
+	for _, word := range words {
+		if value, ok := writtenNumberWords[word]; ok {
+			current += value
+			matched = true
+			continue
+		}
+		if scale, ok := writtenNumberScaleWords[word]; ok {
+			if current == 0 {
+				current = 1
+			}
+			current *= scale
+			if scale >= 1000 {
+				total += current
+				current = 0
+			}
+			matched = true
+			continue
+		}
+		return 0, false
+	}
+
+	if !matched {
+		return 0, false
+	}
+	return total + current, true
+}
+
 // stripMarkdownCodeBlock removes markdown code block wrappers from a string.
 // Handles formats like ```json\n{...}\n``` or ```\n{...}\n```.
 func stripMarkdownCodeBlock(s string) string {
@@ -247,6 +417,16 @@ func SetFieldValue(fieldValue reflect.Value, value any) error {
 			fieldValue.SetInt(intVal)
 			return nil
 		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if uintVal, ok, negative := convertToUint(value); ok {
+			if fieldValue.OverflowUint(uintVal) {
+				return fmt.Errorf("value %v overflows field of type %s", value, fieldType)
+			}
+			fieldValue.SetUint(uintVal)
+			return nil
+		} else if negative {
+			return fmt.Errorf("value %v is negative and cannot be assigned to unsigned field of type %s", value, fieldType)
+		}
 	case reflect.Float32, reflect.Float64:
 		if floatVal, ok := convertToFloat(value); ok {
 			if fieldValue.OverflowFloat(floatVal) {
@@ -319,6 +499,73 @@ func convertToInt(value any) (int64, bool) {
 	return 0, false
 }
 
+// convertToUint converts value to a uint64, reporting a separate "negative"
+// flag so callers can distinguish a failed conversion from a negative value
+// that was deliberately rejected rather than silently wrapped.
+func convertToUint(value any) (result uint64, ok bool, negative bool) {
+	switch v := value.(type) {
+	case uint:
+		return uint64(v), true, false
+	case uint8:
+		return uint64(v), true, false
+	case uint16:
+		return uint64(v), true, false
+	case uint32:
+		return uint64(v), true, false
+	case uint64:
+		return v, true, false
+	case int:
+		if v < 0 {
+			return 0, false, true
+		}
+		return uint64(v), true, false
+	case int8:
+		if v < 0 {
+			return 0, false, true
+		}
+		return uint64(v), true, false
+	case int16:
+		if v < 0 {
+			return 0, false, true
+		}
+		return uint64(v), true, false
+	case int32:
+		if v < 0 {
+			return 0, false, true
+		}
+		return uint64(v), true, false
+	case int64:
+		if v < 0 {
+			return 0, false, true
+		}
+		return uint64(v), true, false
+	case float32:
+		if v < 0 {
+			return 0, false, true
+		}
+		if v > math.MaxUint64 {
+			return 0, false, false
+		}
+		return uint64(v), true, false
+	case float64:
+		if v < 0 {
+			return 0, false, true
+		}
+		if v > math.MaxUint64 {
+			return 0, false, false
+		}
+		return uint64(v), true, false
+	case string:
+		if u, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64); err == nil {
+			return u, true, false
+		}
+		if i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil && i < 0 {
+			return 0, false, true
+		}
+	}
+	return 0, false, false
+}
+
 func convertToFloat(value any) (float64, bool) {
 	switch v := value.(type) {
 	case float32:
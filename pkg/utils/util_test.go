@@ -1,9 +1,12 @@
 package utils
 
 import (
+	"encoding/json"
 	"reflect"
 	"strings"
 	"testing"
+
+	dspyErrors "github.com/darwishdev/dspy-go/pkg/errors"
 )
 
 func TestParseJSONResponse(t *testing.T) {
@@ -98,6 +101,129 @@ func TestParseJSONResponse(t *testing.T) {
 	}
 }
 
+func TestParseJSONResponseWithNumber(t *testing.T) {
+	result, err := ParseJSONResponseWithNumber(`{"id": 9223372036854775807, "amount": 19.99}`)
+	if err != nil {
+		t.Fatalf("ParseJSONResponseWithNumber() error = %v", err)
+	}
+
+	id, ok := result["id"].(json.Number)
+	if !ok {
+		t.Fatalf("expected id to decode as json.Number, got %T", result["id"])
+	}
+	if id.String() != "9223372036854775807" {
+		t.Errorf("expected id = 9223372036854775807, got %s", id.String())
+	}
+
+	amount, ok := result["amount"].(json.Number)
+	if !ok {
+		t.Fatalf("expected amount to decode as json.Number, got %T", result["amount"])
+	}
+	if amount.String() != "19.99" {
+		t.Errorf("expected amount = 19.99, got %s", amount.String())
+	}
+}
+
+func TestParseJSONResponseDefaultsToFloat64(t *testing.T) {
+	result, err := ParseJSONResponse(`{"id": 42}`)
+	if err != nil {
+		t.Fatalf("ParseJSONResponse() error = %v", err)
+	}
+	if _, ok := result["id"].(float64); !ok {
+		t.Fatalf("expected id to decode as float64, got %T", result["id"])
+	}
+}
+
+func TestParseJSONResponse_ParseFailureCarriesFullRawText(t *testing.T) {
+	raw := "{" + strings.Repeat("x", 500) + `: "unterminated`
+
+	_, err := ParseJSONResponse(raw)
+	if err == nil {
+		t.Fatal("expected an error for malformed JSON")
+	}
+
+	dspyErr, ok := err.(*dspyErrors.Error)
+	if !ok {
+		t.Fatalf("expected *errors.Error, got %T", err)
+	}
+
+	fields := dspyErr.Fields()
+	if fields["raw_response"] != raw {
+		t.Errorf("expected raw_response field to equal the full input, got %v", fields["raw_response"])
+	}
+
+	// The one-line error message must not balloon to the full payload size.
+	if len(err.Error()) >= len(raw) {
+		t.Errorf("expected Error() to be truncated, got length %d for a %d-byte payload", len(err.Error()), len(raw))
+	}
+}
+
+func TestCoerceNumericField(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    interface{}
+		asInt    bool
+		expected interface{}
+		wantOk   bool
+	}{
+		{"json.Number to int64", json.Number("42"), true, int64(42), true},
+		{"json.Number to float64", json.Number("19.99"), false, 19.99, true},
+		{"large json.Number to int64", json.Number("9223372036854775807"), true, int64(9223372036854775807), true},
+		{"float64 to int64", float64(7), true, int64(7), true},
+		{"numeric string to int64", "42", true, int64(42), true},
+		{"non-numeric string fails", "not-a-number", true, nil, false},
+		{"unsupported type fails", true, true, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := CoerceNumericField(tt.input, tt.asInt)
+			if ok != tt.wantOk {
+				t.Fatalf("CoerceNumericField() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && got != tt.expected {
+				t.Errorf("CoerceNumericField() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCoerceNumericFieldTolerant(t *testing.T) {
+	tests := []struct {
+		name                string
+		input               interface{}
+		asInt               bool
+		allowWrittenNumbers bool
+		expected            interface{}
+		wantErr             bool
+	}{
+		{"thousands separator", "1,234", true, false, int64(1234), false},
+		{"scientific notation float", "1.5e3", false, false, float64(1500), false},
+		{"plain integer", "42", true, false, int64(42), false},
+		{"written number requires opt-in", "forty-two", true, false, nil, true},
+		{"written number with opt-in", "forty-two", true, true, int64(42), false},
+		{"unrecognized word still fails", "banana", true, true, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CoerceNumericFieldTolerant(tt.input, tt.asInt, tt.allowWrittenNumbers)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CoerceNumericFieldTolerant() expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CoerceNumericFieldTolerant() unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("CoerceNumericFieldTolerant() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestTruncateString(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -582,11 +708,11 @@ func TestConvertToInt(t *testing.T) {
 		{"float32", float32(42.0), 42, true},
 		{"float64", float64(42.0), 42, true},
 		{"float32 max safe value", float32(9223372036854775807), 9223372036854775807, true}, // math.MaxInt64 as float32
-		{"float32 overflow positive", float32(1e20), 0, false},                             // Large positive float32, should fail
-		{"float32 overflow negative", float32(-1e20), 0, false},                            // Large negative float32, should fail
+		{"float32 overflow positive", float32(1e20), 0, false},                              // Large positive float32, should fail
+		{"float32 overflow negative", float32(-1e20), 0, false},                             // Large negative float32, should fail
 		{"float64 max safe value", float64(9223372036854775807), 9223372036854775807, true}, // math.MaxInt64 as float64
-		{"float64 overflow positive", float64(1e20), 0, false},                             // Large positive float64, should fail
-		{"float64 overflow negative", float64(-1e20), 0, false},                            // Large negative float64, should fail
+		{"float64 overflow positive", float64(1e20), 0, false},                              // Large positive float64, should fail
+		{"float64 overflow negative", float64(-1e20), 0, false},                             // Large negative float64, should fail
 		{"string number", "42", 42, true},
 		{"string with whitespace", "  42  ", 42, true},
 		{"string partial number (strconv improvement)", "42abc", 0, false}, // strconv.ParseInt is stricter than fmt.Sscanf
@@ -595,9 +721,9 @@ func TestConvertToInt(t *testing.T) {
 		{"bool", true, 0, false},
 		{"nil", nil, 0, false},
 		{"uint64 max safe value", uint64(9223372036854775807), 9223372036854775807, true}, // math.MaxInt64
-		{"uint64 overflow value", uint64(18446744073709551615), 0, false},                // math.MaxUint64, should fail
-		{"uint max safe value", uint(9223372036854775807), 9223372036854775807, true},    // math.MaxInt64 as uint
-		{"uint overflow value", uint(18446744073709551615), 0, false},                   // math.MaxUint64 as uint, should fail
+		{"uint64 overflow value", uint64(18446744073709551615), 0, false},                 // math.MaxUint64, should fail
+		{"uint max safe value", uint(9223372036854775807), 9223372036854775807, true},     // math.MaxInt64 as uint
+		{"uint overflow value", uint(18446744073709551615), 0, false},                     // math.MaxUint64 as uint, should fail
 	}
 
 	for _, tt := range tests {
@@ -613,6 +739,81 @@ func TestConvertToInt(t *testing.T) {
 	}
 }
 
+func TestConvertToUint(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected uint64
+		success  bool
+		negative bool
+	}{
+		{"uint", uint(42), 42, true, false},
+		{"uint8", uint8(42), 42, true, false},
+		{"uint32", uint32(42), 42, true, false},
+		{"uint64", uint64(42), 42, true, false},
+		{"positive int", 42, 42, true, false},
+		{"negative int", -1, 0, false, true},
+		{"negative int64", int64(-5), 0, false, true},
+		{"positive float", float64(3.0), 3, true, false},
+		{"negative float", float64(-3.0), 0, false, true},
+		{"string number", "42", 42, true, false},
+		{"string negative number", "-1", 0, false, true},
+		{"string non-number", "hello", 0, false, false},
+		{"bool", true, 0, false, false},
+		{"nil", nil, 0, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, success, negative := convertToUint(tt.value)
+			if success != tt.success {
+				t.Errorf("convertToUint() success = %v, want %v", success, tt.success)
+			}
+			if negative != tt.negative {
+				t.Errorf("convertToUint() negative = %v, want %v", negative, tt.negative)
+			}
+			if success && result != tt.expected {
+				t.Errorf("convertToUint() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSetFieldValueUintRejectsNegative(t *testing.T) {
+	type UintHolder struct {
+		Count uint64
+	}
+	var holder UintHolder
+	v := reflect.ValueOf(&holder).Elem().FieldByName("Count")
+
+	err := SetFieldValue(v, -5)
+	if err == nil {
+		t.Fatal("expected an error assigning a negative value to a uint64 field")
+	}
+}
+
+func TestPopulateStructFromMapWithUintFields(t *testing.T) {
+	type UintFields struct {
+		A uint
+		B uint32
+		C uint64
+	}
+
+	var result UintFields
+	v := reflect.ValueOf(&result).Elem()
+	err := PopulateStructFromMap(v, reflect.TypeOf(result), map[string]any{
+		"A": 1,
+		"B": uint32(2),
+		"C": float64(3),
+	})
+	if err != nil {
+		t.Fatalf("PopulateStructFromMap() error = %v", err)
+	}
+	if result.A != 1 || result.B != 2 || result.C != 3 {
+		t.Errorf("PopulateStructFromMap() = %+v, want {1 2 3}", result)
+	}
+}
+
 func TestConvertToFloat(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -778,11 +979,11 @@ func TestConvertLegacyOutputsToTypedWithPointer(t *testing.T) {
 
 func TestSetFieldValueEdgeCases(t *testing.T) {
 	type TestStruct struct {
-		StringField string
-		IntField    int
-		BoolField   bool
-		FloatField  float64
-		Int8Field   int8   // For overflow testing
+		StringField  string
+		IntField     int
+		BoolField    bool
+		FloatField   float64
+		Int8Field    int8    // For overflow testing
 		Float32Field float32 // For overflow testing
 	}
 